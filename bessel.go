@@ -0,0 +1,613 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math"
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// maxBessel is the largest magnitude of the orders of the Bessel functions and
+// of the arguments for which their power series are summed
+const maxBessel = 1 << 12
+
+// maxAiry is the largest magnitude of the arguments of the Airy functions for
+// which e^(2/3 z^(3/2)) is within the exponent range of big.Float
+const maxAiry = 1 << 20
+
+// cmulI computes i x
+func cmulI(x *complex.Float) *complex.Float {
+	return complex.NewFloat(new(big.Float).Neg(x.B), new(big.Float).Set(x.A))
+}
+
+// rotation computes e^(i pi r x)
+func rotation(x *complex.Float, r float64, prec uint) *complex.Float {
+	wp := prec + guard + positive(cexponent(x))
+	_, pi := constant(wp)
+	return cexp(cmulI(cscale(x, pi.Mul(pi, big.NewFloat(r)), wp)), prec)
+}
+
+// integerOrder returns the order nu if it is an integer
+func integerOrder(nu *complex.Float) (int, bool) {
+	if nu.B.Sign() != 0 || !nu.A.IsInt() {
+		return 0, false
+	}
+	n, _ := nu.A.Int64()
+	return int(n), true
+}
+
+// exactBessel returns the values of the Bessel functions J and I at zero
+func exactBessel(operation Operation, nu, x *complex.Rational) (*complex.Rational, bool) {
+	if !isZero(x) || (operation != OperationBesselJ && operation != OperationBesselI) {
+		return nil, false
+	}
+	switch {
+	case isZero(nu):
+		return realRational(big.NewRat(1, 1)), true
+	case nu.A.Sign() > 0 || isInteger(nu):
+		return realRational(new(big.Rat)), true
+	}
+	return nil, false
+}
+
+// besselSeries sums the power series (z/2)^nu the sum of (s z^2/4)^k / (k!
+// Γ(nu + k + 1)) of J for s = -1 and of I for s = 1, nu must not be a negative
+// integer. It returns the sum and the binary exponent of the largest term.
+func besselSeries(nu, z *complex.Float, s int, wp uint) (*complex.Float, int) {
+	one := creal(big.NewFloat(1), wp)
+	half := cscale(z, big.NewFloat(.5), wp)
+	q := cmul(half, half, wp)
+	if s < 0 {
+		q = cneg(q)
+	}
+	// the series starts with 1/Γ(nu + 1)
+	n, integer := integerOrder(nu)
+	var term, power *complex.Float
+	if integer {
+		term = creal(newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt(factorial(n))), wp)
+		power = cpowInt(half, n, wp)
+	} else {
+		term = cquo(one, cgamma(shifted(nu, 1, wp), wp), wp)
+		power = cpow(half, nu, wp+positive(cexponent(nu)))
+	}
+	sum, scale := term, cexponent(term)
+	x, y := approximate(q)
+	r := math.Hypot(x, y)
+	a, b := approximate(nu)
+	for k := 1; ; k++ {
+		d := cscale(shifted(nu, k, wp), newFloat(wp).SetInt64(int64(k)), wp)
+		term = cquo(cmul(term, q, wp), d, wp)
+		e := cexponent(term)
+		if e > scale {
+			scale = e
+		}
+		sum = cadd(sum, term, wp)
+		// the terms decrease once k |nu + k| is larger than |z^2/4|
+		if float64(k)*math.Hypot(a+float64(k), b) > r && (czero(term) || e < cexponent(sum)-int(wp)) {
+			break
+		}
+	}
+	return cmul(sum, power, wp), scale + cexponent(power)
+}
+
+// besselLog sums the series of Y_n for s = -1 and of K_n for s = 1 of an
+// order n >= 0, which have logarithmic terms. It returns the sum and the
+// binary exponent of the largest term.
+func besselLog(n int, z *complex.Float, s int, wp uint) (*complex.Float, int) {
+	one := creal(big.NewFloat(1), wp)
+	half := cscale(z, big.NewFloat(.5), wp)
+	q := cmul(half, half, wp)
+	if s < 0 {
+		q = cneg(q)
+	}
+	scale := math.MinInt32
+	maximum := func(a *complex.Float) {
+		if e := cexponent(a); !czero(a) && e > scale {
+			scale = e
+		}
+	}
+	// the finite sum of (n - k - 1)!/k! (-s z^2/4)^k (z/2)^-n
+	finite := newComplex(wp)
+	if n > 0 {
+		term := cscale(cpowInt(cquo(one, half, wp), n, wp), newFloat(wp).SetInt(factorial(n-1)), wp)
+		finite = term
+		maximum(term)
+		for k := 1; k < n; k++ {
+			term = cscale(cneg(cmul(term, q, wp)), newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(k*(n-k)))), wp)
+			finite = cadd(finite, term, wp)
+			maximum(term)
+		}
+	}
+	// the series (z/2)^n the sum of (ψ(k + 1) + ψ(n + k + 1)) (s z^2/4)^k /
+	// (k! (n + k)!) where ψ(k + 1) = H_k - γ
+	euler := cneg(cpolygamma(0, creal(big.NewFloat(1), wp), wp)).A
+	hk, hn := newFloat(wp), newFloat(wp)
+	for j := 1; j <= n; j++ {
+		hn.Add(hn, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(j))))
+	}
+	c := newFloat(wp).Sub(hn, newFloat(wp).SetMantExp(euler, 1))
+	term := cscale(cpowInt(half, n, wp), newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt(factorial(n))), wp)
+	series := cscale(term, c, wp)
+	maximum(series)
+	x, y := approximate(q)
+	r := math.Hypot(x, y)
+	for k := 1; ; k++ {
+		term = cscale(cmul(term, q, wp), newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(k*(n+k)))), wp)
+		hk.Add(hk, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(k))))
+		hn.Add(hn, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(n+k))))
+		c := newFloat(wp).Add(hk, hn)
+		a := cscale(term, c.Sub(c, newFloat(wp).SetMantExp(euler, 1)), wp)
+		maximum(a)
+		series = cadd(series, a, wp)
+		if float64(k*(n+k)) > r && (czero(a) || cexponent(a) < cexponent(series)-int(wp)) {
+			break
+		}
+	}
+	// the logarithm of z/2 times J_n or I_n
+	b, e := besselSeries(creal(newFloat(wp).SetInt64(int64(n)), wp), z, s, wp)
+	log := clog(half, wp)
+	b = cmul(b, log, wp)
+	if e += cexponent(log); e > scale {
+		scale = e
+	}
+	if s < 0 {
+		// Y_n = (2 log(z/2) J_n - the finite sum - the series) / pi
+		_, pi := constant(wp)
+		a := csub(csub(cscale(b, big.NewFloat(2), wp), finite, wp), series, wp)
+		return cscale(a, pi.Quo(big.NewFloat(1), pi), wp), scale + 1
+	}
+	// K_n = (-1)^(n + 1) log(z/2) I_n + (the finite sum + (-1)^n the series) / 2
+	if n%2 == 0 {
+		b = cneg(b)
+	} else {
+		series = cneg(series)
+	}
+	a := cadd(b, cscale(cadd(finite, series, wp), big.NewFloat(.5), wp), wp)
+	return a, scale
+}
+
+// besselReflection computes Y_nu from J_nu and J_-nu for s = -1 and K_nu from
+// I_nu and I_-nu for s = 1, nu must not be an integer. It returns the value
+// and the binary exponent of the larger of the terms that are subtracted.
+func besselReflection(nu, z *complex.Float, s int, wp uint) (*complex.Float, int) {
+	a, scaleA := besselSeries(nu, z, s, wp)
+	b, scaleB := besselSeries(cneg(nu), z, s, wp)
+	sin := sinPi(nu, wp)
+	var d *complex.Float
+	if s < 0 {
+		// Y_nu = (J_nu cos(nu pi) - J_-nu) / sin(nu pi)
+		cos := sinPi(complex.NewFloat(newFloat(wp).Add(nu.A, big.NewFloat(.5)), nu.B), wp)
+		a = cmul(a, cos, wp)
+		scaleA += cexponent(cos)
+		d = csub(a, b, wp)
+	} else {
+		// K_nu = pi/2 (I_-nu - I_nu) / sin(nu pi)
+		_, pi := constant(wp)
+		d = cscale(csub(b, a, wp), pi.SetMantExp(pi, -1), wp)
+		scaleA++
+		scaleB++
+	}
+	scale := scaleA
+	if scaleB > scale {
+		scale = scaleB
+	}
+	return cquo(d, sin, wp), scale - cexponent(sin)
+}
+
+// besselPower computes the Bessel function of the operation with the power
+// series, it returns the value and the binary exponent of the largest term
+func besselPower(operation Operation, nu, z *complex.Float, wp uint) (*complex.Float, int) {
+	n, integer := integerOrder(nu)
+	switch operation {
+	case OperationBesselJ, OperationBesselI:
+		s := 1
+		if operation == OperationBesselJ {
+			s = -1
+		}
+		if integer && n < 0 {
+			// J_-n = (-1)^n J_n and I_-n = I_n
+			a, scale := besselSeries(creal(newFloat(wp).SetInt64(int64(-n)), wp), z, s, wp)
+			if s < 0 && n%2 != 0 {
+				a = cneg(a)
+			}
+			return a, scale
+		}
+		return besselSeries(nu, z, s, wp)
+	}
+	s := 1
+	if operation == OperationBesselY {
+		s = -1
+	}
+	if !integer {
+		return besselReflection(nu, z, s, wp)
+	} else if n < 0 {
+		// Y_-n = (-1)^n Y_n and K_-n = K_n
+		a, scale := besselLog(-n, z, s, wp)
+		if s < 0 && n%2 != 0 {
+			a = cneg(a)
+		}
+		return a, scale
+	}
+	return besselLog(n, z, s, wp)
+}
+
+// besselLarge tests if |z| is large enough for the asymptotic expansions of
+// the Bessel functions of order nu to reach precision prec
+func besselLarge(nu, z *complex.Float, prec uint) bool {
+	x, y := approximate(z)
+	a, b := approximate(nu)
+	return math.Hypot(x, y) >= float64(prec+guard)*math.Ln2/2+a*a+b*b
+}
+
+// hankelSum sums the asymptotic series of the terms a_k(nu) w^k where a_k(nu)
+// = (4nu^2 - 1) (4nu^2 - 9) ... (4nu^2 - (2k - 1)^2) / (k! 8^k), it returns
+// false if the terms grow before they are smaller than 2^-wp
+func hankelSum(nu, w *complex.Float, wp uint) (*complex.Float, bool) {
+	square := cscale(cmul(nu, nu, wp), big.NewFloat(4), wp)
+	term, sum := creal(big.NewFloat(1), wp), creal(big.NewFloat(1), wp)
+	last := cexponent(term)
+	for k := 1; ; k++ {
+		c := cround(square, wp)
+		c.A.Sub(c.A, newFloat(wp).SetInt64(int64((2*k-1)*(2*k-1))))
+		term = cmul(cmul(term, c, wp), w, wp)
+		term = cscale(term, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(8*k))), wp)
+		if czero(term) {
+			// the series of the half integer orders terminate
+			return sum, true
+		}
+		e := cexponent(term)
+		if e > last {
+			return sum, false
+		}
+		sum, last = cadd(sum, term, wp), e
+		if e < cexponent(sum)-int(wp) {
+			return sum, true
+		}
+	}
+}
+
+// hankel computes J_nu and Y_nu from the asymptotic expansions of the Hankel
+// functions H1 = J + iY and H2 = J - iY for z with a non-negative real part,
+// it returns the values, the binary exponent of the larger of H1 and H2 and
+// false if the expansions don't converge
+func hankel(nu, z *complex.Float, wp uint) (j, y *complex.Float, scale int, ok bool) {
+	one := creal(big.NewFloat(1), wp)
+	inverse := cmulI(cquo(one, z, wp))
+	s1, ok := hankelSum(nu, inverse, wp)
+	if !ok {
+		return nil, nil, 0, false
+	}
+	s2, ok := hankelSum(nu, cneg(inverse), wp)
+	if !ok {
+		return nil, nil, 0, false
+	}
+	// H1 = sqrt(2/(pi z)) e^(i omega) the sum of i^k a_k(nu) / z^k and H2 is
+	// the conjugate expansion, where omega = z - (nu/2 + 1/4) pi
+	_, pi := constant(wp)
+	phase := complex.NewFloat(newFloat(wp).SetMantExp(nu.A, -1), newFloat(wp).SetMantExp(nu.B, -1))
+	phase.A.Add(phase.A, big.NewFloat(.25))
+	omega := csub(z, cscale(phase, pi, wp), wp)
+	c := cquo(creal(newFloat(wp).Sqrt(newFloat(wp).Quo(big.NewFloat(2), pi)), wp), csqrt(z, wp), wp)
+	h1 := cmul(cmul(c, cexp(cmulI(omega), wp), wp), s1, wp)
+	h2 := cmul(cmul(c, cexp(cneg(cmulI(omega)), wp), wp), s2, wp)
+	scale = cexponent(h1)
+	if e := cexponent(h2); e > scale {
+		scale = e
+	}
+	j = cscale(cadd(h1, h2, wp), big.NewFloat(.5), wp)
+	y = cscale(cmulI(csub(h2, h1, wp)), big.NewFloat(.5), wp)
+	return j, y, scale, true
+}
+
+// besselAsymptotic computes the Bessel function of the operation with its
+// asymptotic expansion, it returns the value, the binary exponent of the
+// largest term and false if the expansion doesn't converge
+func besselAsymptotic(operation Operation, nu, z *complex.Float, wp uint) (*complex.Float, int, bool) {
+	one := creal(big.NewFloat(1), wp)
+	switch operation {
+	case OperationBesselK:
+		// K_nu = sqrt(pi/(2z)) e^-z the sum of a_k(nu) / z^k
+		s, ok := hankelSum(nu, cquo(one, z, wp), wp)
+		if !ok {
+			return nil, 0, false
+		}
+		_, pi := constant(wp)
+		// the root of z is on the principal branch
+		c := cquo(creal(pi.Sqrt(pi.SetMantExp(pi, -1)), wp), csqrt(z, wp), wp)
+		a := cmul(cmul(c, cexp(cneg(z), wp), wp), s, wp)
+		return a, cexponent(a), true
+	case OperationBesselI:
+		// I_nu(z) = e^(-i pi nu/2) J_nu(iz) for -pi < ph z <= pi/2 and
+		// e^(i pi nu/2) J_nu(-iz) for pi/2 < ph z <= pi
+		r, w := -.5, cmulI(z)
+		if z.A.Sign() < 0 && z.B.Sign() >= 0 {
+			r, w = .5, cneg(w)
+		}
+		a, scale, ok := besselAsymptotic(OperationBesselJ, nu, w, wp)
+		if !ok {
+			return nil, 0, false
+		}
+		c := rotation(nu, r, wp)
+		return cmul(a, c, wp), scale + cexponent(c), true
+	}
+	w := z
+	if z.A.Sign() < 0 {
+		w = cneg(z)
+	}
+	j, y, scale, ok := hankel(nu, w, wp)
+	if !ok {
+		return nil, 0, false
+	} else if z.A.Sign() >= 0 {
+		if operation == OperationBesselJ {
+			return j, scale, true
+		}
+		return y, scale, true
+	}
+	// z = w e^(i pi sigma) where w has a positive real part
+	sigma := 1.0
+	if z.B.Sign() < 0 {
+		sigma = -1
+	}
+	if operation == OperationBesselJ {
+		// J_nu(w e^(i pi sigma)) = e^(i pi sigma nu) J_nu(w)
+		c := rotation(nu, sigma, wp)
+		return cmul(c, j, wp), scale + cexponent(c), true
+	}
+	// Y_nu(w e^(i pi sigma)) = e^(-i pi sigma nu) Y_nu(w) + 2i sigma cos(nu pi)
+	// J_nu(w)
+	c := rotation(nu, -sigma, wp)
+	a := cmul(c, y, wp)
+	cos := sinPi(complex.NewFloat(newFloat(wp).Add(nu.A, big.NewFloat(.5)), nu.B), wp)
+	b := cscale(cmulI(cmul(cos, j, wp)), big.NewFloat(2*sigma), wp)
+	scale += cexponent(c)
+	if e := cexponent(b); e > scale {
+		scale = e
+	}
+	return cadd(a, b, wp), scale, true
+}
+
+// cbessel computes the Bessel function of the operation of order nu, z must
+// not be zero and |z| must not be larger than maxBessel unless it is large
+// enough for the asymptotic expansions
+func cbessel(operation Operation, nu, z *complex.Float, prec uint) *complex.Float {
+	asymptotic := besselLarge(nu, z, prec)
+	a := refine(prec, func(wp uint) (*complex.Float, int) {
+		if asymptotic {
+			if a, scale, ok := besselAsymptotic(operation, nu, z, wp); ok {
+				return a, scale
+			}
+		}
+		return besselPower(operation, nu, z, wp)
+	})
+	// the functions of real orders are real for positive arguments, and J and
+	// I of integer orders are real for the real arguments
+	if nu.B.Sign() == 0 && z.B.Sign() == 0 {
+		_, integer := integerOrder(nu)
+		if z.A.Sign() > 0 || (integer && (operation == OperationBesselJ || operation == OperationBesselI)) {
+			a.B.SetInt64(0)
+		}
+	}
+	return a
+}
+
+// airySum sums the asymptotic series of the terms u_k w^k of the Airy
+// functions, or v_k w^k of their derivatives, it returns false if the terms
+// grow before they are smaller than 2^-wp
+func airySum(derivative bool, w *complex.Float, wp uint) (*complex.Float, bool) {
+	u, sum := creal(big.NewFloat(1), wp), creal(big.NewFloat(1), wp)
+	last := cexponent(u)
+	for k := 1; ; k++ {
+		// u_k = (6k - 5) (6k - 3) (6k - 1) / ((2k - 1) 216 k) u_(k-1)
+		c := newFloat(wp).SetInt64(int64((6*k - 5) * (6*k - 3) * (6*k - 1)))
+		c.Quo(c, newFloat(wp).SetInt64(int64((2*k-1)*216*k)))
+		u = cscale(cmul(u, w, wp), c, wp)
+		term := u
+		if derivative {
+			// v_k = -(6k + 1) / (6k - 1) u_k
+			c := newFloat(wp).SetInt64(int64(-(6*k + 1)))
+			term = cscale(u, c.Quo(c, newFloat(wp).SetInt64(int64(6*k-1))), wp)
+		}
+		e := cexponent(term)
+		if e > last {
+			return sum, false
+		}
+		sum, last = cadd(sum, term, wp), e
+		if e < cexponent(sum)-int(wp) {
+			return sum, true
+		}
+	}
+}
+
+// airyAsymptotic computes Ai or its derivative with the asymptotic expansions
+// for large |z|, it returns false if the expansions don't converge
+func airyAsymptotic(derivative bool, z *complex.Float, wp uint) (*complex.Float, bool) {
+	one := creal(big.NewFloat(1), wp)
+	_, pi := constant(wp)
+	root := newFloat(wp).Sqrt(pi)
+	x, y := approximate(z)
+	if x >= -math.Hypot(x, y)/2 {
+		// for |ph z| <= 2pi/3 Ai(z) = e^-ζ / (2 sqrt(pi) z^(1/4)) the sum of
+		// (-1)^k u_k ζ^-k and Ai'(z) = -z^(1/4) e^-ζ / (2 sqrt(pi)) the sum of
+		// (-1)^k v_k ζ^-k where ζ = 2/3 z^(3/2)
+		r := csqrt(z, wp)
+		q := csqrt(r, wp)
+		zeta := cscale(cmul(z, r, wp), newFloat(wp).Quo(big.NewFloat(2), big.NewFloat(3)), wp)
+		s, ok := airySum(derivative, cneg(cquo(one, zeta, wp)), wp)
+		if !ok {
+			return nil, false
+		}
+		a := cmul(cexp(cneg(zeta), wp), s, wp)
+		if derivative {
+			return cscale(cmul(a, q, wp), newFloat(wp).Quo(big.NewFloat(-.5), root), wp), true
+		}
+		return cscale(cquo(a, q, wp), newFloat(wp).Quo(big.NewFloat(.5), root), wp), true
+	}
+	// for |ph w| < pi/3 where w = -z Ai(-w) = (cos θ the sum of (-1)^k u_2k
+	// ζ^-2k + sin θ the sum of (-1)^k u_(2k+1) ζ^-(2k+1)) / (sqrt(pi) w^(1/4))
+	// and Ai'(-w) = w^(1/4) (sin θ the sum of (-1)^k v_2k ζ^-2k - cos θ the
+	// sum of (-1)^k v_(2k+1) ζ^-(2k+1)) / sqrt(pi) where ζ = 2/3 w^(3/2) and θ =
+	// ζ - pi/4, the sums are the parts of the series in i/ζ and -i/ζ
+	w := cneg(z)
+	r := csqrt(w, wp)
+	q := csqrt(r, wp)
+	zeta := cscale(cmul(w, r, wp), newFloat(wp).Quo(big.NewFloat(2), big.NewFloat(3)), wp)
+	inverse := cmulI(cquo(one, zeta, wp))
+	s1, ok := airySum(derivative, cneg(inverse), wp)
+	if !ok {
+		return nil, false
+	}
+	s2, ok := airySum(derivative, inverse, wp)
+	if !ok {
+		return nil, false
+	}
+	theta := cround(zeta, wp)
+	theta.A.Sub(theta.A, newFloat(wp).SetMantExp(pi, -2))
+	e1 := cmul(cexp(cmulI(theta), wp), s1, wp)
+	e2 := cmul(cexp(cneg(cmulI(theta)), wp), s2, wp)
+	if derivative {
+		a := cneg(cmulI(cmul(csub(e1, e2, wp), q, wp)))
+		return cscale(a, newFloat(wp).Quo(big.NewFloat(.5), root), wp), true
+	}
+	return cscale(cquo(cadd(e1, e2, wp), q, wp), newFloat(wp).Quo(big.NewFloat(.5), root), wp), true
+}
+
+// airyLarge tests if |z| is large enough for the asymptotic expansions of the
+// Airy functions to reach precision prec, which is when 2/3 |z|^(3/2) is larger
+// than (prec + guard) log(2)/2
+func airyLarge(z *complex.Float, prec uint) bool {
+	x, y := approximate(z)
+	r := math.Hypot(x, y)
+	return r*math.Sqrt(r) >= .75*float64(prec+guard)*math.Ln2
+}
+
+// airyRotated computes the Airy function of the operation from the asymptotic
+// expansions of Ai and Ai', it returns the value, the binary exponent of the
+// largest term and false if the expansions don't converge
+func airyRotated(operation Operation, z *complex.Float, wp uint) (*complex.Float, int, bool) {
+	derivative := operation == OperationAiryAiPrime || operation == OperationAiryBiPrime
+	if operation == OperationAiryAi || operation == OperationAiryAiPrime {
+		a, ok := airyAsymptotic(derivative, z, wp)
+		if !ok {
+			return nil, 0, false
+		}
+		return a, cexponent(a), true
+	}
+	// Bi(z) = e^(i pi/6) Ai(z e^(2i pi/3)) + e^(-i pi/6) Ai(z e^(-2i pi/3)) and
+	// Bi'(z) = e^(5i pi/6) Ai'(z e^(2i pi/3)) + e^(-5i pi/6) Ai'(z e^(-2i pi/3))
+	root := newFloat(wp).Sqrt(newFloat(wp).SetInt64(3))
+	half := newFloat(wp).SetMantExp(root, -1)
+	omega := complex.NewFloat(newFloat(wp).SetFloat64(-.5), half)
+	c := complex.NewFloat(half, newFloat(wp).SetFloat64(.5))
+	if derivative {
+		c = complex.NewFloat(newFloat(wp).Neg(half), newFloat(wp).SetFloat64(.5))
+	}
+	conjugate := func(x *complex.Float) *complex.Float {
+		return complex.NewFloat(x.A, new(big.Float).Neg(x.B))
+	}
+	a, ok := airyAsymptotic(derivative, cmul(z, omega, wp), wp)
+	if !ok {
+		return nil, 0, false
+	}
+	b, ok := airyAsymptotic(derivative, cmul(z, conjugate(omega), wp), wp)
+	if !ok {
+		return nil, 0, false
+	}
+	a, b = cmul(c, a, wp), cmul(conjugate(c), b, wp)
+	scale := cexponent(a)
+	if e := cexponent(b); e > scale {
+		scale = e
+	}
+	return cadd(a, b, wp), scale, true
+}
+
+// airySeries sums the Maclaurin series of the Airy function of the operation,
+// it returns the sum and the binary exponent of the largest term
+func airySeries(operation Operation, z *complex.Float, wp uint) (*complex.Float, int) {
+	cube := cmul(cmul(z, z, wp), z, wp)
+	x, y := approximate(cube)
+	r := math.Hypot(x, y)
+	// sum sums the series with the first term and the ratios z^3 / d(j) of the
+	// terms j and j - 1
+	sum := func(first *complex.Float, d func(j int) int64) (*complex.Float, int) {
+		term, sum := first, first
+		scale := cexponent(term)
+		for j := 1; ; j++ {
+			term = cmul(term, cube, wp)
+			term = cscale(term, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(d(j))), wp)
+			e := cexponent(term)
+			if e > scale {
+				scale = e
+			}
+			sum = cadd(sum, term, wp)
+			if float64(d(j)) > r && (czero(term) || e < cexponent(sum)-int(wp)) {
+				return sum, scale
+			}
+		}
+	}
+	// f and g are the solutions of w'' = z w with f(0) = g'(0) = 1 and f'(0) =
+	// g(0) = 0
+	one := creal(big.NewFloat(1), wp)
+	var f, g *complex.Float
+	var scaleF, scaleG int
+	switch operation {
+	case OperationAiryAi, OperationAiryBi:
+		f, scaleF = sum(one, func(j int) int64 {
+			return int64((3*j - 1) * 3 * j)
+		})
+		g, scaleG = sum(cround(z, wp), func(j int) int64 {
+			return int64(3 * j * (3*j + 1))
+		})
+	default:
+		square := cscale(cmul(z, z, wp), big.NewFloat(.5), wp)
+		f, scaleF = sum(square, func(j int) int64 {
+			return int64(3 * j * (3*j + 2))
+		})
+		g, scaleG = sum(one, func(j int) int64 {
+			return int64((3*j - 2) * 3 * j)
+		})
+	}
+	// Ai = c1 f - c2 g and Bi = sqrt(3) (c1 f + c2 g) where c1 = Ai(0) =
+	// 1/(3^(2/3) Γ(2/3)) and c2 = -Ai'(0) = 1/(3^(1/3) Γ(1/3)), and Γ(2/3) =
+	// 2 pi / (sqrt(3) Γ(1/3))
+	_, pi := constant(wp)
+	root := newFloat(wp).Sqrt(newFloat(wp).SetInt64(3))
+	third := logFloat(newFloat(wp).SetInt64(3), wp)
+	third = expFloat(third.Quo(third, newFloat(wp).SetInt64(3)), wp)
+	gamma := cgamma(creal(newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(3)), wp), wp).A
+	c2 := newFloat(wp).Mul(third, gamma)
+	c2.Quo(big.NewFloat(1), c2)
+	c1 := newFloat(wp).Mul(root, gamma)
+	c1.Quo(c1, newFloat(wp).Mul(newFloat(wp).Mul(third, third), pi.SetMantExp(pi, 1)))
+	f, g = cscale(f, c1, wp), cscale(g, c2, wp)
+	scale := scaleF + exponent(c1)
+	if e := scaleG + exponent(c2); e > scale {
+		scale = e
+	}
+	if operation == OperationAiryAi || operation == OperationAiryAiPrime {
+		return csub(f, g, wp), scale
+	}
+	return cscale(cadd(f, g, wp), root, wp), scale + 1
+}
+
+// cairy computes the Airy function of the operation, |z| must not be larger
+// than maxAiry
+func cairy(operation Operation, z *complex.Float, prec uint) *complex.Float {
+	asymptotic := airyLarge(z, prec)
+	a := refine(prec, func(wp uint) (*complex.Float, int) {
+		if asymptotic {
+			if a, scale, ok := airyRotated(operation, z, wp); ok {
+				return a, scale
+			}
+		}
+		return airySeries(operation, z, wp)
+	})
+	// the Airy functions are real for the real arguments
+	if z.B.Sign() == 0 {
+		a.B.SetInt64(0)
+	}
+	return a
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEngineeringFunctions(t *testing.T) {
+	run(t, []test{
+		{"lambertw(1)", "0.5671432904"},
+		{"lambertw(-1/4, -1)", "-2.153292364"},
+		{"lambertw(-1)", "-0.3181315052 + 1.337235701i"},
+		{"airyai(1i)", "0.3314933054 + -0.317449859i"},
+		{"ellipk(1/2)", "1.854074677"},
+		{"ellipe(1/2)", "1.350643881"},
+		{"besselj(1, 0)", "0"},
+	})
+	runZeros(t, []string{
+		"lambertw(1)*exp(lambertw(1)) - 1",
+		"lambertw(-1/4, -1)*exp(lambertw(-1/4, -1)) + 1/4",
+		"lambertw(2 + 1i, 1)*exp(lambertw(2 + 1i, 1)) - (2 + 1i)",
+		"besselj(1/2, 1) - sqrt(2/pi)*sin(1)",
+		"bessely(1/2, 1) + sqrt(2/pi)*cos(1)",
+		"besseli(1/2, 2) - sqrt(1/pi)*sinh(2)",
+		"besselk(1/2, 1) - sqrt(pi/2)*exp(-1)",
+		"airyai(0) - 1/(3^(2/3)*gamma(2/3))",
+		"airybi(0) - 1/(3^(1/6)*gamma(2/3))",
+		"ellipk(0) - pi/2",
+		"ellipe(0) - pi/2",
+		"ellipe(1) - 1",
+		// Legendre's relation at m = 1/2
+		"2*ellipe(1/2)*ellipk(1/2) - ellipk(1/2)^2 - pi/2",
+	}, 1000)
+
+	// the reference values are computed with float64
+	tests := []struct {
+		expression string
+		value      float64
+	}{
+		{"besselj(0, 1)", math.J0(1)},
+		{"besselj(1, 5/2)", math.J1(2.5)},
+		{"besselj(2, 10)", math.Jn(2, 10)},
+		{"bessely(0, 3)", math.Y0(3)},
+		{"bessely(1, 2)", math.Y1(2)},
+		{"bessely(3, 7)", math.Yn(3, 7)},
+	}
+	for _, test := range tests {
+		if a := toComplex128(t, test.expression); !near(a, complex(test.value, 0)) {
+			t.Errorf("%s = %v, want %v", test.expression, a, test.value)
+		}
+	}
+
+	runErrors(t, []errorTest{
+		{"ellipk(1)", ErrorTypeDomain, 0, 9},
+		{"bessely(0, 0)", ErrorTypeDomain, 0, 13},
+		{"lambertw(1, 1/2)", ErrorTypeDomain, 0, 16},
+	})
+}
+
+func TestEngineeringDerivatives(t *testing.T) {
+	checkDerivatives(t, []string{
+		"checkderivative(besselj(2, x), x, 3/2)",
+		"checkderivative(besseli(0, x)*bessely(1, x), x, 2)",
+		"checkderivative(lambertw(x), x, 2)",
+		"checkderivative(lambertw(x, -1), x, -1/4)",
+		"checkderivative(besselk(1/3, x)*airyai(x), x, 1)",
+		"checkderivative(airybi(x^2), x, 1/2)",
+		"checkderivative(ellipk(x) + ellipe(x), x, 1/3)",
+	})
+	// the derivatives with respect to the order and the branch aren't
+	// supported
+	runErrors(t, []errorTest{
+		{"checkderivative(besselj(x, 1), x, 3/10)", ErrorTypeValue, 0, 0},
+		{"derivative(lambertw(1, x))", ErrorTypeValue, 0, 26},
+		{"gradient(besselj(x, y), [x; y])", ErrorTypeValue, 0, 31},
+	})
+	// the roots can still be bracketed
+	run(t, []test{
+		{"solve(besselj(x, 1) - 1/2, x, 1/2)", "0.8726349776"},
+	})
+}
@@ -5,14 +5,185 @@
 package calc
 
 import (
+	"errors"
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/ALTree/bigfloat"
 	complex "github.com/pointlander/c0mpl3x"
 )
 
-var prec uint = 1024
+// DefaultPrec is the default precision of a calculator in bits
+const DefaultPrec uint = 1024
+
+var (
+	// piLock guards the pi cache of bigfloat and the ln 2 cache which are
+	// replaced when a higher precision is requested
+	piLock sync.RWMutex
+	// piPrec is the precision to which the caches have been computed
+	piPrec uint = 1024
+	// ln2Cache is ln 2 computed to piPrec, it is nil until a precision is
+	// reserved
+	ln2Cache *big.Float
+)
+
+// reservePI computes pi and ln 2 to a precision sufficient for computations
+// at prec, so that concurrent computations at or below prec only read the
+// caches. The caller must hold a read lock on piLock.
+func reservePI(prec uint) {
+	// bigfloat doubles the precision internally during newton iteration
+	required := 4*prec + 256
+	if required <= piPrec && ln2Cache != nil {
+		return
+	}
+	piLock.RUnlock()
+	piLock.Lock()
+	if required > piPrec {
+		bigfloat.PI(required)
+		piPrec = required
+	}
+	if ln2Cache == nil || ln2Cache.Prec() < piPrec {
+		ln2Cache = naturalLog2(piPrec)
+	}
+	piLock.Unlock()
+	piLock.RLock()
+}
+
+// builtins are the names of the built in functions and constants
+var builtins = map[string]bool{
+	"exp":             true,
+	"e":               true,
+	"pi":              true,
+	"inf":             true,
+	"prec":            true,
+	"withprec":        true,
+	"simplify":        true,
+	"expand":          true,
+	"collect":         true,
+	"derivative":      true,
+	"checkderivative": true,
+	"gradient":        true,
+	"jacobian":        true,
+	"hessian":         true,
+	"integrate":       true,
+	"solve":           true,
+	"series":          true,
+	"limit":           true,
+	"eval":            true,
+	"log":             true,
+	"log2":            true,
+	"log10":           true,
+	"exp2":            true,
+	"sqrt":            true,
+	"cos":             true,
+	"sin":             true,
+	"tan":             true,
+	"asin":            true,
+	"acos":            true,
+	"atan":            true,
+	"atan2":           true,
+	"sinh":            true,
+	"cosh":            true,
+	"tanh":            true,
+	"asinh":           true,
+	"acosh":           true,
+	"atanh":           true,
+	"sec":             true,
+	"csc":             true,
+	"cot":             true,
+	"gamma":           true,
+	"lgamma":          true,
+	"beta":            true,
+	"erf":             true,
+	"erfc":            true,
+	"zeta":            true,
+	"digamma":         true,
+	"polygamma":       true,
+	"lambertw":        true,
+	"besselj":         true,
+	"bessely":         true,
+	"besseli":         true,
+	"besselk":         true,
+	"airyai":          true,
+	"airybi":          true,
+	"airyaiprime":     true,
+	"airybiprime":     true,
+	"ellipk":          true,
+	"ellipe":          true,
+	"gcd":             true,
+	"lcm":             true,
+	"isprime":         true,
+	"nextprime":       true,
+	"factor":          true,
+	"totient":         true,
+	"powmod":          true,
+	"invmod":          true,
+	"crt":             true,
+	"jacobi":          true,
+}
+
+// functionRules are the operations of the rules of the functions with one
+// argument in functionNames
+var functionRules = map[pegRule]Operation{
+	ruleasin:        OperationArcsine,
+	ruleacos:        OperationArccosine,
+	ruleatan:        OperationArctangent,
+	rulesinh:        OperationHyperbolicSine,
+	rulecosh:        OperationHyperbolicCosine,
+	ruletanh:        OperationHyperbolicTangent,
+	ruleasinh:       OperationHyperbolicArcsine,
+	ruleacosh:       OperationHyperbolicArccosine,
+	ruleatanh:       OperationHyperbolicArctangent,
+	rulesec:         OperationSecant,
+	rulecsc:         OperationCosecant,
+	rulecot:         OperationCotangent,
+	rulegamma:       OperationGamma,
+	rulelgamma:      OperationLogGamma,
+	ruleerf:         OperationErf,
+	ruleerfc:        OperationErfc,
+	ruledigamma:     OperationDigamma,
+	ruleairyai:      OperationAiryAi,
+	ruleairybi:      OperationAiryBi,
+	ruleairyaiprime: OperationAiryAiPrime,
+	ruleairybiprime: OperationAiryBiPrime,
+	ruleellipk:      OperationEllipticK,
+	ruleellipe:      OperationEllipticE,
+	ruleisprime:     OperationIsPrime,
+	rulenextprime:   OperationNextPrime,
+	rulefactor:      OperationFactor,
+	ruletotient:     OperationTotient,
+}
+
+// besselRules are the operations of the rules of the Bessel functions
+var besselRules = map[pegRule]Operation{
+	rulebesselj: OperationBesselJ,
+	rulebessely: OperationBesselY,
+	rulebesseli: OperationBesselI,
+	rulebesselk: OperationBesselK,
+}
+
+// integerRules are the operations of the rules of the number theoretic
+// functions with more than one argument
+var integerRules = map[pegRule]Operation{
+	rulegcd:    OperationGCD,
+	rulelcm:    OperationLCM,
+	rulepowmod: OperationPowMod,
+	ruleinvmod: OperationInvMod,
+	rulecrt:    OperationCRT,
+	rulejacobi: OperationJacobi,
+}
+
+// Prec sets the precision of the calculator in bits
+func Prec(prec uint) func(*Calculator) error {
+	return func(c *Calculator) error {
+		if prec == 0 {
+			return errors.New("precision must be positive")
+		}
+		c.Prec = prec
+		return nil
+	}
+}
 
 // ValueType is a value type
 type ValueType int
@@ -22,6 +193,8 @@ const (
 	ValueTypeMatrix ValueType = iota
 	// ValueTypeExpression is an expression value type
 	ValueTypeExpression
+	// ValueTypeRule is a rewrite rule value type
+	ValueTypeRule
 )
 
 // Value is a value
@@ -29,224 +202,477 @@ type Value struct {
 	ValueType  ValueType
 	Matrix     *complex.Matrix
 	Expression *Node
+	Rule       *Rule
 }
 
-// Eval evaluates the expression
+// Eval evaluates the expression and panics if there is an error
 func (c *Calculator) Eval() Value {
+	value, err := c.EvalE()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// EvalE evaluates the expression
+func (c *Calculator) EvalE() (Value, error) {
+	if c.Prec == 0 {
+		c.Prec = DefaultPrec
+	}
+	if c.Env == nil {
+		c.Env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	reservePI(c.Prec)
 	return c.Rulee(c.AST())
 }
 
+// setPrec sets the precision of the calculator during evaluation
+func (c *Calculator) setPrec(prec uint) {
+	reservePI(prec)
+	c.Prec = prec
+}
+
+// order converts the value computed for node into the order of a derivative
+func (c *Calculator) order(node *node32, a Value) (int, error) {
+	if err := c.matrix(node, node, a); err != nil {
+		return 0, err
+	}
+	if !isScalar(a.Matrix) {
+		return 0, c.newError(ErrorTypeDimension, node, node, "order must be a 1x1 matrix")
+	}
+	x := &a.Matrix.Values[0][0]
+	if !isInteger(x) || x.A.Sign() < 0 || !x.A.Num().IsInt64() || x.A.Num().Int64() > MaxDepth {
+		return 0, c.newError(ErrorTypeNonInteger, node, node,
+			"order must be an integer from 0 to %d", MaxDepth)
+	}
+	return int(x.A.Num().Int64()), nil
+}
+
+// precision converts the value computed for node into a precision
+func (c *Calculator) precision(node *node32, a Value) (uint, error) {
+	if !isScalar(a.Matrix) {
+		return 0, c.newError(ErrorTypeDimension, node, node, "precision must be a 1x1 matrix")
+	}
+	x := &a.Matrix.Values[0][0]
+	if !isInteger(x) || x.A.Sign() <= 0 || !x.A.Num().IsUint64() {
+		return 0, c.newError(ErrorTypeNonInteger, node, node, "precision must be a positive integer")
+	}
+	return uint(x.A.Num().Uint64()), nil
+}
+
+// text returns the text of a node without surrounding space
+func (c *Calculator) text(node *node32) string {
+	return strings.TrimSpace(string(c.buffer[node.begin:node.end]))
+}
+
+// matrix checks that the value computed for the nodes begin through end is a matrix
+func (c *Calculator) matrix(begin, end *node32, a Value) error {
+	if a.ValueType != ValueTypeMatrix || a.Matrix == nil {
+		return c.newError(ErrorTypeValue, begin, end, "expected a matrix")
+	}
+	return nil
+}
+
+// binary applies an arithmetic operation to the values computed for the
+// nodes begin through end
+func (c *Calculator) binary(begin, end *node32, a, b Value, operation func(a, b *complex.Matrix) error) error {
+	if err := c.matrix(begin, end, a); err != nil {
+		return err
+	}
+	if err := c.matrix(begin, end, b); err != nil {
+		return err
+	}
+	if err := operation(a.Matrix, b.Matrix); err != nil {
+		return c.locate(err, begin, end)
+	}
+	return nil
+}
+
 // Rulee is a root expresion
-func (c *Calculator) Rulee(node *node32) Value {
+func (c *Calculator) Rulee(node *node32) (Value, error) {
 	node = node.up
 	for node != nil {
 		switch node.pegRule {
+		case ruledefinition:
+			return c.Ruledefinition(node)
+		case rulerule:
+			return c.Rulerule(node)
+		case ruleassignment:
+			return c.Ruleassignment(node)
 		case rulee1:
 			return c.Rulee1(node)
 		}
 		node = node.next
 	}
-	return Value{}
+	return Value{}, nil
+}
+
+// Ruledefinition defines a function
+func (c *Calculator) Ruledefinition(node *node32) (Value, error) {
+	var (
+		name       string
+		parameters []string
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulename:
+			name = c.text(node)
+			if builtins[name] {
+				return Value{}, c.newError(ErrorTypeDomain, node, node, "%s is a built in name", name)
+			}
+		case rulevariable:
+			parameter := c.text(node)
+			if builtins[parameter] {
+				return Value{}, c.newError(ErrorTypeDomain, node, node, "%s is a built in name", parameter)
+			}
+			for _, p := range parameters {
+				if p == parameter {
+					return Value{}, c.newError(ErrorTypeDomain, node, node, "duplicate parameter %s", parameter)
+				}
+			}
+			parameters = append(parameters, parameter)
+		case rulee1:
+			body, err := c.expression(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if body == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+			c.Env.SetFunction(name, &Function{
+				Parameters: parameters,
+				Body:       body,
+			})
+			return Value{
+				ValueType:  ValueTypeExpression,
+				Expression: body,
+			}, nil
+		}
+		node = node.next
+	}
+	return Value{}, nil
+}
+
+// Rulerule adds a rewrite rule to the environment
+func (c *Calculator) Rulerule(node *node32) (Value, error) {
+	first := node
+	var expressions []*Node
+	node = node.up
+	for node != nil {
+		if node.pegRule == rulee1 {
+			a, err := c.expression(node)
+			if err != nil {
+				return Value{}, err
+			} else if a == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+			expressions = append(expressions, a)
+		}
+		node = node.next
+	}
+	rule, err := newRule(expressions[0], expressions[1])
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	c.Env.AddRule(rule)
+	return Value{
+		ValueType: ValueTypeRule,
+		Rule:      rule,
+	}, nil
+}
+
+// variable is the name of a variable of a symbolic form, the built in names
+// aren't variables
+func (c *Calculator) variable(node *node32) (string, error) {
+	name := c.text(node)
+	if builtins[name] {
+		return "", c.newError(ErrorTypeValue, node, node, "%s is a built in name", name)
+	}
+	return name, nil
+}
+
+// Ruleassignment binds the value of an expression to a variable
+func (c *Calculator) Ruleassignment(node *node32) (Value, error) {
+	var name string
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulevariable:
+			name = c.text(node)
+			if builtins[name] {
+				return Value{}, c.newError(ErrorTypeDomain, node, node, "%s is a built in name", name)
+			}
+		case rulee1:
+			a, err := c.Rulee1(node)
+			if err != nil {
+				return Value{}, err
+			}
+			c.Env.Set(name, a)
+			return a, nil
+		}
+		node = node.next
+	}
+	return Value{}, nil
 }
 
 // Rulee1 deals with addation or subtraction
-func (c *Calculator) Rulee1(node *node32) Value {
+func (c *Calculator) Rulee1(node *node32) (Value, error) {
 	node = node.up
-	var a Value
+	first := node
+	var (
+		a   Value
+		err error
+	)
 	for node != nil {
 		switch node.pegRule {
 		case rulee2:
-			a = c.Rulee2(node)
+			a, err = c.Rulee2(node)
+			if err != nil {
+				return Value{}, err
+			}
 		case ruleadd:
 			node = node.next
-			b := c.Rulee2(node)
-			a.Matrix.Add(a.Matrix, b.Matrix)
+			b, err := c.Rulee2(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, add); err != nil {
+				return Value{}, err
+			}
 		case ruleminus:
 			node = node.next
-			b := c.Rulee2(node)
-			a.Matrix.Sub(a.Matrix, b.Matrix)
+			b, err := c.Rulee2(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, subtract); err != nil {
+				return Value{}, err
+			}
 		}
 		node = node.next
 	}
-	return a
+	return a, nil
 }
 
 // Rulee2 deals with multiplication, division, or modulus
-func (c *Calculator) Rulee2(node *node32) Value {
+func (c *Calculator) Rulee2(node *node32) (Value, error) {
 	node = node.up
-	var a Value
+	first := node
+	var (
+		a   Value
+		err error
+	)
 	for node != nil {
 		switch node.pegRule {
 		case rulee3:
-			a = c.Rulee3(node)
+			a, err = c.Rulee3(node)
+			if err != nil {
+				return Value{}, err
+			}
 		case rulemultiply:
 			node = node.next
-			b := c.Rulee3(node)
-			a.Matrix.Mul(a.Matrix, b.Matrix)
+			b, err := c.Rulee3(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, multiply); err != nil {
+				return Value{}, err
+			}
 		case ruledivide:
 			node = node.next
-			b := c.Rulee3(node)
-			a.Matrix.Div(a.Matrix, b.Matrix)
+			b, err := c.Rulee3(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, divide); err != nil {
+				return Value{}, err
+			}
 		case rulemodulus:
 			node = node.next
-			b := c.Rulee3(node)
-			if a.Matrix.Values[0][0].A.Denom().Cmp(big.NewInt(1)) == 0 && b.Matrix.Values[0][0].A.Denom().Cmp(big.NewInt(1)) == 0 {
-				a.Matrix.Values[0][0].A.Num().Mod(a.Matrix.Values[0][0].A.Num(), b.Matrix.Values[0][0].A.Num())
+			b, err := c.Rulee3(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, modulus); err != nil {
+				return Value{}, err
 			}
 		}
 		node = node.next
 	}
-	return a
+	return a, nil
 }
 
 // Rulee3 deals with exponentiation
-func (c *Calculator) Rulee3(node *node32) Value {
+func (c *Calculator) Rulee3(node *node32) (Value, error) {
 	node = node.up
-	var a Value
+	first := node
+	var (
+		a   Value
+		err error
+	)
 	for node != nil {
 		switch node.pegRule {
 		case rulee4:
-			a = c.Rulee4(node)
+			a, err = c.Rulee4(node)
+			if err != nil {
+				return Value{}, err
+			}
 		case ruleexponentiation:
 			node = node.next
-			b := c.Rulee4(node)
-			a.Matrix.Pow(a.Matrix, &b.Matrix.Values[0][0])
+			b, err := c.Rulee4(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := c.binary(first, node, a, b, power); err != nil {
+				return Value{}, err
+			}
 		}
 		node = node.next
 	}
-	return a
+	return a, nil
 }
 
-// Rulee4 negates a number
-func (c *Calculator) Rulee4(node *node32) Value {
+// Rulee4 computes the factorials of a number and then negates it
+func (c *Calculator) Rulee4(node *node32) (Value, error) {
+	first := node
 	node = node.up
-	minus := false
+	var (
+		a     Value
+		err   error
+		minus bool
+	)
 	for node != nil {
 		switch node.pegRule {
 		case rulevalue:
-			a := c.Rulevalue(node)
-			if minus {
-				a.Matrix.Neg(a.Matrix)
+			a, err = c.Rulevalue(node)
+			if err != nil {
+				return Value{}, err
+			}
+		case rulefactorial:
+			if err := c.matrix(first, node, a); err != nil {
+				return Value{}, err
+			}
+			if err := factorialFunction(a.Matrix); err != nil {
+				return Value{}, c.locate(err, first, node)
 			}
-			return a
 		case ruleminus:
 			minus = true
 		}
 		node = node.next
 	}
-	return Value{}
+	if minus {
+		if err := c.matrix(first, first, a); err != nil {
+			return Value{}, err
+		}
+		a.Matrix.Neg(a.Matrix)
+	}
+	return a, nil
+}
+
+// argument evaluates the argument of a function which must be a matrix
+func (c *Calculator) argument(node *node32) (Value, error) {
+	node = node.up
+	for node != nil {
+		var (
+			a   Value
+			err error
+		)
+		switch node.pegRule {
+		case rulee1:
+			a, err = c.Rulee1(node)
+		case rulevalue:
+			a, err = c.Rulevalue(node)
+		default:
+			node = node.next
+			continue
+		}
+		if err != nil {
+			return Value{}, err
+		}
+		if err := c.matrix(node, node, a); err != nil {
+			return Value{}, err
+		}
+		return a, nil
+	}
+	return Value{}, nil
 }
 
 // Rulevalue evaluates the value
-func (c *Calculator) Rulevalue(node *node32) Value {
+func (c *Calculator) Rulevalue(node *node32) (Value, error) {
 	node = node.up
 	for node != nil {
 		switch node.pegRule {
 		case rulematrix:
 			return c.Rulematrix(node)
-		case ruleimaginary:
-			a := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
+		case ruleimaginary, rulenumber:
+			var decimal, exponent string
+			imaginary := node.pegRule == ruleimaginary
 			node := node.up
 			for node != nil {
 				switch node.pegRule {
 				case ruledecimal:
-					a.A.SetString(strings.TrimSpace(string(c.buffer[node.begin:node.end])))
+					decimal = c.text(node)
 				case rulenotation:
-					b := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
-					b.A.SetString(strings.TrimSpace(string(c.buffer[node.up.begin:node.up.end])))
-					c := complex.NewRational(big.NewRat(10, 1), big.NewRat(0, 1))
-					x := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
-					x.SetRat(c)
-					y := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
-					y.SetRat(b)
-					x.Pow(x, y).Rat(b)
-					a.Mul(a, b)
+					exponent = c.text(node.up)
 				}
 				node = node.next
 			}
-
-			a.A, a.B = a.B, a.A
-			b := complex.NewMatrix(prec)
-			b.Values = [][]complex.Rational{[]complex.Rational{*a}}
-			return Value{
-				ValueType: ValueTypeMatrix,
-				Matrix:    &b,
-			}
-		case rulenumber:
-			a := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
-			node := node.up
-			for node != nil {
-				switch node.pegRule {
-				case ruledecimal:
-					a.A.SetString(strings.TrimSpace(string(c.buffer[node.begin:node.end])))
-				case rulenotation:
-					b := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
-					b.A.SetString(strings.TrimSpace(string(c.buffer[node.up.begin:node.up.end])))
-					c := complex.NewRational(big.NewRat(10, 1), big.NewRat(0, 1))
-					x := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
-					x.SetRat(c)
-					y := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
-					y.SetRat(b)
-					x.Pow(x, y).Rat(b)
-					a.Mul(a, b)
-				}
-				node = node.next
+			a := parseNumber(decimal, exponent, c.Prec)
+			if imaginary {
+				a.A, a.B = a.B, a.A
 			}
-
-			b := complex.NewMatrix(prec)
-			b.Values = [][]complex.Rational{[]complex.Rational{*a}}
 			return Value{
 				ValueType: ValueTypeMatrix,
-				Matrix:    &b,
-			}
-		case ruleexp1:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Exp(a.Matrix)
-					return a
-				}
-				node = node.next
-			}
-		case ruleexp2:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulevalue {
-					a := c.Rulevalue(node)
-					a.Matrix.Exp(a.Matrix)
-					return a
-				}
-				node = node.next
+				Matrix:    newScalar(c.Prec, a),
+			}, nil
+		case ruleexp1, ruleexp2:
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
 			}
+			exponential(a.Matrix)
+			return a, nil
 		case rulenatural:
 			a := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
-			b := complex.NewMatrix(prec)
+			b := complex.NewMatrix(c.Prec)
 			b.Values = [][]complex.Rational{[]complex.Rational{*a}}
 			return Value{
 				ValueType: ValueTypeMatrix,
-				Matrix:    b.Exp(&b),
-			}
+				Matrix:    exponential(&b),
+			}, nil
 		case rulepi:
 			a := big.NewRat(1, 1)
-			bigfloat.PI(prec).Rat(a)
+			bigfloat.PI(c.Prec).Rat(a)
 			b := complex.NewRational(a, big.NewRat(0, 1))
-			c := complex.NewMatrix(prec)
-			c.Values = [][]complex.Rational{[]complex.Rational{*b}}
+			m := complex.NewMatrix(c.Prec)
+			m.Values = [][]complex.Rational{[]complex.Rational{*b}}
 			return Value{
 				ValueType: ValueTypeMatrix,
-				Matrix:    &c,
-			}
+				Matrix:    &m,
+			}, nil
+		case ruleinfinity:
+			return Value{
+				ValueType: ValueTypeExpression,
+				Expression: &Node{
+					Operation: OperationInfinity,
+				},
+			}, nil
 		case ruleprec:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					prec = uint(a.Matrix.Values[0][0].A.Num().Uint64())
-					return a
-				}
-				node = node.next
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
+			}
+			p, err := c.precision(node, a)
+			if err != nil {
+				return Value{}, err
 			}
+			c.setPrec(p)
+			return a, nil
+		case rulewithprec:
+			return c.Rulewithprec(node)
 		case rulesimplify:
 			node := node.up
 			for node != nil {
@@ -255,134 +681,593 @@ func (c *Calculator) Rulevalue(node *node32) Value {
 				}
 				node = node.next
 			}
-		case rulederivative:
+		case ruleexpand:
 			node := node.up
 			for node != nil {
 				if node.pegRule == rulee1 {
-					return c.Rulederivative(node)
+					return c.Ruleexpand(node)
 				}
 				node = node.next
 			}
+		case rulecollect:
+			return c.Rulecollect(node)
+		case rulederivative:
+			return c.Rulederivative(node)
+		case rulecheckderivative:
+			return c.Rulecheckderivative(node)
+		case rulegradient, rulejacobian, rulehessian:
+			return c.Rulegradient(node)
+		case ruleintegrate:
+			return c.Ruleintegrate(node)
+		case rulesolve:
+			return c.Rulesolve(node)
+		case ruleseries:
+			return c.Ruleseries(node)
+		case rulelimit:
+			return c.Rulelimit(node)
 		case rulelog:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Log(a.Matrix)
-					return a
-				}
-				node = node.next
+			return c.Rulelog(node)
+		case rulelog2, rulelog10:
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
+			}
+			base := int64(2)
+			if node.pegRule == rulelog10 {
+				base = 10
+			}
+			b := newScalar(c.Prec, complex.NewRational(big.NewRat(base, 1), big.NewRat(0, 1)))
+			if err := logarithmBase(a.Matrix, b); err != nil {
+				return Value{}, c.locate(err, node, node)
+			}
+			return a, nil
+		case ruleexponent2:
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
+			}
+			b := newScalar(c.Prec, complex.NewRational(big.NewRat(2, 1), big.NewRat(0, 1)))
+			if err := power(b, a.Matrix); err != nil {
+				return Value{}, c.locate(err, node, node)
 			}
+			a.Matrix = b
+			return a, nil
 		case rulesqrt:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Sqrt(a.Matrix)
-					return a
-				}
-				node = node.next
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
 			}
+			squareRoot(a.Matrix)
+			return a, nil
 		case rulecos:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Cos(a.Matrix)
-					return a
-				}
-				node = node.next
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
 			}
+			cosine(a.Matrix)
+			return a, nil
 		case rulesin:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Sin(a.Matrix)
-					return a
-				}
-				node = node.next
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
 			}
+			sine(a.Matrix)
+			return a, nil
 		case ruletan:
-			node := node.up
-			for node != nil {
-				if node.pegRule == rulee1 {
-					a := c.Rulee1(node)
-					a.Matrix.Tan(a.Matrix)
-					return a
-				}
-				node = node.next
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
 			}
+			tangent(a.Matrix)
+			return a, nil
+		case ruleasin, ruleacos, ruleatan, rulesinh, rulecosh, ruletanh,
+			ruleasinh, ruleacosh, ruleatanh, rulesec, rulecsc, rulecot,
+			rulegamma, rulelgamma, ruleerf, ruleerfc, ruledigamma,
+			ruleairyai, ruleairybi, ruleairyaiprime, ruleairybiprime, ruleellipk, ruleellipe,
+			ruleisprime, rulenextprime, rulefactor, ruletotient:
+			a, err := c.argument(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if err := functionMatrices[functionRules[node.pegRule]](a.Matrix); err != nil {
+				return Value{}, c.locate(err, node, node)
+			}
+			return a, nil
+		case ruleatan2:
+			return c.Ruleatan2(node)
+		case rulebeta:
+			return c.Rulebeta(node)
+		case rulezeta:
+			return c.Rulezeta(node)
+		case rulepolygamma:
+			return c.Rulepolygamma(node)
+		case rulelambertw:
+			return c.Rulelambertw(node)
+		case rulebesselj, rulebessely, rulebesseli, rulebesselk:
+			return c.Rulebessel(node)
+		case rulegcd, rulelcm, rulepowmod, ruleinvmod, rulecrt, rulejacobi:
+			return c.Ruleinteger(node)
+		case ruleeval:
+			return c.Ruleeval(node)
+		case rulecall:
+			return c.Rulecall(node)
+		case rulevariable:
+			name := c.text(node)
+			if a, ok := c.Env.Get(name); ok {
+				return a, nil
+			}
+			return Value{}, c.newError(ErrorTypeUnknownIdentifier, node, node, "%s is not defined", name)
 		case rulesub:
 			return c.Rulesub(node)
 		}
 		node = node.next
 	}
-	return Value{}
+	return Value{}, nil
 }
 
-// Rulematrix computes the matrix
-func (c *Calculator) Rulematrix(node *node32) Value {
-	node = node.up
-	x := complex.NewMatrix(prec)
-	x.Values = make([][]complex.Rational, 1)
-	for node != nil {
-		switch node.pegRule {
-		case rulee1:
-			a, end := c.Rulee1(node), len(x.Values)-1
-			if len(a.Matrix.Values) == 1 && len(a.Matrix.Values[0]) == 1 {
-				x.Values[end] = append(x.Values[end], a.Matrix.Values[0][0])
-				break
-			}
-			panic("matrix within matrix not allowed")
-		case rulerow:
-			x.Values = append(x.Values, make([]complex.Rational, 0, 8))
+// operands evaluates the arguments of a function which must be matrices
+func (c *Calculator) operands(node *node32) ([]Value, error) {
+	var operands []Value
+	for node = node.up; node != nil; node = node.next {
+		if node.pegRule != rulee1 {
+			continue
 		}
-		node = node.next
+		a, err := c.Rulee1(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.matrix(node, node, a); err != nil {
+			return nil, err
+		}
+		operands = append(operands, a)
 	}
-	return Value{
-		ValueType: ValueTypeMatrix,
-		Matrix:    &x,
+	return operands, nil
+}
+
+// Rulelog computes the natural logarithm or the logarithm to a base
+func (c *Calculator) Rulelog(node *node32) (Value, error) {
+	first := node
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	a := operands[0]
+	if len(operands) == 1 {
+		if err := logarithm(a.Matrix); err != nil {
+			return Value{}, c.locate(err, first, first)
+		}
+		return a, nil
 	}
+	if err := logarithmBase(a.Matrix, operands[1].Matrix); err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return a, nil
 }
 
-// Convert converts to an expression
-func (c *Calculator) Convert(node *node32) Value {
-	var (
-		convert      func(node *node32) (a *Node)
-		convertValue func(node *node32) (a *Node)
-	)
-	convertValue = func(node *node32) (a *Node) {
-		node = node.up
-		for node != nil {
-			switch node.pegRule {
-			case rulevalue:
-				a = convertValue(node)
-			case ruleminus:
-				a = &Node{
-					Operation: OperationNegate,
-					Left:      convertValue(node),
-				}
-			case rulevariable:
-				a = &Node{
-					Operation: OperationVariable,
-					Value:     strings.TrimSpace(string(c.buffer[node.begin:node.end])),
-				}
-			case ruleimaginary:
-				node := node.up
-				a = &Node{
-					Operation: OperationImaginary,
-				}
-				for node != nil {
-					switch node.pegRule {
-					case ruledecimal:
-						a.Value = strings.TrimSpace(string(c.buffer[node.begin:node.end]))
-					case rulenotation:
-						a.Left = &Node{
-							Operation: OperationImaginary,
-							Value:     a.Value,
-						}
-						a.Operation = OperationNotation
+// Ruleatan2 computes the angle of the point with the coordinates y and x
+func (c *Calculator) Ruleatan2(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := arctangent2(operands[0].Matrix, operands[1].Matrix); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[0], nil
+}
+
+// Rulebeta computes the beta function
+func (c *Calculator) Rulebeta(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := betaFunction(operands[0].Matrix, operands[1].Matrix); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[0], nil
+}
+
+// Rulezeta computes the Riemann zeta function or its derivative of the order
+// given by the first argument
+func (c *Calculator) Rulezeta(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(operands) == 1 {
+		err = zetaFunction(operands[0].Matrix)
+	} else {
+		operands[0], operands[1] = operands[1], operands[0]
+		err = zetaDerivative(operands[0].Matrix, operands[1].Matrix)
+	}
+	if err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[0], nil
+}
+
+// Rulepolygamma computes the polygamma function of the order given by the
+// first argument
+func (c *Calculator) Rulepolygamma(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := polygammaFunction(operands[1].Matrix, operands[0].Matrix); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[1], nil
+}
+
+// Rulelambertw computes the Lambert W function on the principal branch or on
+// the branch given by the second argument
+func (c *Calculator) Rulelambertw(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	k := newScalar(c.Prec, realRational(new(big.Rat)))
+	if len(operands) == 2 {
+		k = operands[1].Matrix
+	}
+	if err := lambertFunction(operands[0].Matrix, k); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[0], nil
+}
+
+// Rulebessel computes the Bessel function of the order given by the first
+// argument
+func (c *Calculator) Rulebessel(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := besselFunction(besselRules[node.pegRule])(operands[1].Matrix, operands[0].Matrix); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[1], nil
+}
+
+// Ruleinteger computes the number theoretic function of the integer arguments
+func (c *Calculator) Ruleinteger(node *node32) (Value, error) {
+	operands, err := c.operands(node)
+	if err != nil {
+		return Value{}, err
+	}
+	matrices := make([]*complex.Matrix, len(operands))
+	for i, operand := range operands {
+		matrices[i] = operand.Matrix
+	}
+	if err := integerMatrices[integerRules[node.pegRule]](matrices...); err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
+	return operands[0], nil
+}
+
+// Rulewithprec evaluates an expression at a precision and then restores the
+// prior precision
+func (c *Calculator) Rulewithprec(node *node32) (Value, error) {
+	first := node
+	node = node.up
+	for node != nil && node.pegRule != rulee1 {
+		node = node.next
+	}
+	a, err := c.Rulee1(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := c.matrix(node, node, a); err != nil {
+		return Value{}, err
+	}
+	p, err := c.precision(node, a)
+	if err != nil {
+		return Value{}, err
+	}
+	node = node.next
+	for node != nil && node.pegRule != rulee1 {
+		node = node.next
+	}
+	if node == nil {
+		return Value{}, c.newError(ErrorTypeValue, first, first, "missing expression")
+	}
+	prior := c.Prec
+	c.setPrec(p)
+	defer func() {
+		c.Prec = prior
+	}()
+	return c.Rulee1(node)
+}
+
+// Ruleeval evaluates an expression with variables bound to values
+func (c *Calculator) Ruleeval(node *node32) (Value, error) {
+	first := node
+	var expression *node32
+	scope, substitutions := c.Env.scope(), make(map[string]*Node)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			expression = node
+		case rulebinding:
+			var name string
+			binding := node.up
+			for binding != nil {
+				switch binding.pegRule {
+				case rulevariable:
+					var err error
+					if name, err = c.variable(binding); err != nil {
+						return Value{}, err
+					} else if _, ok := scope.values[name]; ok {
+						return Value{}, c.newError(ErrorTypeDomain, binding, binding, "duplicate binding %s", name)
+					}
+				case rulee1:
+					a, err := c.Rulee1(binding)
+					if err != nil {
+						return Value{}, err
+					}
+					scope.values[name] = a
+					if a.Matrix == nil {
+						substitutions[name] = a.Expression
+					}
+				}
+				binding = binding.next
+			}
+		}
+		node = node.next
+	}
+
+	// the bindings are substituted into the symbolic form of the expression
+	// before it is evaluated, so that symbolic and numeric parts can be mixed
+	prior := c.Env
+	c.Env = scope
+	e, err := c.expression(expression)
+	c.Env = prior
+	if err != nil {
+		return Value{}, err
+	} else if e == nil {
+		return Value{}, c.newError(ErrorTypeValue, expression, expression, "unsupported expression")
+	}
+	e, err = e.Substitute(substitutions).Inline(scope)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	if len(substitutions) > 0 {
+		// a binding to an expression leaves the result symbolic
+		for name, a := range scope.values {
+			if a.Matrix != nil && isScalar(a.Matrix) {
+				substitutions[name] = newNumber(&a.Matrix.Values[0][0])
+			}
+		}
+		return Value{
+			ValueType:  ValueTypeExpression,
+			Expression: e.Substitute(substitutions).Simplify(),
+		}, nil
+	}
+	m, err := e.evaluate(scope, c.Prec, 0)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    m,
+	}, nil
+}
+
+// Rulecall calls a user defined function
+func (c *Calculator) Rulecall(node *node32) (Value, error) {
+	first := node
+	call := &Node{
+		Operation: OperationCall,
+	}
+	var arguments []Value
+	symbolic := false
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulename:
+			call.Value = c.text(node)
+		case rulee1:
+			a, err := c.Rulee1(node)
+			if err != nil {
+				return Value{}, err
+			}
+			symbolic = symbolic || a.Matrix == nil
+			arguments = append(arguments, a)
+		}
+		node = node.next
+	}
+	function, err := c.Env.function(call.Value, len(arguments), 0)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	if symbolic {
+		bindings := make(map[string]*Node, len(arguments))
+		for i, a := range arguments {
+			if a.Matrix == nil {
+				bindings[function.Parameters[i]] = a.Expression
+				continue
+			} else if !isScalar(a.Matrix) {
+				return Value{}, c.newError(ErrorTypeDimension, first, first,
+					"symbolic calls require 1x1 matrices")
+			}
+			bindings[function.Parameters[i]] = newNumber(&a.Matrix.Values[0][0])
+		}
+		expression, err := function.Body.Substitute(bindings).Inline(c.Env)
+		if err != nil {
+			return Value{}, c.locate(err, first, first)
+		}
+		return Value{
+			ValueType:  ValueTypeExpression,
+			Expression: expression,
+		}, nil
+	}
+	scope := c.Env.scope()
+	for i, a := range arguments {
+		scope.values[function.Parameters[i]] = a
+	}
+	a, err := function.Body.evaluate(scope, c.Prec, 1)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    a,
+	}, nil
+}
+
+// Rulematrix computes the matrix, which is a matrix of expressions if any
+// of the elements are expressions
+func (c *Calculator) Rulematrix(node *node32) (Value, error) {
+	first := node
+	node = node.up
+	values := [][]Value{nil}
+	symbolic := false
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			a, err := c.Rulee1(node)
+			if err != nil {
+				return Value{}, err
+			}
+			if (a.Matrix != nil && !isScalar(a.Matrix)) ||
+				(a.Matrix == nil && a.Expression.Operation == OperationMatrix) {
+				return Value{}, c.newError(ErrorTypeDimension, node, node, "matrix within matrix not allowed")
+			}
+			symbolic = symbolic || a.Matrix == nil
+			end := len(values) - 1
+			values[end] = append(values[end], a)
+		case rulerow:
+			values = append(values, nil)
+		}
+		node = node.next
+	}
+	for _, row := range values {
+		if len(row) == 0 || len(row) != len(values[0]) {
+			return Value{}, c.newError(ErrorTypeDimension, first, first, "rows must have the same number of columns")
+		}
+	}
+	if symbolic {
+		rows := make([][]*Node, len(values))
+		for i, row := range values {
+			for _, a := range row {
+				if a.Matrix != nil {
+					a.Expression = newNumber(&a.Matrix.Values[0][0])
+				}
+				rows[i] = append(rows[i], a.Expression)
+			}
+		}
+		return Value{
+			ValueType:  ValueTypeExpression,
+			Expression: newMatrix(rows),
+		}, nil
+	}
+	x := complex.NewMatrix(c.Prec)
+	x.Values = make([][]complex.Rational, len(values))
+	for i, row := range values {
+		for _, a := range row {
+			x.Values[i] = append(x.Values[i], a.Matrix.Values[0][0])
+		}
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    &x,
+	}, nil
+}
+
+// Convert converts to an expression
+func (c *Calculator) Convert(node *node32) Value {
+	a, _ := c.expression(node)
+	return Value{
+		ValueType:  ValueTypeExpression,
+		Expression: a,
+	}
+}
+
+// toNode converts a value into an expression
+func toNode(a Value) *Node {
+	if a.Matrix == nil {
+		return a.Expression
+	} else if isScalar(a.Matrix) {
+		return newNumber(&a.Matrix.Values[0][0])
+	}
+	rows := make([][]*Node, len(a.Matrix.Values))
+	for i, row := range a.Matrix.Values {
+		for j := range row {
+			rows[i] = append(rows[i], newNumber(&row[j]))
+		}
+	}
+	return newMatrix(rows)
+}
+
+// expression converts to an expression, the symbolic operations within the
+// expression are computed during the conversion
+func (c *Calculator) expression(node *node32) (*Node, error) {
+	var (
+		err          error
+		convert      func(node *node32) (a *Node)
+		convertValue func(node *node32) (a *Node)
+	)
+	convertValue = func(node *node32) (a *Node) {
+		value, minus := node, false
+		node = node.up
+		for node != nil {
+			switch node.pegRule {
+			case rulevalue:
+				a = convertValue(node)
+			case rulefactorial:
+				a = &Node{
+					Operation: OperationFactorial,
+					Left:      a,
+				}
+			case ruleminus:
+				minus = true
+			case rulesimplify, ruleexpand, rulecollect, rulederivative, rulegradient,
+				rulejacobian, rulehessian, ruleintegrate, ruleseries, rulelimit, ruleeval,
+				ruleprec, rulewithprec, rulecheckderivative, rulesolve:
+				b, e := c.Rulevalue(value)
+				if e != nil {
+					if err == nil {
+						err = e
+					}
+					return nil
+				}
+				return toNode(b)
+			case rulematrix:
+				node := node.up
+				a = newMatrix([][]*Node{nil})
+				for node != nil {
+					switch node.pegRule {
+					case rulee1:
+						end := len(a.Rows) - 1
+						a.Rows[end] = append(a.Rows[end], convert(node))
+					case rulerow:
+						a.Rows = append(a.Rows, nil)
+					}
+					node = node.next
+				}
+				return a
+			case rulevariable:
+				a = &Node{
+					Operation: OperationVariable,
+					Value:     strings.TrimSpace(string(c.buffer[node.begin:node.end])),
+				}
+			case ruleimaginary:
+				node := node.up
+				a = &Node{
+					Operation: OperationImaginary,
+				}
+				for node != nil {
+					switch node.pegRule {
+					case ruledecimal:
+						a.Value = strings.TrimSpace(string(c.buffer[node.begin:node.end]))
+					case rulenotation:
+						a.Left = &Node{
+							Operation: OperationImaginary,
+							Value:     a.Value,
+						}
+						a.Operation = OperationNotation
 						a.Value = ""
 						a.Right = &Node{
 							Operation: OperationNumber,
@@ -434,7 +1319,7 @@ func (c *Calculator) Convert(node *node32) Value {
 					if node.pegRule == rulevalue {
 						a = &Node{
 							Operation: OperationNaturalExponentiation,
-							Left:      convert(node),
+							Left:      convertValue(node),
 						}
 						return a
 					}
@@ -450,13 +1335,58 @@ func (c *Calculator) Convert(node *node32) Value {
 					Operation: OperationPI,
 				}
 				return a
+			case ruleinfinity:
+				a = &Node{
+					Operation: OperationInfinity,
+				}
+				return a
 			case rulelog:
+				a = &Node{
+					Operation: OperationNaturalLogarithm,
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						if a.Left == nil {
+							a.Left = convert(node)
+						} else {
+							a.Operation, a.Right = OperationLogarithm, convert(node)
+						}
+					}
+					node = node.next
+				}
+				return a
+			case rulelog2, rulelog10:
+				base := "2"
+				if node.pegRule == rulelog10 {
+					base = "10"
+				}
 				node := node.up
 				for node != nil {
 					if node.pegRule == rulee1 {
 						a = &Node{
-							Operation: OperationNaturalLogarithm,
+							Operation: OperationLogarithm,
 							Left:      convert(node),
+							Right: &Node{
+								Operation: OperationNumber,
+								Value:     base,
+							},
+						}
+						return a
+					}
+					node = node.next
+				}
+			case ruleexponent2:
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						a = &Node{
+							Operation: OperationExponentiation,
+							Left: &Node{
+								Operation: OperationNumber,
+								Value:     "2",
+							},
+							Right: convert(node),
 						}
 						return a
 					}
@@ -510,6 +1440,149 @@ func (c *Calculator) Convert(node *node32) Value {
 					}
 					node = node.next
 				}
+			case ruleasin, ruleacos, ruleatan, rulesinh, rulecosh, ruletanh,
+				ruleasinh, ruleacosh, ruleatanh, rulesec, rulecsc, rulecot,
+				rulegamma, rulelgamma, ruleerf, ruleerfc, ruledigamma,
+				ruleairyai, ruleairybi, ruleairyaiprime, ruleairybiprime, ruleellipk, ruleellipe,
+				ruleisprime, rulenextprime, rulefactor, ruletotient:
+				operation := functionRules[node.pegRule]
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						a = &Node{
+							Operation: operation,
+							Left:      convert(node),
+						}
+						return a
+					}
+					node = node.next
+				}
+			case ruleatan2, rulebeta:
+				a = &Node{
+					Operation: OperationArctangent2,
+				}
+				if node.pegRule == rulebeta {
+					a.Operation = OperationBeta
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						if a.Left == nil {
+							a.Left = convert(node)
+						} else {
+							a.Right = convert(node)
+						}
+					}
+					node = node.next
+				}
+				return a
+			case rulezeta, rulepolygamma:
+				// the order is the first of two arguments
+				var arguments []*Node
+				rule, node := node.pegRule, node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						arguments = append(arguments, convert(node))
+					}
+					node = node.next
+				}
+				if len(arguments) == 1 {
+					a = &Node{
+						Operation: OperationZeta,
+						Left:      arguments[0],
+					}
+					return a
+				}
+				a = &Node{
+					Operation: OperationZetaDerivative,
+					Left:      arguments[1],
+					Right:     arguments[0],
+				}
+				if rule == rulepolygamma {
+					a.Operation = OperationPolygamma
+				}
+				return a
+			case rulelambertw:
+				// the branch is zero if it is omitted
+				a = &Node{
+					Operation: OperationLambertW,
+					Right: &Node{
+						Operation: OperationNumber,
+						Value:     "0",
+					},
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						if a.Left == nil {
+							a.Left = convert(node)
+						} else {
+							a.Right = convert(node)
+						}
+					}
+					node = node.next
+				}
+				return a
+			case rulegcd, rulelcm, ruleinvmod, rulecrt, rulejacobi:
+				a = &Node{
+					Operation: integerRules[node.pegRule],
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						if a.Left == nil {
+							a.Left = convert(node)
+						} else {
+							a.Right = convert(node)
+						}
+					}
+					node = node.next
+				}
+				return a
+			case rulepowmod:
+				a = &Node{
+					Operation: OperationPowMod,
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						a.Arguments = append(a.Arguments, convert(node))
+					}
+					node = node.next
+				}
+				return a
+			case rulebesselj, rulebessely, rulebesseli, rulebesselk:
+				// the order is the first argument
+				a = &Node{
+					Operation: besselRules[node.pegRule],
+				}
+				node := node.up
+				for node != nil {
+					if node.pegRule == rulee1 {
+						if a.Right == nil {
+							a.Right = convert(node)
+						} else {
+							a.Left = convert(node)
+						}
+					}
+					node = node.next
+				}
+				return a
+			case rulecall:
+				node := node.up
+				a = &Node{
+					Operation: OperationCall,
+				}
+				for node != nil {
+					switch node.pegRule {
+					case rulename:
+						a.Value = strings.TrimSpace(string(c.buffer[node.begin:node.end]))
+					case rulee1:
+						a.Arguments = append(a.Arguments, convert(node))
+					}
+					node = node.next
+				}
+				return a
 			case rulesub:
 				node := node.up
 				for node != nil {
@@ -521,6 +1594,12 @@ func (c *Calculator) Convert(node *node32) Value {
 			}
 			node = node.next
 		}
+		if minus {
+			a = &Node{
+				Operation: OperationNegate,
+				Left:      a,
+			}
+		}
 		return a
 	}
 	convert = func(node *node32) (a *Node) {
@@ -578,39 +1657,489 @@ func (c *Calculator) Convert(node *node32) Value {
 		}
 		return a
 	}
+	a := convert(node)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Rulesimplify simplifies the expression with the rules of the environment
+// and then the default rules
+func (c *Calculator) Rulesimplify(node *node32) (Value, error) {
+	expression, err := c.expression(node)
+	if err != nil {
+		return Value{}, err
+	} else if expression == nil {
+		return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+	}
+	expression, err = expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, node, node)
+	}
 	return Value{
 		ValueType:  ValueTypeExpression,
-		Expression: convert(node),
-	}
+		Expression: expression.SimplifyWith(append(c.Env.Rules(), DefaultRules...)),
+	}, nil
 }
 
-// Rulesimplify simplifies the expression
-func (c *Calculator) Rulesimplify(node *node32) Value {
-	expression := c.Convert(node).Expression
-	if expression != nil {
-		expression = expression.Simplify()
+// Ruleexpand multiplies out the products and powers of sums in the expression
+func (c *Calculator) Ruleexpand(node *node32) (Value, error) {
+	expression, err := c.expression(node)
+	if err != nil {
+		return Value{}, err
+	} else if expression == nil {
+		return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+	}
+	expression, err = expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, node, node)
 	}
 	return Value{
 		ValueType:  ValueTypeExpression,
-		Expression: expression,
+		Expression: expression.Simplify().Expand(),
+	}, nil
+}
+
+// Rulecollect gathers the terms of the expression with the same power of a
+// variable
+func (c *Calculator) Rulecollect(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variable   string
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			var err error
+			if expression, err = c.expression(node); err != nil {
+				return Value{}, err
+			} else if expression == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
 	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType:  ValueTypeExpression,
+		Expression: expression.Simplify().Collect(variable),
+	}, nil
 }
 
-// Rulederivative computes the symbolic derivative of a number
-func (c *Calculator) Rulederivative(node *node32) Value {
-	expression := c.Convert(node).Expression
-	derivative := expression.Derivative()
-	if derivative != nil {
+// Rulederivative computes the symbolic derivative of an expression with
+// respect to a variable, optionally of a higher order
+func (c *Calculator) Rulederivative(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variable   string
+	)
+	order := 1
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			if expression != nil {
+				a, err := c.Rulee1(node)
+				if err != nil {
+					return Value{}, err
+				}
+				if order, err = c.order(node, a); err != nil {
+					return Value{}, err
+				}
+				break
+			}
+			var err error
+			if expression, err = c.expression(node); err != nil {
+				return Value{}, err
+			} else if expression == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
+	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	var derivative *Node
+	if variable != "" {
+		derivative = expression.DerivativeN(variable, order)
+	} else if derivative, err = expression.DerivativeE(); err != nil {
+		return Value{}, c.locate(err, first, first)
+	} else if derivative != nil {
 		derivative = derivative.Simplify()
 	}
+	if derivative == nil {
+		return Value{}, c.newError(ErrorTypeValue, first, first, "unsupported expression")
+	}
 	return Value{
 		ValueType:  ValueTypeExpression,
 		Expression: derivative,
+	}, nil
+}
+
+// Rulecheckderivative compares the symbolic derivative of an expression at a
+// point with a finite difference
+func (c *Calculator) Rulecheckderivative(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variable   string
+		point      *complex.Rational
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			if expression != nil {
+				a, err := c.Rulee1(node)
+				if err != nil {
+					return Value{}, err
+				}
+				if err := c.matrix(node, node, a); err != nil {
+					return Value{}, err
+				} else if !isScalar(a.Matrix) {
+					return Value{}, c.newError(ErrorTypeDimension, node, node, "point must be a 1x1 matrix")
+				}
+				point = &a.Matrix.Values[0][0]
+				break
+			}
+			var err error
+			if expression, err = c.expression(node); err != nil {
+				return Value{}, err
+			} else if expression == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
 	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	a, err := expression.checkDerivative(c.Env, variable, point, c.Prec)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    a,
+	}, nil
+}
+
+// Rulegradient computes the gradient, jacobian or hessian of an expression
+// with respect to a vector of variables
+func (c *Calculator) Rulegradient(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variables  []string
+	)
+	node = node.up
+	for node != nil {
+		if node.pegRule != rulee1 {
+			node = node.next
+			continue
+		}
+		a, err := c.expression(node)
+		if err != nil {
+			return Value{}, err
+		} else if a == nil {
+			return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+		}
+		if expression == nil {
+			expression = a
+			if first.pegRule != rulejacobian && a.Operation == OperationMatrix {
+				return Value{}, c.newError(ErrorTypeDimension, node, node, "expected a scalar expression")
+			}
+			node = node.next
+			continue
+		}
+		if a.Operation == OperationMatrix && len(a.Rows) != 1 && len(a.Rows[0]) != 1 {
+			return Value{}, c.newError(ErrorTypeDimension, node, node, "expected a vector of variables")
+		}
+		seen := make(map[string]bool)
+		for _, element := range a.Elements() {
+			if element.Operation != OperationVariable {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "%s is not a variable", element)
+			} else if seen[element.Value] {
+				return Value{}, c.newError(ErrorTypeDomain, node, node, "duplicate variable %s", element.Value)
+			}
+			seen[element.Value] = true
+			variables = append(variables, element.Value)
+		}
+		node = node.next
+	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	switch first.pegRule {
+	case rulegradient:
+		expression = expression.Gradient(variables)
+	case rulejacobian:
+		expression = expression.Jacobian(variables)
+	case rulehessian:
+		expression = expression.Hessian(variables)
+	}
+	if expression == nil {
+		return Value{}, c.newError(ErrorTypeValue, first, first, "unsupported expression")
+	}
+	return Value{
+		ValueType:  ValueTypeExpression,
+		Expression: expression,
+	}, nil
+}
+
+// Ruleintegrate computes the symbolic integral of an expression with respect
+// to a variable, or the numeric integral if the bounds are given
+func (c *Calculator) Ruleintegrate(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variable   string
+		bounds     []*complex.Rational
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			if expression != nil {
+				a, err := c.Rulee1(node)
+				if err != nil {
+					return Value{}, err
+				}
+				if err := c.matrix(node, node, a); err != nil {
+					return Value{}, err
+				} else if !isScalar(a.Matrix) {
+					return Value{}, c.newError(ErrorTypeDimension, node, node, "bound must be a 1x1 matrix")
+				}
+				bounds = append(bounds, &a.Matrix.Values[0][0])
+				break
+			}
+			var err error
+			if expression, err = c.expression(node); err != nil {
+				return Value{}, err
+			} else if expression == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
+	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	if bounds == nil {
+		return Value{
+			ValueType:  ValueTypeExpression,
+			Expression: expression.Integrate(variable),
+		}, nil
+	}
+	// the estimated error of the integral is within the precision of the
+	// result, otherwise the convergence error reports the estimate
+	a, _, err := expression.integral(c.Env, variable, bounds[0], bounds[1], c.Prec)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    a,
+	}, nil
+}
+
+// Rulesolve finds a root of an expression in a variable near a guess or
+// within a bracket
+func (c *Calculator) Rulesolve(node *node32) (Value, error) {
+	first := node
+	var (
+		expression *Node
+		variable   string
+		guesses    []*complex.Rational
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			if expression != nil {
+				a, err := c.Rulee1(node)
+				if err != nil {
+					return Value{}, err
+				}
+				if err := c.matrix(node, node, a); err != nil {
+					return Value{}, err
+				} else if !isScalar(a.Matrix) {
+					return Value{}, c.newError(ErrorTypeDimension, node, node, "guess must be a 1x1 matrix")
+				}
+				guesses = append(guesses, &a.Matrix.Values[0][0])
+				break
+			}
+			var err error
+			if expression, err = c.expression(node); err != nil {
+				return Value{}, err
+			} else if expression == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
+	}
+	expression, err := expression.Inline(c.Env)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	var b *complex.Rational
+	if len(guesses) > 1 {
+		b = guesses[1]
+	}
+	a, err := expression.solve(c.Env, variable, guesses[0], b, c.Prec)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    a,
+	}, nil
+}
+
+// Ruleseries computes the truncated Taylor or Laurent series of an
+// expression in a variable around a point
+func (c *Calculator) Ruleseries(node *node32) (Value, error) {
+	first := node
+	var (
+		expression, point *Node
+		variable          string
+		order             int
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			if point != nil {
+				a, err := c.Rulee1(node)
+				if err != nil {
+					return Value{}, err
+				}
+				if order, err = c.order(node, a); err != nil {
+					return Value{}, err
+				}
+				break
+			}
+			a, err := c.expression(node)
+			if err != nil {
+				return Value{}, err
+			} else if a == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+			if a, err = a.Inline(c.Env); err != nil {
+				return Value{}, c.locate(err, node, node)
+			}
+			if expression == nil {
+				expression = a
+			} else {
+				point = a
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		}
+		node = node.next
+	}
+	a, err := expression.Series(variable, point, order)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType:  ValueTypeExpression,
+		Expression: a,
+	}, nil
+}
+
+// Rulelimit computes the limit of an expression as a variable approaches a
+// target, optionally from one side
+func (c *Calculator) Rulelimit(node *node32) (Value, error) {
+	first := node
+	var (
+		expression, target *Node
+		variable           string
+		side               int
+	)
+	node = node.up
+	for node != nil {
+		switch node.pegRule {
+		case rulee1:
+			a, err := c.expression(node)
+			if err != nil {
+				return Value{}, err
+			} else if a == nil {
+				return Value{}, c.newError(ErrorTypeValue, node, node, "unsupported expression")
+			}
+			if a, err = a.Inline(c.Env); err != nil {
+				return Value{}, c.locate(err, node, node)
+			}
+			if expression == nil {
+				expression = a
+			} else {
+				target = a
+			}
+		case rulevariable:
+			var err error
+			if variable, err = c.variable(node); err != nil {
+				return Value{}, err
+			}
+		case ruleside:
+			side = 1
+			if c.text(node) == "-" {
+				side = -1
+			}
+		}
+		node = node.next
+	}
+	a, err := expression.limit(c.Env, variable, target, side, c.Prec)
+	if err != nil {
+		return Value{}, c.locate(err, first, first)
+	}
+	return Value{
+		ValueType:  ValueTypeExpression,
+		Expression: a,
+	}, nil
 }
 
 // Rulesub computes the subexpression
-func (c *Calculator) Rulesub(node *node32) Value {
+func (c *Calculator) Rulesub(node *node32) (Value, error) {
 	node = node.up
 	for node != nil {
 		switch node.pegRule {
@@ -619,5 +2148,5 @@ func (c *Calculator) Rulesub(node *node32) Value {
 		}
 		node = node.next
 	}
-	return Value{}
+	return Value{}, nil
 }
@@ -19,11 +19,16 @@ type pegRule uint8
 const (
 	ruleUnknown pegRule = iota
 	rulee
+	ruledefinition
+	rulerule
+	ruleassignment
 	rulee1
 	rulee2
 	rulee3
 	rulee4
 	rulevalue
+	rulecall
+	rulename
 	rulevariable
 	rulematrix
 	ruleimaginary
@@ -34,14 +39,74 @@ const (
 	ruleexp2
 	rulenatural
 	rulepi
+	ruleinfinity
 	ruleprec
+	rulewithprec
 	rulesimplify
+	ruleexpand
+	rulecollect
 	rulederivative
+	rulecheckderivative
+	rulegradient
+	rulejacobian
+	rulehessian
+	ruleintegrate
+	rulesolve
+	ruleseries
+	rulelimit
+	ruleside
+	ruleeval
+	rulebinding
 	rulelog
+	rulelog2
+	rulelog10
+	ruleexponent2
 	rulesqrt
 	rulecos
 	rulesin
 	ruletan
+	ruleasin
+	ruleacos
+	ruleatan2
+	ruleatan
+	rulesinh
+	rulecosh
+	ruletanh
+	ruleasinh
+	ruleacosh
+	ruleatanh
+	rulesec
+	rulecsc
+	rulecot
+	rulegamma
+	rulelgamma
+	rulebeta
+	ruleerf
+	ruleerfc
+	rulezeta
+	ruledigamma
+	rulepolygamma
+	rulelambertw
+	rulebesselj
+	rulebessely
+	rulebesseli
+	rulebesselk
+	ruleairyaiprime
+	ruleairybiprime
+	ruleairyai
+	ruleairybi
+	ruleellipk
+	ruleellipe
+	rulegcd
+	rulelcm
+	ruleisprime
+	rulenextprime
+	rulefactor
+	ruletotient
+	rulepowmod
+	ruleinvmod
+	rulecrt
+	rulejacobi
 	rulesub
 	ruleadd
 	ruleminus
@@ -49,8 +114,12 @@ const (
 	ruledivide
 	rulemodulus
 	ruleexponentiation
+	rulefactorial
 	ruleopen
 	ruleclose
+	rulecomma
+	ruleequals
+	rulearrow
 	rulesp
 	rulerow
 )
@@ -58,11 +127,16 @@ const (
 var rul3s = [...]string{
 	"Unknown",
 	"e",
+	"definition",
+	"rule",
+	"assignment",
 	"e1",
 	"e2",
 	"e3",
 	"e4",
 	"value",
+	"call",
+	"name",
 	"variable",
 	"matrix",
 	"imaginary",
@@ -73,14 +147,74 @@ var rul3s = [...]string{
 	"exp2",
 	"natural",
 	"pi",
+	"infinity",
 	"prec",
+	"withprec",
 	"simplify",
+	"expand",
+	"collect",
 	"derivative",
+	"checkderivative",
+	"gradient",
+	"jacobian",
+	"hessian",
+	"integrate",
+	"solve",
+	"series",
+	"limit",
+	"side",
+	"eval",
+	"binding",
 	"log",
+	"log2",
+	"log10",
+	"exponent2",
 	"sqrt",
 	"cos",
 	"sin",
 	"tan",
+	"asin",
+	"acos",
+	"atan2",
+	"atan",
+	"sinh",
+	"cosh",
+	"tanh",
+	"asinh",
+	"acosh",
+	"atanh",
+	"sec",
+	"csc",
+	"cot",
+	"gamma",
+	"lgamma",
+	"beta",
+	"erf",
+	"erfc",
+	"zeta",
+	"digamma",
+	"polygamma",
+	"lambertw",
+	"besselj",
+	"bessely",
+	"besseli",
+	"besselk",
+	"airyaiprime",
+	"airybiprime",
+	"airyai",
+	"airybi",
+	"ellipk",
+	"ellipe",
+	"gcd",
+	"lcm",
+	"isprime",
+	"nextprime",
+	"factor",
+	"totient",
+	"powmod",
+	"invmod",
+	"crt",
+	"jacobi",
 	"sub",
 	"add",
 	"minus",
@@ -88,8 +222,12 @@ var rul3s = [...]string{
 	"divide",
 	"modulus",
 	"exponentiation",
+	"factorial",
 	"open",
 	"close",
+	"comma",
+	"equals",
+	"arrow",
 	"sp",
 	"row",
 }
@@ -204,9 +342,12 @@ func (t *tokens32) Tokens() []token32 {
 }
 
 type Calculator struct {
+	Prec uint
+	Env  *Environment
+
 	Buffer string
 	buffer []rune
-	rules  [36]func() bool
+	rules  [105]func() bool
 	parse  func(rule ...int) error
 	reset  func()
 	Pretty bool
@@ -386,7 +527,7 @@ func (p *Calculator) Init(options ...func(*Calculator) error) error {
 
 	_rules = [...]func() bool{
 		nil,
-		/* 0 e <- <(sp e1 !.)> */
+		/* 0 e <- <(sp (definition / rule / assignment / e1) !.)> */
 		func() bool {
 			position0, tokenIndex0 := position, tokenIndex
 			{
@@ -394,17 +535,39 @@ func (p *Calculator) Init(options ...func(*Calculator) error) error {
 				if !_rules[rulesp]() {
 					goto l0
 				}
-				if !_rules[rulee1]() {
-					goto l0
-				}
 				{
 					position2, tokenIndex2 := position, tokenIndex
+					if !_rules[ruledefinition]() {
+						goto l3
+					}
+					goto l2
+				l3:
+					position, tokenIndex = position2, tokenIndex2
+					if !_rules[rulerule]() {
+						goto l4
+					}
+					goto l2
+				l4:
+					position, tokenIndex = position2, tokenIndex2
+					if !_rules[ruleassignment]() {
+						goto l5
+					}
+					goto l2
+				l5:
+					position, tokenIndex = position2, tokenIndex2
+					if !_rules[rulee1]() {
+						goto l0
+					}
+				}
+			l2:
+				{
+					position6, tokenIndex6 := position, tokenIndex
 					if !matchDot() {
-						goto l2
+						goto l6
 					}
 					goto l0
-				l2:
-					position, tokenIndex = position2, tokenIndex2
+				l6:
+					position, tokenIndex = position6, tokenIndex6
 				}
 				add(rulee, position1)
 			}
@@ -413,1178 +576,4777 @@ func (p *Calculator) Init(options ...func(*Calculator) error) error {
 			position, tokenIndex = position0, tokenIndex0
 			return false
 		},
-		/* 1 e1 <- <(e2 ((add e2) / (minus e2))*)> */
+		/* 1 definition <- <(name open variable (comma variable)* close equals e1)> */
+		func() bool {
+			position7, tokenIndex7 := position, tokenIndex
+			{
+				position8 := position
+				if !_rules[rulename]() {
+					goto l7
+				}
+				if !_rules[ruleopen]() {
+					goto l7
+				}
+				if !_rules[rulevariable]() {
+					goto l7
+				}
+			l9:
+				{
+					position10, tokenIndex10 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l10
+					}
+					if !_rules[rulevariable]() {
+						goto l10
+					}
+					goto l9
+				l10:
+					position, tokenIndex = position10, tokenIndex10
+				}
+				if !_rules[ruleclose]() {
+					goto l7
+				}
+				if !_rules[ruleequals]() {
+					goto l7
+				}
+				if !_rules[rulee1]() {
+					goto l7
+				}
+				add(ruledefinition, position8)
+			}
+			return true
+		l7:
+			position, tokenIndex = position7, tokenIndex7
+			return false
+		},
+		/* 2 rule <- <(e1 arrow e1)> */
+		func() bool {
+			position11, tokenIndex11 := position, tokenIndex
+			{
+				position12 := position
+				if !_rules[rulee1]() {
+					goto l11
+				}
+				if !_rules[rulearrow]() {
+					goto l11
+				}
+				if !_rules[rulee1]() {
+					goto l11
+				}
+				add(rulerule, position12)
+			}
+			return true
+		l11:
+			position, tokenIndex = position11, tokenIndex11
+			return false
+		},
+		/* 3 assignment <- <(variable equals e1)> */
+		func() bool {
+			position13, tokenIndex13 := position, tokenIndex
+			{
+				position14 := position
+				if !_rules[rulevariable]() {
+					goto l13
+				}
+				if !_rules[ruleequals]() {
+					goto l13
+				}
+				if !_rules[rulee1]() {
+					goto l13
+				}
+				add(ruleassignment, position14)
+			}
+			return true
+		l13:
+			position, tokenIndex = position13, tokenIndex13
+			return false
+		},
+		/* 4 e1 <- <(e2 ((add e2) / (minus e2))*)> */
 		func() bool {
-			position3, tokenIndex3 := position, tokenIndex
+			position15, tokenIndex15 := position, tokenIndex
 			{
-				position4 := position
+				position16 := position
 				if !_rules[rulee2]() {
-					goto l3
+					goto l15
 				}
-			l5:
+			l17:
 				{
-					position6, tokenIndex6 := position, tokenIndex
+					position18, tokenIndex18 := position, tokenIndex
 					{
-						position7, tokenIndex7 := position, tokenIndex
+						position19, tokenIndex19 := position, tokenIndex
 						if !_rules[ruleadd]() {
-							goto l8
+							goto l20
 						}
 						if !_rules[rulee2]() {
-							goto l8
+							goto l20
 						}
-						goto l7
-					l8:
-						position, tokenIndex = position7, tokenIndex7
+						goto l19
+					l20:
+						position, tokenIndex = position19, tokenIndex19
 						if !_rules[ruleminus]() {
-							goto l6
+							goto l18
 						}
 						if !_rules[rulee2]() {
-							goto l6
+							goto l18
 						}
 					}
-				l7:
-					goto l5
-				l6:
-					position, tokenIndex = position6, tokenIndex6
+				l19:
+					goto l17
+				l18:
+					position, tokenIndex = position18, tokenIndex18
 				}
-				add(rulee1, position4)
+				add(rulee1, position16)
 			}
 			return true
-		l3:
-			position, tokenIndex = position3, tokenIndex3
+		l15:
+			position, tokenIndex = position15, tokenIndex15
 			return false
 		},
-		/* 2 e2 <- <(e3 ((multiply e3) / (divide e3) / (modulus e3))*)> */
+		/* 5 e2 <- <(e3 ((multiply e3) / (divide e3) / (modulus e3))*)> */
 		func() bool {
-			position9, tokenIndex9 := position, tokenIndex
+			position21, tokenIndex21 := position, tokenIndex
 			{
-				position10 := position
+				position22 := position
 				if !_rules[rulee3]() {
-					goto l9
+					goto l21
 				}
-			l11:
+			l23:
 				{
-					position12, tokenIndex12 := position, tokenIndex
+					position24, tokenIndex24 := position, tokenIndex
 					{
-						position13, tokenIndex13 := position, tokenIndex
+						position25, tokenIndex25 := position, tokenIndex
 						if !_rules[rulemultiply]() {
-							goto l14
+							goto l26
 						}
 						if !_rules[rulee3]() {
-							goto l14
+							goto l26
 						}
-						goto l13
-					l14:
-						position, tokenIndex = position13, tokenIndex13
+						goto l25
+					l26:
+						position, tokenIndex = position25, tokenIndex25
 						if !_rules[ruledivide]() {
-							goto l15
+							goto l27
 						}
 						if !_rules[rulee3]() {
-							goto l15
+							goto l27
 						}
-						goto l13
-					l15:
-						position, tokenIndex = position13, tokenIndex13
+						goto l25
+					l27:
+						position, tokenIndex = position25, tokenIndex25
 						if !_rules[rulemodulus]() {
-							goto l12
+							goto l24
 						}
 						if !_rules[rulee3]() {
-							goto l12
+							goto l24
 						}
 					}
-				l13:
-					goto l11
-				l12:
-					position, tokenIndex = position12, tokenIndex12
+				l25:
+					goto l23
+				l24:
+					position, tokenIndex = position24, tokenIndex24
 				}
-				add(rulee2, position10)
+				add(rulee2, position22)
 			}
 			return true
-		l9:
-			position, tokenIndex = position9, tokenIndex9
+		l21:
+			position, tokenIndex = position21, tokenIndex21
 			return false
 		},
-		/* 3 e3 <- <(e4 (exponentiation e4)*)> */
+		/* 6 e3 <- <(e4 (exponentiation e4)*)> */
 		func() bool {
-			position16, tokenIndex16 := position, tokenIndex
+			position28, tokenIndex28 := position, tokenIndex
 			{
-				position17 := position
+				position29 := position
 				if !_rules[rulee4]() {
-					goto l16
+					goto l28
 				}
-			l18:
+			l30:
 				{
-					position19, tokenIndex19 := position, tokenIndex
+					position31, tokenIndex31 := position, tokenIndex
 					if !_rules[ruleexponentiation]() {
-						goto l19
+						goto l31
 					}
 					if !_rules[rulee4]() {
-						goto l19
+						goto l31
 					}
-					goto l18
-				l19:
-					position, tokenIndex = position19, tokenIndex19
+					goto l30
+				l31:
+					position, tokenIndex = position31, tokenIndex31
 				}
-				add(rulee3, position17)
+				add(rulee3, position29)
 			}
 			return true
-		l16:
-			position, tokenIndex = position16, tokenIndex16
+		l28:
+			position, tokenIndex = position28, tokenIndex28
 			return false
 		},
-		/* 4 e4 <- <((minus value) / value)> */
+		/* 7 e4 <- <((minus value factorial*) / (value factorial*))> */
 		func() bool {
-			position20, tokenIndex20 := position, tokenIndex
+			position32, tokenIndex32 := position, tokenIndex
 			{
-				position21 := position
+				position33 := position
 				{
-					position22, tokenIndex22 := position, tokenIndex
+					position34, tokenIndex34 := position, tokenIndex
 					if !_rules[ruleminus]() {
-						goto l23
+						goto l35
 					}
 					if !_rules[rulevalue]() {
-						goto l23
+						goto l35
+					}
+				l36:
+					{
+						position37, tokenIndex37 := position, tokenIndex
+						if !_rules[rulefactorial]() {
+							goto l37
+						}
+						goto l36
+					l37:
+						position, tokenIndex = position37, tokenIndex37
 					}
-					goto l22
-				l23:
-					position, tokenIndex = position22, tokenIndex22
+					goto l34
+				l35:
+					position, tokenIndex = position34, tokenIndex34
 					if !_rules[rulevalue]() {
-						goto l20
+						goto l32
+					}
+				l38:
+					{
+						position39, tokenIndex39 := position, tokenIndex
+						if !_rules[rulefactorial]() {
+							goto l39
+						}
+						goto l38
+					l39:
+						position, tokenIndex = position39, tokenIndex39
 					}
 				}
-			l22:
-				add(rulee4, position21)
+			l34:
+				add(rulee4, position33)
 			}
 			return true
-		l20:
-			position, tokenIndex = position20, tokenIndex20
+		l32:
+			position, tokenIndex = position32, tokenIndex32
 			return false
 		},
-		/* 5 value <- <(matrix / imaginary / number / exp1 / exp2 / natural / pi / prec / simplify / derivative / log / sqrt / cos / sin / tan / variable / sub)> */
+		/* 8 value <- <(matrix / imaginary / number / exp1 / exp2 / natural / pi / infinity / prec / withprec / simplify / expand / collect / derivative / checkderivative / gradient / jacobian / hessian / integrate / solve / series / limit / eval / log / log2 / log10 / exponent2 / sqrt / cos / sin / tan / asin / acos / atan2 / atan / sinh / cosh / tanh / asinh / acosh / atanh / sec / csc / cot / gamma / lgamma / beta / erf / erfc / zeta / digamma / polygamma / lambertw / besselj / bessely / besseli / besselk / airyaiprime / airybiprime / airyai / airybi / ellipk / ellipe / gcd / lcm / isprime / nextprime / factor / totient / powmod / invmod / crt / jacobi / call / variable / sub)> */
 		func() bool {
-			position24, tokenIndex24 := position, tokenIndex
+			position40, tokenIndex40 := position, tokenIndex
 			{
-				position25 := position
+				position41 := position
 				{
-					position26, tokenIndex26 := position, tokenIndex
+					position42, tokenIndex42 := position, tokenIndex
 					if !_rules[rulematrix]() {
-						goto l27
+						goto l43
 					}
-					goto l26
-				l27:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l43:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[ruleimaginary]() {
-						goto l28
+						goto l44
 					}
-					goto l26
-				l28:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l44:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulenumber]() {
-						goto l29
+						goto l45
 					}
-					goto l26
-				l29:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l45:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[ruleexp1]() {
-						goto l30
+						goto l46
 					}
-					goto l26
-				l30:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l46:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[ruleexp2]() {
-						goto l31
+						goto l47
 					}
-					goto l26
-				l31:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l47:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulenatural]() {
-						goto l32
+						goto l48
 					}
-					goto l26
-				l32:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l48:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulepi]() {
-						goto l33
+						goto l49
+					}
+					goto l42
+				l49:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleinfinity]() {
+						goto l50
 					}
-					goto l26
-				l33:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l50:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[ruleprec]() {
-						goto l34
+						goto l51
+					}
+					goto l42
+				l51:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulewithprec]() {
+						goto l52
 					}
-					goto l26
-				l34:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l52:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulesimplify]() {
-						goto l35
+						goto l53
 					}
-					goto l26
-				l35:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l53:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleexpand]() {
+						goto l54
+					}
+					goto l42
+				l54:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecollect]() {
+						goto l55
+					}
+					goto l42
+				l55:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulederivative]() {
-						goto l36
+						goto l56
 					}
-					goto l26
-				l36:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l56:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecheckderivative]() {
+						goto l57
+					}
+					goto l42
+				l57:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulegradient]() {
+						goto l58
+					}
+					goto l42
+				l58:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulejacobian]() {
+						goto l59
+					}
+					goto l42
+				l59:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulehessian]() {
+						goto l60
+					}
+					goto l42
+				l60:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleintegrate]() {
+						goto l61
+					}
+					goto l42
+				l61:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulesolve]() {
+						goto l62
+					}
+					goto l42
+				l62:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleseries]() {
+						goto l63
+					}
+					goto l42
+				l63:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelimit]() {
+						goto l64
+					}
+					goto l42
+				l64:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleeval]() {
+						goto l65
+					}
+					goto l42
+				l65:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulelog]() {
-						goto l37
+						goto l66
+					}
+					goto l42
+				l66:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelog2]() {
+						goto l67
+					}
+					goto l42
+				l67:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelog10]() {
+						goto l68
 					}
-					goto l26
-				l37:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l68:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleexponent2]() {
+						goto l69
+					}
+					goto l42
+				l69:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulesqrt]() {
-						goto l38
+						goto l70
 					}
-					goto l26
-				l38:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l70:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulecos]() {
-						goto l39
+						goto l71
 					}
-					goto l26
-				l39:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l71:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulesin]() {
-						goto l40
+						goto l72
 					}
-					goto l26
-				l40:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l72:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[ruletan]() {
-						goto l41
+						goto l73
+					}
+					goto l42
+				l73:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleasin]() {
+						goto l74
+					}
+					goto l42
+				l74:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleacos]() {
+						goto l75
+					}
+					goto l42
+				l75:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleatan2]() {
+						goto l76
+					}
+					goto l42
+				l76:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleatan]() {
+						goto l77
+					}
+					goto l42
+				l77:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulesinh]() {
+						goto l78
+					}
+					goto l42
+				l78:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecosh]() {
+						goto l79
+					}
+					goto l42
+				l79:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruletanh]() {
+						goto l80
+					}
+					goto l42
+				l80:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleasinh]() {
+						goto l81
+					}
+					goto l42
+				l81:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleacosh]() {
+						goto l82
+					}
+					goto l42
+				l82:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleatanh]() {
+						goto l83
+					}
+					goto l42
+				l83:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulesec]() {
+						goto l84
+					}
+					goto l42
+				l84:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecsc]() {
+						goto l85
+					}
+					goto l42
+				l85:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecot]() {
+						goto l86
+					}
+					goto l42
+				l86:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulegamma]() {
+						goto l87
+					}
+					goto l42
+				l87:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelgamma]() {
+						goto l88
+					}
+					goto l42
+				l88:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulebeta]() {
+						goto l89
 					}
-					goto l26
-				l41:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l89:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleerf]() {
+						goto l90
+					}
+					goto l42
+				l90:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleerfc]() {
+						goto l91
+					}
+					goto l42
+				l91:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulezeta]() {
+						goto l92
+					}
+					goto l42
+				l92:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruledigamma]() {
+						goto l93
+					}
+					goto l42
+				l93:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulepolygamma]() {
+						goto l94
+					}
+					goto l42
+				l94:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelambertw]() {
+						goto l95
+					}
+					goto l42
+				l95:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulebesselj]() {
+						goto l96
+					}
+					goto l42
+				l96:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulebessely]() {
+						goto l97
+					}
+					goto l42
+				l97:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulebesseli]() {
+						goto l98
+					}
+					goto l42
+				l98:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulebesselk]() {
+						goto l99
+					}
+					goto l42
+				l99:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleairyaiprime]() {
+						goto l100
+					}
+					goto l42
+				l100:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleairybiprime]() {
+						goto l101
+					}
+					goto l42
+				l101:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleairyai]() {
+						goto l102
+					}
+					goto l42
+				l102:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleairybi]() {
+						goto l103
+					}
+					goto l42
+				l103:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleellipk]() {
+						goto l104
+					}
+					goto l42
+				l104:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleellipe]() {
+						goto l105
+					}
+					goto l42
+				l105:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulegcd]() {
+						goto l106
+					}
+					goto l42
+				l106:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulelcm]() {
+						goto l107
+					}
+					goto l42
+				l107:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleisprime]() {
+						goto l108
+					}
+					goto l42
+				l108:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulenextprime]() {
+						goto l109
+					}
+					goto l42
+				l109:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulefactor]() {
+						goto l110
+					}
+					goto l42
+				l110:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruletotient]() {
+						goto l111
+					}
+					goto l42
+				l111:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulepowmod]() {
+						goto l112
+					}
+					goto l42
+				l112:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[ruleinvmod]() {
+						goto l113
+					}
+					goto l42
+				l113:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecrt]() {
+						goto l114
+					}
+					goto l42
+				l114:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulejacobi]() {
+						goto l115
+					}
+					goto l42
+				l115:
+					position, tokenIndex = position42, tokenIndex42
+					if !_rules[rulecall]() {
+						goto l116
+					}
+					goto l42
+				l116:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulevariable]() {
-						goto l42
+						goto l117
 					}
-					goto l26
-				l42:
-					position, tokenIndex = position26, tokenIndex26
+					goto l42
+				l117:
+					position, tokenIndex = position42, tokenIndex42
 					if !_rules[rulesub]() {
-						goto l24
+						goto l40
+					}
+				}
+			l42:
+				add(rulevalue, position41)
+			}
+			return true
+		l40:
+			position, tokenIndex = position40, tokenIndex40
+			return false
+		},
+		/* 9 call <- <(name open e1 (comma e1)* close)> */
+		func() bool {
+			position118, tokenIndex118 := position, tokenIndex
+			{
+				position119 := position
+				if !_rules[rulename]() {
+					goto l118
+				}
+				if !_rules[ruleopen]() {
+					goto l118
+				}
+				if !_rules[rulee1]() {
+					goto l118
+				}
+			l120:
+				{
+					position121, tokenIndex121 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l121
+					}
+					if !_rules[rulee1]() {
+						goto l121
 					}
+					goto l120
+				l121:
+					position, tokenIndex = position121, tokenIndex121
 				}
-			l26:
-				add(rulevalue, position25)
+				if !_rules[ruleclose]() {
+					goto l118
+				}
+				add(rulecall, position119)
 			}
 			return true
-		l24:
-			position, tokenIndex = position24, tokenIndex24
+		l118:
+			position, tokenIndex = position118, tokenIndex118
 			return false
 		},
-		/* 6 variable <- <(([A-Z] / [a-z])+ sp)> */
+		/* 10 name <- <(([A-Z] / [a-z])+ sp)> */
 		func() bool {
-			position43, tokenIndex43 := position, tokenIndex
+			position122, tokenIndex122 := position, tokenIndex
 			{
-				position44 := position
+				position123 := position
 				{
-					position47, tokenIndex47 := position, tokenIndex
+					position126, tokenIndex126 := position, tokenIndex
 					if c := buffer[position]; c < rune('A') || c > rune('Z') {
-						goto l48
+						goto l127
 					}
 					position++
-					goto l47
-				l48:
-					position, tokenIndex = position47, tokenIndex47
+					goto l126
+				l127:
+					position, tokenIndex = position126, tokenIndex126
 					if c := buffer[position]; c < rune('a') || c > rune('z') {
-						goto l43
+						goto l122
 					}
 					position++
 				}
-			l47:
-			l45:
+			l126:
+			l124:
 				{
-					position46, tokenIndex46 := position, tokenIndex
+					position125, tokenIndex125 := position, tokenIndex
 					{
-						position49, tokenIndex49 := position, tokenIndex
+						position128, tokenIndex128 := position, tokenIndex
 						if c := buffer[position]; c < rune('A') || c > rune('Z') {
-							goto l50
+							goto l129
 						}
 						position++
-						goto l49
-					l50:
-						position, tokenIndex = position49, tokenIndex49
+						goto l128
+					l129:
+						position, tokenIndex = position128, tokenIndex128
 						if c := buffer[position]; c < rune('a') || c > rune('z') {
-							goto l46
+							goto l125
 						}
 						position++
 					}
-				l49:
-					goto l45
-				l46:
-					position, tokenIndex = position46, tokenIndex46
+				l128:
+					goto l124
+				l125:
+					position, tokenIndex = position125, tokenIndex125
+				}
+				if !_rules[rulesp]() {
+					goto l122
+				}
+				add(rulename, position123)
+			}
+			return true
+		l122:
+			position, tokenIndex = position122, tokenIndex122
+			return false
+		},
+		/* 11 variable <- <(([A-Z] / [a-z])+ sp)> */
+		func() bool {
+			position130, tokenIndex130 := position, tokenIndex
+			{
+				position131 := position
+				{
+					position134, tokenIndex134 := position, tokenIndex
+					if c := buffer[position]; c < rune('A') || c > rune('Z') {
+						goto l135
+					}
+					position++
+					goto l134
+				l135:
+					position, tokenIndex = position134, tokenIndex134
+					if c := buffer[position]; c < rune('a') || c > rune('z') {
+						goto l130
+					}
+					position++
+				}
+			l134:
+			l132:
+				{
+					position133, tokenIndex133 := position, tokenIndex
+					{
+						position136, tokenIndex136 := position, tokenIndex
+						if c := buffer[position]; c < rune('A') || c > rune('Z') {
+							goto l137
+						}
+						position++
+						goto l136
+					l137:
+						position, tokenIndex = position136, tokenIndex136
+						if c := buffer[position]; c < rune('a') || c > rune('z') {
+							goto l133
+						}
+						position++
+					}
+				l136:
+					goto l132
+				l133:
+					position, tokenIndex = position133, tokenIndex133
 				}
 				if !_rules[rulesp]() {
-					goto l43
+					goto l130
 				}
-				add(rulevariable, position44)
+				add(rulevariable, position131)
 			}
 			return true
-		l43:
-			position, tokenIndex = position43, tokenIndex43
+		l130:
+			position, tokenIndex = position130, tokenIndex130
 			return false
 		},
-		/* 7 matrix <- <('[' sp (e1 / row)+ ']' sp)> */
+		/* 12 matrix <- <('[' sp (e1 / row)+ ']' sp)> */
 		func() bool {
-			position51, tokenIndex51 := position, tokenIndex
+			position138, tokenIndex138 := position, tokenIndex
 			{
-				position52 := position
+				position139 := position
 				if buffer[position] != rune('[') {
-					goto l51
+					goto l138
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l51
+					goto l138
 				}
 				{
-					position55, tokenIndex55 := position, tokenIndex
+					position142, tokenIndex142 := position, tokenIndex
 					if !_rules[rulee1]() {
-						goto l56
+						goto l143
 					}
-					goto l55
-				l56:
-					position, tokenIndex = position55, tokenIndex55
+					goto l142
+				l143:
+					position, tokenIndex = position142, tokenIndex142
 					if !_rules[rulerow]() {
-						goto l51
+						goto l138
 					}
 				}
-			l55:
-			l53:
+			l142:
+			l140:
 				{
-					position54, tokenIndex54 := position, tokenIndex
+					position141, tokenIndex141 := position, tokenIndex
 					{
-						position57, tokenIndex57 := position, tokenIndex
+						position144, tokenIndex144 := position, tokenIndex
 						if !_rules[rulee1]() {
-							goto l58
+							goto l145
 						}
-						goto l57
-					l58:
-						position, tokenIndex = position57, tokenIndex57
+						goto l144
+					l145:
+						position, tokenIndex = position144, tokenIndex144
 						if !_rules[rulerow]() {
-							goto l54
+							goto l141
 						}
 					}
-				l57:
-					goto l53
-				l54:
-					position, tokenIndex = position54, tokenIndex54
+				l144:
+					goto l140
+				l141:
+					position, tokenIndex = position141, tokenIndex141
 				}
 				if buffer[position] != rune(']') {
-					goto l51
+					goto l138
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l51
+					goto l138
 				}
-				add(rulematrix, position52)
+				add(rulematrix, position139)
 			}
 			return true
-		l51:
-			position, tokenIndex = position51, tokenIndex51
+		l138:
+			position, tokenIndex = position138, tokenIndex138
 			return false
 		},
-		/* 8 imaginary <- <(decimal notation? 'i' sp)> */
+		/* 13 imaginary <- <(decimal notation? 'i' sp)> */
 		func() bool {
-			position59, tokenIndex59 := position, tokenIndex
+			position146, tokenIndex146 := position, tokenIndex
 			{
-				position60 := position
+				position147 := position
 				if !_rules[ruledecimal]() {
-					goto l59
+					goto l146
 				}
 				{
-					position61, tokenIndex61 := position, tokenIndex
+					position148, tokenIndex148 := position, tokenIndex
 					if !_rules[rulenotation]() {
-						goto l61
+						goto l148
 					}
-					goto l62
-				l61:
-					position, tokenIndex = position61, tokenIndex61
+					goto l149
+				l148:
+					position, tokenIndex = position148, tokenIndex148
 				}
-			l62:
+			l149:
 				if buffer[position] != rune('i') {
-					goto l59
+					goto l146
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l59
+					goto l146
 				}
-				add(ruleimaginary, position60)
+				add(ruleimaginary, position147)
 			}
 			return true
-		l59:
-			position, tokenIndex = position59, tokenIndex59
+		l146:
+			position, tokenIndex = position146, tokenIndex146
 			return false
 		},
-		/* 9 number <- <(decimal notation? sp)> */
+		/* 14 number <- <(decimal notation? sp)> */
 		func() bool {
-			position63, tokenIndex63 := position, tokenIndex
+			position150, tokenIndex150 := position, tokenIndex
 			{
-				position64 := position
+				position151 := position
 				if !_rules[ruledecimal]() {
-					goto l63
+					goto l150
 				}
 				{
-					position65, tokenIndex65 := position, tokenIndex
+					position152, tokenIndex152 := position, tokenIndex
 					if !_rules[rulenotation]() {
-						goto l65
+						goto l152
 					}
-					goto l66
-				l65:
-					position, tokenIndex = position65, tokenIndex65
+					goto l153
+				l152:
+					position, tokenIndex = position152, tokenIndex152
 				}
-			l66:
+			l153:
 				if !_rules[rulesp]() {
-					goto l63
+					goto l150
 				}
-				add(rulenumber, position64)
+				add(rulenumber, position151)
 			}
 			return true
-		l63:
-			position, tokenIndex = position63, tokenIndex63
+		l150:
+			position, tokenIndex = position150, tokenIndex150
 			return false
 		},
-		/* 10 decimal <- <(('-' / '+')? [0-9]+ ('.' [0-9]*)?)> */
+		/* 15 decimal <- <(('-' / '+')? [0-9]+ ('.' [0-9]*)?)> */
 		func() bool {
-			position67, tokenIndex67 := position, tokenIndex
+			position154, tokenIndex154 := position, tokenIndex
 			{
-				position68 := position
+				position155 := position
 				{
-					position69, tokenIndex69 := position, tokenIndex
+					position156, tokenIndex156 := position, tokenIndex
 					{
-						position71, tokenIndex71 := position, tokenIndex
+						position158, tokenIndex158 := position, tokenIndex
 						if buffer[position] != rune('-') {
-							goto l72
+							goto l159
 						}
 						position++
-						goto l71
-					l72:
-						position, tokenIndex = position71, tokenIndex71
+						goto l158
+					l159:
+						position, tokenIndex = position158, tokenIndex158
 						if buffer[position] != rune('+') {
-							goto l69
+							goto l156
 						}
 						position++
 					}
-				l71:
-					goto l70
-				l69:
-					position, tokenIndex = position69, tokenIndex69
+				l158:
+					goto l157
+				l156:
+					position, tokenIndex = position156, tokenIndex156
 				}
-			l70:
+			l157:
 				if c := buffer[position]; c < rune('0') || c > rune('9') {
-					goto l67
+					goto l154
 				}
 				position++
-			l73:
+			l160:
 				{
-					position74, tokenIndex74 := position, tokenIndex
+					position161, tokenIndex161 := position, tokenIndex
 					if c := buffer[position]; c < rune('0') || c > rune('9') {
-						goto l74
+						goto l161
 					}
 					position++
-					goto l73
-				l74:
-					position, tokenIndex = position74, tokenIndex74
+					goto l160
+				l161:
+					position, tokenIndex = position161, tokenIndex161
 				}
 				{
-					position75, tokenIndex75 := position, tokenIndex
+					position162, tokenIndex162 := position, tokenIndex
 					if buffer[position] != rune('.') {
-						goto l75
+						goto l162
 					}
 					position++
-				l77:
+				l164:
 					{
-						position78, tokenIndex78 := position, tokenIndex
+						position165, tokenIndex165 := position, tokenIndex
 						if c := buffer[position]; c < rune('0') || c > rune('9') {
-							goto l78
+							goto l165
 						}
 						position++
-						goto l77
-					l78:
-						position, tokenIndex = position78, tokenIndex78
+						goto l164
+					l165:
+						position, tokenIndex = position165, tokenIndex165
 					}
-					goto l76
-				l75:
-					position, tokenIndex = position75, tokenIndex75
+					goto l163
+				l162:
+					position, tokenIndex = position162, tokenIndex162
 				}
-			l76:
-				add(ruledecimal, position68)
+			l163:
+				add(ruledecimal, position155)
 			}
 			return true
-		l67:
-			position, tokenIndex = position67, tokenIndex67
+		l154:
+			position, tokenIndex = position154, tokenIndex154
 			return false
 		},
-		/* 11 notation <- <(('e' / 'E') decimal)> */
+		/* 16 notation <- <(('e' / 'E') decimal)> */
 		func() bool {
-			position79, tokenIndex79 := position, tokenIndex
+			position166, tokenIndex166 := position, tokenIndex
 			{
-				position80 := position
+				position167 := position
 				{
-					position81, tokenIndex81 := position, tokenIndex
+					position168, tokenIndex168 := position, tokenIndex
 					if buffer[position] != rune('e') {
-						goto l82
+						goto l169
 					}
 					position++
-					goto l81
-				l82:
-					position, tokenIndex = position81, tokenIndex81
+					goto l168
+				l169:
+					position, tokenIndex = position168, tokenIndex168
 					if buffer[position] != rune('E') {
-						goto l79
+						goto l166
 					}
 					position++
 				}
-			l81:
-				if !_rules[ruledecimal]() {
-					goto l79
+			l168:
+				if !_rules[ruledecimal]() {
+					goto l166
+				}
+				add(rulenotation, position167)
+			}
+			return true
+		l166:
+			position, tokenIndex = position166, tokenIndex166
+			return false
+		},
+		/* 17 exp1 <- <('e' 'x' 'p' open e1 close)> */
+		func() bool {
+			position170, tokenIndex170 := position, tokenIndex
+			{
+				position171 := position
+				if buffer[position] != rune('e') {
+					goto l170
+				}
+				position++
+				if buffer[position] != rune('x') {
+					goto l170
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l170
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l170
+				}
+				if !_rules[rulee1]() {
+					goto l170
+				}
+				if !_rules[ruleclose]() {
+					goto l170
+				}
+				add(ruleexp1, position171)
+			}
+			return true
+		l170:
+			position, tokenIndex = position170, tokenIndex170
+			return false
+		},
+		/* 18 exp2 <- <('e' '^' value)> */
+		func() bool {
+			position172, tokenIndex172 := position, tokenIndex
+			{
+				position173 := position
+				if buffer[position] != rune('e') {
+					goto l172
+				}
+				position++
+				if buffer[position] != rune('^') {
+					goto l172
+				}
+				position++
+				if !_rules[rulevalue]() {
+					goto l172
+				}
+				add(ruleexp2, position173)
+			}
+			return true
+		l172:
+			position, tokenIndex = position172, tokenIndex172
+			return false
+		},
+		/* 19 natural <- <('e' !([A-Z] / [a-z]) sp)> */
+		func() bool {
+			position174, tokenIndex174 := position, tokenIndex
+			{
+				position175 := position
+				if buffer[position] != rune('e') {
+					goto l174
+				}
+				position++
+				{
+					position176, tokenIndex176 := position, tokenIndex
+					{
+						position177, tokenIndex177 := position, tokenIndex
+						if c := buffer[position]; c < rune('A') || c > rune('Z') {
+							goto l178
+						}
+						position++
+						goto l177
+					l178:
+						position, tokenIndex = position177, tokenIndex177
+						if c := buffer[position]; c < rune('a') || c > rune('z') {
+							goto l176
+						}
+						position++
+					}
+				l177:
+					goto l174
+				l176:
+					position, tokenIndex = position176, tokenIndex176
+				}
+				if !_rules[rulesp]() {
+					goto l174
+				}
+				add(rulenatural, position175)
+			}
+			return true
+		l174:
+			position, tokenIndex = position174, tokenIndex174
+			return false
+		},
+		/* 20 pi <- <('p' 'i' !([A-Z] / [a-z]) sp)> */
+		func() bool {
+			position179, tokenIndex179 := position, tokenIndex
+			{
+				position180 := position
+				if buffer[position] != rune('p') {
+					goto l179
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l179
+				}
+				position++
+				{
+					position181, tokenIndex181 := position, tokenIndex
+					{
+						position182, tokenIndex182 := position, tokenIndex
+						if c := buffer[position]; c < rune('A') || c > rune('Z') {
+							goto l183
+						}
+						position++
+						goto l182
+					l183:
+						position, tokenIndex = position182, tokenIndex182
+						if c := buffer[position]; c < rune('a') || c > rune('z') {
+							goto l181
+						}
+						position++
+					}
+				l182:
+					goto l179
+				l181:
+					position, tokenIndex = position181, tokenIndex181
+				}
+				if !_rules[rulesp]() {
+					goto l179
+				}
+				add(rulepi, position180)
+			}
+			return true
+		l179:
+			position, tokenIndex = position179, tokenIndex179
+			return false
+		},
+		/* 21 infinity <- <('i' 'n' 'f' !([A-Z] / [a-z]) sp)> */
+		func() bool {
+			position184, tokenIndex184 := position, tokenIndex
+			{
+				position185 := position
+				if buffer[position] != rune('i') {
+					goto l184
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l184
+				}
+				position++
+				if buffer[position] != rune('f') {
+					goto l184
+				}
+				position++
+				{
+					position186, tokenIndex186 := position, tokenIndex
+					{
+						position187, tokenIndex187 := position, tokenIndex
+						if c := buffer[position]; c < rune('A') || c > rune('Z') {
+							goto l188
+						}
+						position++
+						goto l187
+					l188:
+						position, tokenIndex = position187, tokenIndex187
+						if c := buffer[position]; c < rune('a') || c > rune('z') {
+							goto l186
+						}
+						position++
+					}
+				l187:
+					goto l184
+				l186:
+					position, tokenIndex = position186, tokenIndex186
+				}
+				if !_rules[rulesp]() {
+					goto l184
+				}
+				add(ruleinfinity, position185)
+			}
+			return true
+		l184:
+			position, tokenIndex = position184, tokenIndex184
+			return false
+		},
+		/* 22 prec <- <('p' 'r' 'e' 'c' open e1 close)> */
+		func() bool {
+			position189, tokenIndex189 := position, tokenIndex
+			{
+				position190 := position
+				if buffer[position] != rune('p') {
+					goto l189
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l189
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l189
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l189
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l189
+				}
+				if !_rules[rulee1]() {
+					goto l189
+				}
+				if !_rules[ruleclose]() {
+					goto l189
+				}
+				add(ruleprec, position190)
+			}
+			return true
+		l189:
+			position, tokenIndex = position189, tokenIndex189
+			return false
+		},
+		/* 23 withprec <- <('w' 'i' 't' 'h' 'p' 'r' 'e' 'c' open e1 comma e1 close)> */
+		func() bool {
+			position191, tokenIndex191 := position, tokenIndex
+			{
+				position192 := position
+				if buffer[position] != rune('w') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l191
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l191
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l191
+				}
+				if !_rules[rulee1]() {
+					goto l191
+				}
+				if !_rules[rulecomma]() {
+					goto l191
+				}
+				if !_rules[rulee1]() {
+					goto l191
+				}
+				if !_rules[ruleclose]() {
+					goto l191
+				}
+				add(rulewithprec, position192)
+			}
+			return true
+		l191:
+			position, tokenIndex = position191, tokenIndex191
+			return false
+		},
+		/* 24 simplify <- <('s' 'i' 'm' 'p' 'l' 'i' 'f' 'y' open e1 close)> */
+		func() bool {
+			position193, tokenIndex193 := position, tokenIndex
+			{
+				position194 := position
+				if buffer[position] != rune('s') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('f') {
+					goto l193
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l193
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l193
+				}
+				if !_rules[rulee1]() {
+					goto l193
+				}
+				if !_rules[ruleclose]() {
+					goto l193
+				}
+				add(rulesimplify, position194)
+			}
+			return true
+		l193:
+			position, tokenIndex = position193, tokenIndex193
+			return false
+		},
+		/* 25 expand <- <('e' 'x' 'p' 'a' 'n' 'd' open e1 close)> */
+		func() bool {
+			position195, tokenIndex195 := position, tokenIndex
+			{
+				position196 := position
+				if buffer[position] != rune('e') {
+					goto l195
+				}
+				position++
+				if buffer[position] != rune('x') {
+					goto l195
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l195
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l195
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l195
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l195
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l195
+				}
+				if !_rules[rulee1]() {
+					goto l195
+				}
+				if !_rules[ruleclose]() {
+					goto l195
+				}
+				add(ruleexpand, position196)
+			}
+			return true
+		l195:
+			position, tokenIndex = position195, tokenIndex195
+			return false
+		},
+		/* 26 collect <- <('c' 'o' 'l' 'l' 'e' 'c' 't' open e1 comma variable close)> */
+		func() bool {
+			position197, tokenIndex197 := position, tokenIndex
+			{
+				position198 := position
+				if buffer[position] != rune('c') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l197
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l197
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l197
+				}
+				if !_rules[rulee1]() {
+					goto l197
+				}
+				if !_rules[rulecomma]() {
+					goto l197
+				}
+				if !_rules[rulevariable]() {
+					goto l197
+				}
+				if !_rules[ruleclose]() {
+					goto l197
+				}
+				add(rulecollect, position198)
+			}
+			return true
+		l197:
+			position, tokenIndex = position197, tokenIndex197
+			return false
+		},
+		/* 27 derivative <- <('d' 'e' 'r' 'i' 'v' 'a' 't' 'i' 'v' 'e' open e1 (comma variable (comma e1)?)? close)> */
+		func() bool {
+			position199, tokenIndex199 := position, tokenIndex
+			{
+				position200 := position
+				if buffer[position] != rune('d') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l199
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l199
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l199
+				}
+				if !_rules[rulee1]() {
+					goto l199
+				}
+				{
+					position201, tokenIndex201 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l201
+					}
+					if !_rules[rulevariable]() {
+						goto l201
+					}
+					{
+						position203, tokenIndex203 := position, tokenIndex
+						if !_rules[rulecomma]() {
+							goto l203
+						}
+						if !_rules[rulee1]() {
+							goto l203
+						}
+						goto l204
+					l203:
+						position, tokenIndex = position203, tokenIndex203
+					}
+				l204:
+					goto l202
+				l201:
+					position, tokenIndex = position201, tokenIndex201
+				}
+			l202:
+				if !_rules[ruleclose]() {
+					goto l199
+				}
+				add(rulederivative, position200)
+			}
+			return true
+		l199:
+			position, tokenIndex = position199, tokenIndex199
+			return false
+		},
+		/* 28 checkderivative <- <('c' 'h' 'e' 'c' 'k' 'd' 'e' 'r' 'i' 'v' 'a' 't' 'i' 'v' 'e' open e1 comma variable comma e1 close)> */
+		func() bool {
+			position205, tokenIndex205 := position, tokenIndex
+			{
+				position206 := position
+				if buffer[position] != rune('c') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('k') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l205
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l205
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l205
+				}
+				if !_rules[rulee1]() {
+					goto l205
+				}
+				if !_rules[rulecomma]() {
+					goto l205
+				}
+				if !_rules[rulevariable]() {
+					goto l205
+				}
+				if !_rules[rulecomma]() {
+					goto l205
+				}
+				if !_rules[rulee1]() {
+					goto l205
+				}
+				if !_rules[ruleclose]() {
+					goto l205
+				}
+				add(rulecheckderivative, position206)
+			}
+			return true
+		l205:
+			position, tokenIndex = position205, tokenIndex205
+			return false
+		},
+		/* 29 gradient <- <('g' 'r' 'a' 'd' 'i' 'e' 'n' 't' open e1 comma e1 close)> */
+		func() bool {
+			position207, tokenIndex207 := position, tokenIndex
+			{
+				position208 := position
+				if buffer[position] != rune('g') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l207
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l207
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l207
+				}
+				if !_rules[rulee1]() {
+					goto l207
+				}
+				if !_rules[rulecomma]() {
+					goto l207
+				}
+				if !_rules[rulee1]() {
+					goto l207
+				}
+				if !_rules[ruleclose]() {
+					goto l207
+				}
+				add(rulegradient, position208)
+			}
+			return true
+		l207:
+			position, tokenIndex = position207, tokenIndex207
+			return false
+		},
+		/* 30 jacobian <- <('j' 'a' 'c' 'o' 'b' 'i' 'a' 'n' open e1 comma e1 close)> */
+		func() bool {
+			position209, tokenIndex209 := position, tokenIndex
+			{
+				position210 := position
+				if buffer[position] != rune('j') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('b') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l209
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l209
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l209
+				}
+				if !_rules[rulee1]() {
+					goto l209
+				}
+				if !_rules[rulecomma]() {
+					goto l209
+				}
+				if !_rules[rulee1]() {
+					goto l209
+				}
+				if !_rules[ruleclose]() {
+					goto l209
+				}
+				add(rulejacobian, position210)
+			}
+			return true
+		l209:
+			position, tokenIndex = position209, tokenIndex209
+			return false
+		},
+		/* 31 hessian <- <('h' 'e' 's' 's' 'i' 'a' 'n' open e1 comma e1 close)> */
+		func() bool {
+			position211, tokenIndex211 := position, tokenIndex
+			{
+				position212 := position
+				if buffer[position] != rune('h') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l211
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l211
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l211
+				}
+				if !_rules[rulee1]() {
+					goto l211
+				}
+				if !_rules[rulecomma]() {
+					goto l211
+				}
+				if !_rules[rulee1]() {
+					goto l211
+				}
+				if !_rules[ruleclose]() {
+					goto l211
+				}
+				add(rulehessian, position212)
+			}
+			return true
+		l211:
+			position, tokenIndex = position211, tokenIndex211
+			return false
+		},
+		/* 32 integrate <- <('i' 'n' 't' 'e' 'g' 'r' 'a' 't' 'e' open e1 comma variable (comma e1 comma e1)? close)> */
+		func() bool {
+			position213, tokenIndex213 := position, tokenIndex
+			{
+				position214 := position
+				if buffer[position] != rune('i') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l213
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l213
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l213
+				}
+				if !_rules[rulee1]() {
+					goto l213
+				}
+				if !_rules[rulecomma]() {
+					goto l213
+				}
+				if !_rules[rulevariable]() {
+					goto l213
+				}
+				{
+					position215, tokenIndex215 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l215
+					}
+					if !_rules[rulee1]() {
+						goto l215
+					}
+					if !_rules[rulecomma]() {
+						goto l215
+					}
+					if !_rules[rulee1]() {
+						goto l215
+					}
+					goto l216
+				l215:
+					position, tokenIndex = position215, tokenIndex215
+				}
+			l216:
+				if !_rules[ruleclose]() {
+					goto l213
+				}
+				add(ruleintegrate, position214)
+			}
+			return true
+		l213:
+			position, tokenIndex = position213, tokenIndex213
+			return false
+		},
+		/* 33 solve <- <('s' 'o' 'l' 'v' 'e' open e1 comma variable comma e1 (comma e1)? close)> */
+		func() bool {
+			position217, tokenIndex217 := position, tokenIndex
+			{
+				position218 := position
+				if buffer[position] != rune('s') {
+					goto l217
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l217
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l217
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l217
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l217
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l217
+				}
+				if !_rules[rulee1]() {
+					goto l217
+				}
+				if !_rules[rulecomma]() {
+					goto l217
+				}
+				if !_rules[rulevariable]() {
+					goto l217
+				}
+				if !_rules[rulecomma]() {
+					goto l217
+				}
+				if !_rules[rulee1]() {
+					goto l217
+				}
+				{
+					position219, tokenIndex219 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l219
+					}
+					if !_rules[rulee1]() {
+						goto l219
+					}
+					goto l220
+				l219:
+					position, tokenIndex = position219, tokenIndex219
+				}
+			l220:
+				if !_rules[ruleclose]() {
+					goto l217
+				}
+				add(rulesolve, position218)
+			}
+			return true
+		l217:
+			position, tokenIndex = position217, tokenIndex217
+			return false
+		},
+		/* 34 series <- <('s' 'e' 'r' 'i' 'e' 's' open e1 comma variable comma e1 comma e1 close)> */
+		func() bool {
+			position221, tokenIndex221 := position, tokenIndex
+			{
+				position222 := position
+				if buffer[position] != rune('s') {
+					goto l221
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l221
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l221
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l221
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l221
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l221
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l221
+				}
+				if !_rules[rulee1]() {
+					goto l221
+				}
+				if !_rules[rulecomma]() {
+					goto l221
+				}
+				if !_rules[rulevariable]() {
+					goto l221
+				}
+				if !_rules[rulecomma]() {
+					goto l221
+				}
+				if !_rules[rulee1]() {
+					goto l221
+				}
+				if !_rules[rulecomma]() {
+					goto l221
+				}
+				if !_rules[rulee1]() {
+					goto l221
+				}
+				if !_rules[ruleclose]() {
+					goto l221
+				}
+				add(ruleseries, position222)
+			}
+			return true
+		l221:
+			position, tokenIndex = position221, tokenIndex221
+			return false
+		},
+		/* 35 limit <- <('l' 'i' 'm' 'i' 't' open e1 comma variable comma e1 (comma side)? close)> */
+		func() bool {
+			position223, tokenIndex223 := position, tokenIndex
+			{
+				position224 := position
+				if buffer[position] != rune('l') {
+					goto l223
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l223
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l223
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l223
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l223
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l223
+				}
+				if !_rules[rulee1]() {
+					goto l223
+				}
+				if !_rules[rulecomma]() {
+					goto l223
+				}
+				if !_rules[rulevariable]() {
+					goto l223
+				}
+				if !_rules[rulecomma]() {
+					goto l223
+				}
+				if !_rules[rulee1]() {
+					goto l223
+				}
+				{
+					position225, tokenIndex225 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l225
+					}
+					if !_rules[ruleside]() {
+						goto l225
+					}
+					goto l226
+				l225:
+					position, tokenIndex = position225, tokenIndex225
+				}
+			l226:
+				if !_rules[ruleclose]() {
+					goto l223
+				}
+				add(rulelimit, position224)
+			}
+			return true
+		l223:
+			position, tokenIndex = position223, tokenIndex223
+			return false
+		},
+		/* 36 side <- <(('-' / '+') sp)> */
+		func() bool {
+			position227, tokenIndex227 := position, tokenIndex
+			{
+				position228 := position
+				{
+					position229, tokenIndex229 := position, tokenIndex
+					if buffer[position] != rune('-') {
+						goto l230
+					}
+					position++
+					goto l229
+				l230:
+					position, tokenIndex = position229, tokenIndex229
+					if buffer[position] != rune('+') {
+						goto l227
+					}
+					position++
+				}
+			l229:
+				if !_rules[rulesp]() {
+					goto l227
+				}
+				add(ruleside, position228)
+			}
+			return true
+		l227:
+			position, tokenIndex = position227, tokenIndex227
+			return false
+		},
+		/* 37 eval <- <('e' 'v' 'a' 'l' open e1 (comma binding)* close)> */
+		func() bool {
+			position231, tokenIndex231 := position, tokenIndex
+			{
+				position232 := position
+				if buffer[position] != rune('e') {
+					goto l231
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l231
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l231
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l231
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l231
+				}
+				if !_rules[rulee1]() {
+					goto l231
+				}
+			l233:
+				{
+					position234, tokenIndex234 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l234
+					}
+					if !_rules[rulebinding]() {
+						goto l234
+					}
+					goto l233
+				l234:
+					position, tokenIndex = position234, tokenIndex234
+				}
+				if !_rules[ruleclose]() {
+					goto l231
+				}
+				add(ruleeval, position232)
+			}
+			return true
+		l231:
+			position, tokenIndex = position231, tokenIndex231
+			return false
+		},
+		/* 38 binding <- <(variable equals e1)> */
+		func() bool {
+			position235, tokenIndex235 := position, tokenIndex
+			{
+				position236 := position
+				if !_rules[rulevariable]() {
+					goto l235
+				}
+				if !_rules[ruleequals]() {
+					goto l235
+				}
+				if !_rules[rulee1]() {
+					goto l235
+				}
+				add(rulebinding, position236)
+			}
+			return true
+		l235:
+			position, tokenIndex = position235, tokenIndex235
+			return false
+		},
+		/* 39 log <- <('l' 'o' 'g' open e1 (comma e1)? close)> */
+		func() bool {
+			position237, tokenIndex237 := position, tokenIndex
+			{
+				position238 := position
+				if buffer[position] != rune('l') {
+					goto l237
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l237
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l237
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l237
+				}
+				if !_rules[rulee1]() {
+					goto l237
+				}
+				{
+					position239, tokenIndex239 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l239
+					}
+					if !_rules[rulee1]() {
+						goto l239
+					}
+					goto l240
+				l239:
+					position, tokenIndex = position239, tokenIndex239
+				}
+			l240:
+				if !_rules[ruleclose]() {
+					goto l237
+				}
+				add(rulelog, position238)
+			}
+			return true
+		l237:
+			position, tokenIndex = position237, tokenIndex237
+			return false
+		},
+		/* 40 log2 <- <('l' 'o' 'g' '2' open e1 close)> */
+		func() bool {
+			position241, tokenIndex241 := position, tokenIndex
+			{
+				position242 := position
+				if buffer[position] != rune('l') {
+					goto l241
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l241
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l241
+				}
+				position++
+				if buffer[position] != rune('2') {
+					goto l241
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l241
+				}
+				if !_rules[rulee1]() {
+					goto l241
+				}
+				if !_rules[ruleclose]() {
+					goto l241
+				}
+				add(rulelog2, position242)
+			}
+			return true
+		l241:
+			position, tokenIndex = position241, tokenIndex241
+			return false
+		},
+		/* 41 log10 <- <('l' 'o' 'g' '1' '0' open e1 close)> */
+		func() bool {
+			position243, tokenIndex243 := position, tokenIndex
+			{
+				position244 := position
+				if buffer[position] != rune('l') {
+					goto l243
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l243
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l243
+				}
+				position++
+				if buffer[position] != rune('1') {
+					goto l243
+				}
+				position++
+				if buffer[position] != rune('0') {
+					goto l243
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l243
+				}
+				if !_rules[rulee1]() {
+					goto l243
+				}
+				if !_rules[ruleclose]() {
+					goto l243
+				}
+				add(rulelog10, position244)
+			}
+			return true
+		l243:
+			position, tokenIndex = position243, tokenIndex243
+			return false
+		},
+		/* 42 exponent2 <- <('e' 'x' 'p' '2' open e1 close)> */
+		func() bool {
+			position245, tokenIndex245 := position, tokenIndex
+			{
+				position246 := position
+				if buffer[position] != rune('e') {
+					goto l245
+				}
+				position++
+				if buffer[position] != rune('x') {
+					goto l245
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l245
+				}
+				position++
+				if buffer[position] != rune('2') {
+					goto l245
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l245
+				}
+				if !_rules[rulee1]() {
+					goto l245
+				}
+				if !_rules[ruleclose]() {
+					goto l245
+				}
+				add(ruleexponent2, position246)
+			}
+			return true
+		l245:
+			position, tokenIndex = position245, tokenIndex245
+			return false
+		},
+		/* 43 sqrt <- <('s' 'q' 'r' 't' open e1 close)> */
+		func() bool {
+			position247, tokenIndex247 := position, tokenIndex
+			{
+				position248 := position
+				if buffer[position] != rune('s') {
+					goto l247
+				}
+				position++
+				if buffer[position] != rune('q') {
+					goto l247
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l247
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l247
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l247
+				}
+				if !_rules[rulee1]() {
+					goto l247
+				}
+				if !_rules[ruleclose]() {
+					goto l247
+				}
+				add(rulesqrt, position248)
+			}
+			return true
+		l247:
+			position, tokenIndex = position247, tokenIndex247
+			return false
+		},
+		/* 44 cos <- <('c' 'o' 's' open e1 close)> */
+		func() bool {
+			position249, tokenIndex249 := position, tokenIndex
+			{
+				position250 := position
+				if buffer[position] != rune('c') {
+					goto l249
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l249
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l249
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l249
+				}
+				if !_rules[rulee1]() {
+					goto l249
+				}
+				if !_rules[ruleclose]() {
+					goto l249
+				}
+				add(rulecos, position250)
+			}
+			return true
+		l249:
+			position, tokenIndex = position249, tokenIndex249
+			return false
+		},
+		/* 45 sin <- <('s' 'i' 'n' open e1 close)> */
+		func() bool {
+			position251, tokenIndex251 := position, tokenIndex
+			{
+				position252 := position
+				if buffer[position] != rune('s') {
+					goto l251
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l251
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l251
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l251
+				}
+				if !_rules[rulee1]() {
+					goto l251
+				}
+				if !_rules[ruleclose]() {
+					goto l251
+				}
+				add(rulesin, position252)
+			}
+			return true
+		l251:
+			position, tokenIndex = position251, tokenIndex251
+			return false
+		},
+		/* 46 tan <- <('t' 'a' 'n' open e1 close)> */
+		func() bool {
+			position253, tokenIndex253 := position, tokenIndex
+			{
+				position254 := position
+				if buffer[position] != rune('t') {
+					goto l253
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l253
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l253
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l253
+				}
+				if !_rules[rulee1]() {
+					goto l253
+				}
+				if !_rules[ruleclose]() {
+					goto l253
+				}
+				add(ruletan, position254)
+			}
+			return true
+		l253:
+			position, tokenIndex = position253, tokenIndex253
+			return false
+		},
+		/* 47 asin <- <('a' 's' 'i' 'n' open e1 close)> */
+		func() bool {
+			position255, tokenIndex255 := position, tokenIndex
+			{
+				position256 := position
+				if buffer[position] != rune('a') {
+					goto l255
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l255
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l255
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l255
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l255
+				}
+				if !_rules[rulee1]() {
+					goto l255
+				}
+				if !_rules[ruleclose]() {
+					goto l255
+				}
+				add(ruleasin, position256)
+			}
+			return true
+		l255:
+			position, tokenIndex = position255, tokenIndex255
+			return false
+		},
+		/* 48 acos <- <('a' 'c' 'o' 's' open e1 close)> */
+		func() bool {
+			position257, tokenIndex257 := position, tokenIndex
+			{
+				position258 := position
+				if buffer[position] != rune('a') {
+					goto l257
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l257
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l257
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l257
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l257
+				}
+				if !_rules[rulee1]() {
+					goto l257
+				}
+				if !_rules[ruleclose]() {
+					goto l257
+				}
+				add(ruleacos, position258)
+			}
+			return true
+		l257:
+			position, tokenIndex = position257, tokenIndex257
+			return false
+		},
+		/* 49 atan2 <- <('a' 't' 'a' 'n' '2' open e1 comma e1 close)> */
+		func() bool {
+			position259, tokenIndex259 := position, tokenIndex
+			{
+				position260 := position
+				if buffer[position] != rune('a') {
+					goto l259
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l259
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l259
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l259
+				}
+				position++
+				if buffer[position] != rune('2') {
+					goto l259
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l259
+				}
+				if !_rules[rulee1]() {
+					goto l259
+				}
+				if !_rules[rulecomma]() {
+					goto l259
+				}
+				if !_rules[rulee1]() {
+					goto l259
+				}
+				if !_rules[ruleclose]() {
+					goto l259
+				}
+				add(ruleatan2, position260)
+			}
+			return true
+		l259:
+			position, tokenIndex = position259, tokenIndex259
+			return false
+		},
+		/* 50 atan <- <('a' 't' 'a' 'n' open e1 close)> */
+		func() bool {
+			position261, tokenIndex261 := position, tokenIndex
+			{
+				position262 := position
+				if buffer[position] != rune('a') {
+					goto l261
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l261
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l261
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l261
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l261
+				}
+				if !_rules[rulee1]() {
+					goto l261
+				}
+				if !_rules[ruleclose]() {
+					goto l261
+				}
+				add(ruleatan, position262)
+			}
+			return true
+		l261:
+			position, tokenIndex = position261, tokenIndex261
+			return false
+		},
+		/* 51 sinh <- <('s' 'i' 'n' 'h' open e1 close)> */
+		func() bool {
+			position263, tokenIndex263 := position, tokenIndex
+			{
+				position264 := position
+				if buffer[position] != rune('s') {
+					goto l263
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l263
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l263
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l263
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l263
+				}
+				if !_rules[rulee1]() {
+					goto l263
+				}
+				if !_rules[ruleclose]() {
+					goto l263
+				}
+				add(rulesinh, position264)
+			}
+			return true
+		l263:
+			position, tokenIndex = position263, tokenIndex263
+			return false
+		},
+		/* 52 cosh <- <('c' 'o' 's' 'h' open e1 close)> */
+		func() bool {
+			position265, tokenIndex265 := position, tokenIndex
+			{
+				position266 := position
+				if buffer[position] != rune('c') {
+					goto l265
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l265
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l265
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l265
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l265
+				}
+				if !_rules[rulee1]() {
+					goto l265
+				}
+				if !_rules[ruleclose]() {
+					goto l265
+				}
+				add(rulecosh, position266)
+			}
+			return true
+		l265:
+			position, tokenIndex = position265, tokenIndex265
+			return false
+		},
+		/* 53 tanh <- <('t' 'a' 'n' 'h' open e1 close)> */
+		func() bool {
+			position267, tokenIndex267 := position, tokenIndex
+			{
+				position268 := position
+				if buffer[position] != rune('t') {
+					goto l267
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l267
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l267
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l267
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l267
+				}
+				if !_rules[rulee1]() {
+					goto l267
+				}
+				if !_rules[ruleclose]() {
+					goto l267
+				}
+				add(ruletanh, position268)
+			}
+			return true
+		l267:
+			position, tokenIndex = position267, tokenIndex267
+			return false
+		},
+		/* 54 asinh <- <('a' 's' 'i' 'n' 'h' open e1 close)> */
+		func() bool {
+			position269, tokenIndex269 := position, tokenIndex
+			{
+				position270 := position
+				if buffer[position] != rune('a') {
+					goto l269
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l269
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l269
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l269
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l269
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l269
+				}
+				if !_rules[rulee1]() {
+					goto l269
+				}
+				if !_rules[ruleclose]() {
+					goto l269
+				}
+				add(ruleasinh, position270)
+			}
+			return true
+		l269:
+			position, tokenIndex = position269, tokenIndex269
+			return false
+		},
+		/* 55 acosh <- <('a' 'c' 'o' 's' 'h' open e1 close)> */
+		func() bool {
+			position271, tokenIndex271 := position, tokenIndex
+			{
+				position272 := position
+				if buffer[position] != rune('a') {
+					goto l271
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l271
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l271
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l271
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l271
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l271
+				}
+				if !_rules[rulee1]() {
+					goto l271
+				}
+				if !_rules[ruleclose]() {
+					goto l271
+				}
+				add(ruleacosh, position272)
+			}
+			return true
+		l271:
+			position, tokenIndex = position271, tokenIndex271
+			return false
+		},
+		/* 56 atanh <- <('a' 't' 'a' 'n' 'h' open e1 close)> */
+		func() bool {
+			position273, tokenIndex273 := position, tokenIndex
+			{
+				position274 := position
+				if buffer[position] != rune('a') {
+					goto l273
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l273
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l273
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l273
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l273
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l273
+				}
+				if !_rules[rulee1]() {
+					goto l273
+				}
+				if !_rules[ruleclose]() {
+					goto l273
+				}
+				add(ruleatanh, position274)
+			}
+			return true
+		l273:
+			position, tokenIndex = position273, tokenIndex273
+			return false
+		},
+		/* 57 sec <- <('s' 'e' 'c' open e1 close)> */
+		func() bool {
+			position275, tokenIndex275 := position, tokenIndex
+			{
+				position276 := position
+				if buffer[position] != rune('s') {
+					goto l275
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l275
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l275
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l275
+				}
+				if !_rules[rulee1]() {
+					goto l275
+				}
+				if !_rules[ruleclose]() {
+					goto l275
+				}
+				add(rulesec, position276)
+			}
+			return true
+		l275:
+			position, tokenIndex = position275, tokenIndex275
+			return false
+		},
+		/* 58 csc <- <('c' 's' 'c' open e1 close)> */
+		func() bool {
+			position277, tokenIndex277 := position, tokenIndex
+			{
+				position278 := position
+				if buffer[position] != rune('c') {
+					goto l277
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l277
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l277
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l277
+				}
+				if !_rules[rulee1]() {
+					goto l277
+				}
+				if !_rules[ruleclose]() {
+					goto l277
+				}
+				add(rulecsc, position278)
+			}
+			return true
+		l277:
+			position, tokenIndex = position277, tokenIndex277
+			return false
+		},
+		/* 59 cot <- <('c' 'o' 't' open e1 close)> */
+		func() bool {
+			position279, tokenIndex279 := position, tokenIndex
+			{
+				position280 := position
+				if buffer[position] != rune('c') {
+					goto l279
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l279
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l279
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l279
+				}
+				if !_rules[rulee1]() {
+					goto l279
+				}
+				if !_rules[ruleclose]() {
+					goto l279
+				}
+				add(rulecot, position280)
+			}
+			return true
+		l279:
+			position, tokenIndex = position279, tokenIndex279
+			return false
+		},
+		/* 60 gamma <- <('g' 'a' 'm' 'm' 'a' open e1 close)> */
+		func() bool {
+			position281, tokenIndex281 := position, tokenIndex
+			{
+				position282 := position
+				if buffer[position] != rune('g') {
+					goto l281
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l281
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l281
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l281
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l281
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l281
+				}
+				if !_rules[rulee1]() {
+					goto l281
+				}
+				if !_rules[ruleclose]() {
+					goto l281
+				}
+				add(rulegamma, position282)
+			}
+			return true
+		l281:
+			position, tokenIndex = position281, tokenIndex281
+			return false
+		},
+		/* 61 lgamma <- <('l' 'g' 'a' 'm' 'm' 'a' open e1 close)> */
+		func() bool {
+			position283, tokenIndex283 := position, tokenIndex
+			{
+				position284 := position
+				if buffer[position] != rune('l') {
+					goto l283
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l283
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l283
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l283
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l283
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l283
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l283
+				}
+				if !_rules[rulee1]() {
+					goto l283
+				}
+				if !_rules[ruleclose]() {
+					goto l283
+				}
+				add(rulelgamma, position284)
+			}
+			return true
+		l283:
+			position, tokenIndex = position283, tokenIndex283
+			return false
+		},
+		/* 62 beta <- <('b' 'e' 't' 'a' open e1 comma e1 close)> */
+		func() bool {
+			position285, tokenIndex285 := position, tokenIndex
+			{
+				position286 := position
+				if buffer[position] != rune('b') {
+					goto l285
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l285
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l285
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l285
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l285
+				}
+				if !_rules[rulee1]() {
+					goto l285
+				}
+				if !_rules[rulecomma]() {
+					goto l285
+				}
+				if !_rules[rulee1]() {
+					goto l285
+				}
+				if !_rules[ruleclose]() {
+					goto l285
+				}
+				add(rulebeta, position286)
+			}
+			return true
+		l285:
+			position, tokenIndex = position285, tokenIndex285
+			return false
+		},
+		/* 63 erf <- <('e' 'r' 'f' open e1 close)> */
+		func() bool {
+			position287, tokenIndex287 := position, tokenIndex
+			{
+				position288 := position
+				if buffer[position] != rune('e') {
+					goto l287
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l287
+				}
+				position++
+				if buffer[position] != rune('f') {
+					goto l287
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l287
+				}
+				if !_rules[rulee1]() {
+					goto l287
+				}
+				if !_rules[ruleclose]() {
+					goto l287
+				}
+				add(ruleerf, position288)
+			}
+			return true
+		l287:
+			position, tokenIndex = position287, tokenIndex287
+			return false
+		},
+		/* 64 erfc <- <('e' 'r' 'f' 'c' open e1 close)> */
+		func() bool {
+			position289, tokenIndex289 := position, tokenIndex
+			{
+				position290 := position
+				if buffer[position] != rune('e') {
+					goto l289
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l289
+				}
+				position++
+				if buffer[position] != rune('f') {
+					goto l289
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l289
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l289
+				}
+				if !_rules[rulee1]() {
+					goto l289
+				}
+				if !_rules[ruleclose]() {
+					goto l289
+				}
+				add(ruleerfc, position290)
+			}
+			return true
+		l289:
+			position, tokenIndex = position289, tokenIndex289
+			return false
+		},
+		/* 65 zeta <- <('z' 'e' 't' 'a' open e1 (comma e1)? close)> */
+		func() bool {
+			position291, tokenIndex291 := position, tokenIndex
+			{
+				position292 := position
+				if buffer[position] != rune('z') {
+					goto l291
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l291
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l291
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l291
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l291
+				}
+				if !_rules[rulee1]() {
+					goto l291
+				}
+				{
+					position293, tokenIndex293 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l293
+					}
+					if !_rules[rulee1]() {
+						goto l293
+					}
+					goto l294
+				l293:
+					position, tokenIndex = position293, tokenIndex293
+				}
+			l294:
+				if !_rules[ruleclose]() {
+					goto l291
+				}
+				add(rulezeta, position292)
+			}
+			return true
+		l291:
+			position, tokenIndex = position291, tokenIndex291
+			return false
+		},
+		/* 66 digamma <- <('d' 'i' 'g' 'a' 'm' 'm' 'a' open e1 close)> */
+		func() bool {
+			position295, tokenIndex295 := position, tokenIndex
+			{
+				position296 := position
+				if buffer[position] != rune('d') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l295
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l295
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l295
+				}
+				if !_rules[rulee1]() {
+					goto l295
+				}
+				if !_rules[ruleclose]() {
+					goto l295
+				}
+				add(ruledigamma, position296)
+			}
+			return true
+		l295:
+			position, tokenIndex = position295, tokenIndex295
+			return false
+		},
+		/* 67 polygamma <- <('p' 'o' 'l' 'y' 'g' 'a' 'm' 'm' 'a' open e1 comma e1 close)> */
+		func() bool {
+			position297, tokenIndex297 := position, tokenIndex
+			{
+				position298 := position
+				if buffer[position] != rune('p') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('g') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l297
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l297
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l297
+				}
+				if !_rules[rulee1]() {
+					goto l297
+				}
+				if !_rules[rulecomma]() {
+					goto l297
+				}
+				if !_rules[rulee1]() {
+					goto l297
+				}
+				if !_rules[ruleclose]() {
+					goto l297
+				}
+				add(rulepolygamma, position298)
+			}
+			return true
+		l297:
+			position, tokenIndex = position297, tokenIndex297
+			return false
+		},
+		/* 68 lambertw <- <('l' 'a' 'm' 'b' 'e' 'r' 't' 'w' open e1 (comma e1)? close)> */
+		func() bool {
+			position299, tokenIndex299 := position, tokenIndex
+			{
+				position300 := position
+				if buffer[position] != rune('l') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('b') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l299
+				}
+				position++
+				if buffer[position] != rune('w') {
+					goto l299
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l299
+				}
+				if !_rules[rulee1]() {
+					goto l299
+				}
+				{
+					position301, tokenIndex301 := position, tokenIndex
+					if !_rules[rulecomma]() {
+						goto l301
+					}
+					if !_rules[rulee1]() {
+						goto l301
+					}
+					goto l302
+				l301:
+					position, tokenIndex = position301, tokenIndex301
+				}
+			l302:
+				if !_rules[ruleclose]() {
+					goto l299
+				}
+				add(rulelambertw, position300)
+			}
+			return true
+		l299:
+			position, tokenIndex = position299, tokenIndex299
+			return false
+		},
+		/* 69 besselj <- <('b' 'e' 's' 's' 'e' 'l' 'j' open e1 comma e1 close)> */
+		func() bool {
+			position303, tokenIndex303 := position, tokenIndex
+			{
+				position304 := position
+				if buffer[position] != rune('b') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l303
+				}
+				position++
+				if buffer[position] != rune('j') {
+					goto l303
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l303
+				}
+				if !_rules[rulee1]() {
+					goto l303
+				}
+				if !_rules[rulecomma]() {
+					goto l303
+				}
+				if !_rules[rulee1]() {
+					goto l303
+				}
+				if !_rules[ruleclose]() {
+					goto l303
+				}
+				add(rulebesselj, position304)
+			}
+			return true
+		l303:
+			position, tokenIndex = position303, tokenIndex303
+			return false
+		},
+		/* 70 bessely <- <('b' 'e' 's' 's' 'e' 'l' 'y' open e1 comma e1 close)> */
+		func() bool {
+			position305, tokenIndex305 := position, tokenIndex
+			{
+				position306 := position
+				if buffer[position] != rune('b') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l305
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l305
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l305
+				}
+				if !_rules[rulee1]() {
+					goto l305
+				}
+				if !_rules[rulecomma]() {
+					goto l305
+				}
+				if !_rules[rulee1]() {
+					goto l305
+				}
+				if !_rules[ruleclose]() {
+					goto l305
+				}
+				add(rulebessely, position306)
+			}
+			return true
+		l305:
+			position, tokenIndex = position305, tokenIndex305
+			return false
+		},
+		/* 71 besseli <- <('b' 'e' 's' 's' 'e' 'l' 'i' open e1 comma e1 close)> */
+		func() bool {
+			position307, tokenIndex307 := position, tokenIndex
+			{
+				position308 := position
+				if buffer[position] != rune('b') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l307
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l307
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l307
+				}
+				if !_rules[rulee1]() {
+					goto l307
+				}
+				if !_rules[rulecomma]() {
+					goto l307
+				}
+				if !_rules[rulee1]() {
+					goto l307
+				}
+				if !_rules[ruleclose]() {
+					goto l307
+				}
+				add(rulebesseli, position308)
+			}
+			return true
+		l307:
+			position, tokenIndex = position307, tokenIndex307
+			return false
+		},
+		/* 72 besselk <- <('b' 'e' 's' 's' 'e' 'l' 'k' open e1 comma e1 close)> */
+		func() bool {
+			position309, tokenIndex309 := position, tokenIndex
+			{
+				position310 := position
+				if buffer[position] != rune('b') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l309
+				}
+				position++
+				if buffer[position] != rune('k') {
+					goto l309
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l309
+				}
+				if !_rules[rulee1]() {
+					goto l309
+				}
+				if !_rules[rulecomma]() {
+					goto l309
+				}
+				if !_rules[rulee1]() {
+					goto l309
+				}
+				if !_rules[ruleclose]() {
+					goto l309
+				}
+				add(rulebesselk, position310)
+			}
+			return true
+		l309:
+			position, tokenIndex = position309, tokenIndex309
+			return false
+		},
+		/* 73 airyaiprime <- <('a' 'i' 'r' 'y' 'a' 'i' 'p' 'r' 'i' 'm' 'e' open e1 close)> */
+		func() bool {
+			position311, tokenIndex311 := position, tokenIndex
+			{
+				position312 := position
+				if buffer[position] != rune('a') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l311
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l311
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l311
+				}
+				if !_rules[rulee1]() {
+					goto l311
+				}
+				if !_rules[ruleclose]() {
+					goto l311
+				}
+				add(ruleairyaiprime, position312)
+			}
+			return true
+		l311:
+			position, tokenIndex = position311, tokenIndex311
+			return false
+		},
+		/* 74 airybiprime <- <('a' 'i' 'r' 'y' 'b' 'i' 'p' 'r' 'i' 'm' 'e' open e1 close)> */
+		func() bool {
+			position313, tokenIndex313 := position, tokenIndex
+			{
+				position314 := position
+				if buffer[position] != rune('a') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('b') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l313
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l313
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l313
+				}
+				if !_rules[rulee1]() {
+					goto l313
+				}
+				if !_rules[ruleclose]() {
+					goto l313
+				}
+				add(ruleairybiprime, position314)
+			}
+			return true
+		l313:
+			position, tokenIndex = position313, tokenIndex313
+			return false
+		},
+		/* 75 airyai <- <('a' 'i' 'r' 'y' 'a' 'i' open e1 close)> */
+		func() bool {
+			position315, tokenIndex315 := position, tokenIndex
+			{
+				position316 := position
+				if buffer[position] != rune('a') {
+					goto l315
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l315
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l315
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l315
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l315
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l315
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l315
+				}
+				if !_rules[rulee1]() {
+					goto l315
 				}
-				add(rulenotation, position80)
+				if !_rules[ruleclose]() {
+					goto l315
+				}
+				add(ruleairyai, position316)
 			}
 			return true
-		l79:
-			position, tokenIndex = position79, tokenIndex79
+		l315:
+			position, tokenIndex = position315, tokenIndex315
 			return false
 		},
-		/* 12 exp1 <- <('e' 'x' 'p' open e1 close)> */
+		/* 76 airybi <- <('a' 'i' 'r' 'y' 'b' 'i' open e1 close)> */
 		func() bool {
-			position83, tokenIndex83 := position, tokenIndex
+			position317, tokenIndex317 := position, tokenIndex
 			{
-				position84 := position
-				if buffer[position] != rune('e') {
-					goto l83
+				position318 := position
+				if buffer[position] != rune('a') {
+					goto l317
 				}
 				position++
-				if buffer[position] != rune('x') {
-					goto l83
+				if buffer[position] != rune('i') {
+					goto l317
 				}
 				position++
-				if buffer[position] != rune('p') {
-					goto l83
+				if buffer[position] != rune('r') {
+					goto l317
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l317
+				}
+				position++
+				if buffer[position] != rune('b') {
+					goto l317
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l317
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l83
+					goto l317
 				}
 				if !_rules[rulee1]() {
-					goto l83
+					goto l317
 				}
 				if !_rules[ruleclose]() {
-					goto l83
+					goto l317
 				}
-				add(ruleexp1, position84)
+				add(ruleairybi, position318)
 			}
 			return true
-		l83:
-			position, tokenIndex = position83, tokenIndex83
+		l317:
+			position, tokenIndex = position317, tokenIndex317
 			return false
 		},
-		/* 13 exp2 <- <('e' '^' value)> */
+		/* 77 ellipk <- <('e' 'l' 'l' 'i' 'p' 'k' open e1 close)> */
 		func() bool {
-			position85, tokenIndex85 := position, tokenIndex
+			position319, tokenIndex319 := position, tokenIndex
 			{
-				position86 := position
+				position320 := position
 				if buffer[position] != rune('e') {
-					goto l85
+					goto l319
 				}
 				position++
-				if buffer[position] != rune('^') {
-					goto l85
+				if buffer[position] != rune('l') {
+					goto l319
 				}
 				position++
-				if !_rules[rulevalue]() {
-					goto l85
+				if buffer[position] != rune('l') {
+					goto l319
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l319
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l319
+				}
+				position++
+				if buffer[position] != rune('k') {
+					goto l319
 				}
-				add(ruleexp2, position86)
+				position++
+				if !_rules[ruleopen]() {
+					goto l319
+				}
+				if !_rules[rulee1]() {
+					goto l319
+				}
+				if !_rules[ruleclose]() {
+					goto l319
+				}
+				add(ruleellipk, position320)
 			}
 			return true
-		l85:
-			position, tokenIndex = position85, tokenIndex85
+		l319:
+			position, tokenIndex = position319, tokenIndex319
 			return false
 		},
-		/* 14 natural <- <('e' sp)> */
+		/* 78 ellipe <- <('e' 'l' 'l' 'i' 'p' 'e' open e1 close)> */
 		func() bool {
-			position87, tokenIndex87 := position, tokenIndex
+			position321, tokenIndex321 := position, tokenIndex
 			{
-				position88 := position
+				position322 := position
 				if buffer[position] != rune('e') {
-					goto l87
+					goto l321
 				}
 				position++
-				if !_rules[rulesp]() {
-					goto l87
+				if buffer[position] != rune('l') {
+					goto l321
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l321
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l321
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l321
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l321
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l321
 				}
-				add(rulenatural, position88)
+				if !_rules[rulee1]() {
+					goto l321
+				}
+				if !_rules[ruleclose]() {
+					goto l321
+				}
+				add(ruleellipe, position322)
 			}
 			return true
-		l87:
-			position, tokenIndex = position87, tokenIndex87
+		l321:
+			position, tokenIndex = position321, tokenIndex321
 			return false
 		},
-		/* 15 pi <- <('p' 'i' sp)> */
+		/* 79 gcd <- <('g' 'c' 'd' open e1 comma e1 close)> */
 		func() bool {
-			position89, tokenIndex89 := position, tokenIndex
+			position323, tokenIndex323 := position, tokenIndex
 			{
-				position90 := position
-				if buffer[position] != rune('p') {
-					goto l89
+				position324 := position
+				if buffer[position] != rune('g') {
+					goto l323
 				}
 				position++
-				if buffer[position] != rune('i') {
-					goto l89
+				if buffer[position] != rune('c') {
+					goto l323
 				}
 				position++
-				if !_rules[rulesp]() {
-					goto l89
+				if buffer[position] != rune('d') {
+					goto l323
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l323
+				}
+				if !_rules[rulee1]() {
+					goto l323
+				}
+				if !_rules[rulecomma]() {
+					goto l323
 				}
-				add(rulepi, position90)
+				if !_rules[rulee1]() {
+					goto l323
+				}
+				if !_rules[ruleclose]() {
+					goto l323
+				}
+				add(rulegcd, position324)
 			}
 			return true
-		l89:
-			position, tokenIndex = position89, tokenIndex89
+		l323:
+			position, tokenIndex = position323, tokenIndex323
 			return false
 		},
-		/* 16 prec <- <('p' 'r' 'e' 'c' open e1 close)> */
+		/* 80 lcm <- <('l' 'c' 'm' open e1 comma e1 close)> */
 		func() bool {
-			position91, tokenIndex91 := position, tokenIndex
+			position325, tokenIndex325 := position, tokenIndex
 			{
-				position92 := position
-				if buffer[position] != rune('p') {
-					goto l91
-				}
-				position++
-				if buffer[position] != rune('r') {
-					goto l91
+				position326 := position
+				if buffer[position] != rune('l') {
+					goto l325
 				}
 				position++
-				if buffer[position] != rune('e') {
-					goto l91
+				if buffer[position] != rune('c') {
+					goto l325
 				}
 				position++
-				if buffer[position] != rune('c') {
-					goto l91
+				if buffer[position] != rune('m') {
+					goto l325
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l91
+					goto l325
+				}
+				if !_rules[rulee1]() {
+					goto l325
+				}
+				if !_rules[rulecomma]() {
+					goto l325
 				}
 				if !_rules[rulee1]() {
-					goto l91
+					goto l325
 				}
 				if !_rules[ruleclose]() {
-					goto l91
+					goto l325
 				}
-				add(ruleprec, position92)
+				add(rulelcm, position326)
 			}
 			return true
-		l91:
-			position, tokenIndex = position91, tokenIndex91
+		l325:
+			position, tokenIndex = position325, tokenIndex325
 			return false
 		},
-		/* 17 simplify <- <('s' 'i' 'm' 'p' 'l' 'i' 'f' 'y' open e1 close)> */
+		/* 81 isprime <- <('i' 's' 'p' 'r' 'i' 'm' 'e' open e1 close)> */
 		func() bool {
-			position93, tokenIndex93 := position, tokenIndex
+			position327, tokenIndex327 := position, tokenIndex
 			{
-				position94 := position
-				if buffer[position] != rune('s') {
-					goto l93
-				}
-				position++
+				position328 := position
 				if buffer[position] != rune('i') {
-					goto l93
+					goto l327
 				}
 				position++
-				if buffer[position] != rune('m') {
-					goto l93
+				if buffer[position] != rune('s') {
+					goto l327
 				}
 				position++
 				if buffer[position] != rune('p') {
-					goto l93
+					goto l327
 				}
 				position++
-				if buffer[position] != rune('l') {
-					goto l93
+				if buffer[position] != rune('r') {
+					goto l327
 				}
 				position++
 				if buffer[position] != rune('i') {
-					goto l93
+					goto l327
 				}
 				position++
-				if buffer[position] != rune('f') {
-					goto l93
+				if buffer[position] != rune('m') {
+					goto l327
 				}
 				position++
-				if buffer[position] != rune('y') {
-					goto l93
+				if buffer[position] != rune('e') {
+					goto l327
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l93
+					goto l327
 				}
 				if !_rules[rulee1]() {
-					goto l93
+					goto l327
 				}
 				if !_rules[ruleclose]() {
-					goto l93
+					goto l327
 				}
-				add(rulesimplify, position94)
+				add(ruleisprime, position328)
 			}
 			return true
-		l93:
-			position, tokenIndex = position93, tokenIndex93
+		l327:
+			position, tokenIndex = position327, tokenIndex327
 			return false
 		},
-		/* 18 derivative <- <('d' 'e' 'r' 'i' 'v' 'a' 't' 'i' 'v' 'e' open e1 close)> */
+		/* 82 nextprime <- <('n' 'e' 'x' 't' 'p' 'r' 'i' 'm' 'e' open e1 close)> */
 		func() bool {
-			position95, tokenIndex95 := position, tokenIndex
+			position329, tokenIndex329 := position, tokenIndex
 			{
-				position96 := position
-				if buffer[position] != rune('d') {
-					goto l95
+				position330 := position
+				if buffer[position] != rune('n') {
+					goto l329
 				}
 				position++
 				if buffer[position] != rune('e') {
-					goto l95
+					goto l329
+				}
+				position++
+				if buffer[position] != rune('x') {
+					goto l329
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l329
+				}
+				position++
+				if buffer[position] != rune('p') {
+					goto l329
 				}
 				position++
 				if buffer[position] != rune('r') {
-					goto l95
+					goto l329
 				}
 				position++
 				if buffer[position] != rune('i') {
-					goto l95
+					goto l329
 				}
 				position++
-				if buffer[position] != rune('v') {
-					goto l95
+				if buffer[position] != rune('m') {
+					goto l329
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l329
+				}
+				position++
+				if !_rules[ruleopen]() {
+					goto l329
+				}
+				if !_rules[rulee1]() {
+					goto l329
+				}
+				if !_rules[ruleclose]() {
+					goto l329
+				}
+				add(rulenextprime, position330)
+			}
+			return true
+		l329:
+			position, tokenIndex = position329, tokenIndex329
+			return false
+		},
+		/* 83 factor <- <('f' 'a' 'c' 't' 'o' 'r' open e1 close)> */
+		func() bool {
+			position331, tokenIndex331 := position, tokenIndex
+			{
+				position332 := position
+				if buffer[position] != rune('f') {
+					goto l331
 				}
 				position++
 				if buffer[position] != rune('a') {
-					goto l95
+					goto l331
 				}
 				position++
-				if buffer[position] != rune('t') {
-					goto l95
+				if buffer[position] != rune('c') {
+					goto l331
 				}
 				position++
-				if buffer[position] != rune('i') {
-					goto l95
+				if buffer[position] != rune('t') {
+					goto l331
 				}
 				position++
-				if buffer[position] != rune('v') {
-					goto l95
+				if buffer[position] != rune('o') {
+					goto l331
 				}
 				position++
-				if buffer[position] != rune('e') {
-					goto l95
+				if buffer[position] != rune('r') {
+					goto l331
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l95
+					goto l331
 				}
 				if !_rules[rulee1]() {
-					goto l95
+					goto l331
 				}
 				if !_rules[ruleclose]() {
-					goto l95
+					goto l331
 				}
-				add(rulederivative, position96)
+				add(rulefactor, position332)
 			}
 			return true
-		l95:
-			position, tokenIndex = position95, tokenIndex95
+		l331:
+			position, tokenIndex = position331, tokenIndex331
 			return false
 		},
-		/* 19 log <- <('l' 'o' 'g' open e1 close)> */
+		/* 84 totient <- <('t' 'o' 't' 'i' 'e' 'n' 't' open e1 close)> */
 		func() bool {
-			position97, tokenIndex97 := position, tokenIndex
+			position333, tokenIndex333 := position, tokenIndex
 			{
-				position98 := position
-				if buffer[position] != rune('l') {
-					goto l97
+				position334 := position
+				if buffer[position] != rune('t') {
+					goto l333
 				}
 				position++
 				if buffer[position] != rune('o') {
-					goto l97
+					goto l333
 				}
 				position++
-				if buffer[position] != rune('g') {
-					goto l97
+				if buffer[position] != rune('t') {
+					goto l333
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l333
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l333
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l333
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l333
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l97
+					goto l333
 				}
 				if !_rules[rulee1]() {
-					goto l97
+					goto l333
 				}
 				if !_rules[ruleclose]() {
-					goto l97
+					goto l333
 				}
-				add(rulelog, position98)
+				add(ruletotient, position334)
 			}
 			return true
-		l97:
-			position, tokenIndex = position97, tokenIndex97
+		l333:
+			position, tokenIndex = position333, tokenIndex333
 			return false
 		},
-		/* 20 sqrt <- <('s' 'q' 'r' 't' open e1 close)> */
+		/* 85 powmod <- <('p' 'o' 'w' 'm' 'o' 'd' open e1 comma e1 comma e1 close)> */
 		func() bool {
-			position99, tokenIndex99 := position, tokenIndex
+			position335, tokenIndex335 := position, tokenIndex
 			{
-				position100 := position
-				if buffer[position] != rune('s') {
-					goto l99
+				position336 := position
+				if buffer[position] != rune('p') {
+					goto l335
 				}
 				position++
-				if buffer[position] != rune('q') {
-					goto l99
+				if buffer[position] != rune('o') {
+					goto l335
 				}
 				position++
-				if buffer[position] != rune('r') {
-					goto l99
+				if buffer[position] != rune('w') {
+					goto l335
 				}
 				position++
-				if buffer[position] != rune('t') {
-					goto l99
+				if buffer[position] != rune('m') {
+					goto l335
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l335
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l335
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l99
+					goto l335
+				}
+				if !_rules[rulee1]() {
+					goto l335
+				}
+				if !_rules[rulecomma]() {
+					goto l335
+				}
+				if !_rules[rulee1]() {
+					goto l335
+				}
+				if !_rules[rulecomma]() {
+					goto l335
 				}
 				if !_rules[rulee1]() {
-					goto l99
+					goto l335
 				}
 				if !_rules[ruleclose]() {
-					goto l99
+					goto l335
 				}
-				add(rulesqrt, position100)
+				add(rulepowmod, position336)
 			}
 			return true
-		l99:
-			position, tokenIndex = position99, tokenIndex99
+		l335:
+			position, tokenIndex = position335, tokenIndex335
 			return false
 		},
-		/* 21 cos <- <('c' 'o' 's' open e1 close)> */
+		/* 86 invmod <- <('i' 'n' 'v' 'm' 'o' 'd' open e1 comma e1 close)> */
 		func() bool {
-			position101, tokenIndex101 := position, tokenIndex
+			position337, tokenIndex337 := position, tokenIndex
 			{
-				position102 := position
-				if buffer[position] != rune('c') {
-					goto l101
+				position338 := position
+				if buffer[position] != rune('i') {
+					goto l337
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l337
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l337
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l337
 				}
 				position++
 				if buffer[position] != rune('o') {
-					goto l101
+					goto l337
 				}
 				position++
-				if buffer[position] != rune('s') {
-					goto l101
+				if buffer[position] != rune('d') {
+					goto l337
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l101
+					goto l337
+				}
+				if !_rules[rulee1]() {
+					goto l337
+				}
+				if !_rules[rulecomma]() {
+					goto l337
 				}
 				if !_rules[rulee1]() {
-					goto l101
+					goto l337
 				}
 				if !_rules[ruleclose]() {
-					goto l101
+					goto l337
 				}
-				add(rulecos, position102)
+				add(ruleinvmod, position338)
 			}
 			return true
-		l101:
-			position, tokenIndex = position101, tokenIndex101
+		l337:
+			position, tokenIndex = position337, tokenIndex337
 			return false
 		},
-		/* 22 sin <- <('s' 'i' 'n' open e1 close)> */
+		/* 87 crt <- <('c' 'r' 't' open e1 comma e1 close)> */
 		func() bool {
-			position103, tokenIndex103 := position, tokenIndex
+			position339, tokenIndex339 := position, tokenIndex
 			{
-				position104 := position
-				if buffer[position] != rune('s') {
-					goto l103
+				position340 := position
+				if buffer[position] != rune('c') {
+					goto l339
 				}
 				position++
-				if buffer[position] != rune('i') {
-					goto l103
+				if buffer[position] != rune('r') {
+					goto l339
 				}
 				position++
-				if buffer[position] != rune('n') {
-					goto l103
+				if buffer[position] != rune('t') {
+					goto l339
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l103
+					goto l339
+				}
+				if !_rules[rulee1]() {
+					goto l339
+				}
+				if !_rules[rulecomma]() {
+					goto l339
 				}
 				if !_rules[rulee1]() {
-					goto l103
+					goto l339
 				}
 				if !_rules[ruleclose]() {
-					goto l103
+					goto l339
 				}
-				add(rulesin, position104)
+				add(rulecrt, position340)
 			}
 			return true
-		l103:
-			position, tokenIndex = position103, tokenIndex103
+		l339:
+			position, tokenIndex = position339, tokenIndex339
 			return false
 		},
-		/* 23 tan <- <('t' 'a' 'n' open e1 close)> */
+		/* 88 jacobi <- <('j' 'a' 'c' 'o' 'b' 'i' open e1 comma e1 close)> */
 		func() bool {
-			position105, tokenIndex105 := position, tokenIndex
+			position341, tokenIndex341 := position, tokenIndex
 			{
-				position106 := position
-				if buffer[position] != rune('t') {
-					goto l105
+				position342 := position
+				if buffer[position] != rune('j') {
+					goto l341
 				}
 				position++
 				if buffer[position] != rune('a') {
-					goto l105
+					goto l341
 				}
 				position++
-				if buffer[position] != rune('n') {
-					goto l105
+				if buffer[position] != rune('c') {
+					goto l341
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l341
+				}
+				position++
+				if buffer[position] != rune('b') {
+					goto l341
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l341
 				}
 				position++
 				if !_rules[ruleopen]() {
-					goto l105
+					goto l341
+				}
+				if !_rules[rulee1]() {
+					goto l341
+				}
+				if !_rules[rulecomma]() {
+					goto l341
 				}
 				if !_rules[rulee1]() {
-					goto l105
+					goto l341
 				}
 				if !_rules[ruleclose]() {
-					goto l105
+					goto l341
 				}
-				add(ruletan, position106)
+				add(rulejacobi, position342)
 			}
 			return true
-		l105:
-			position, tokenIndex = position105, tokenIndex105
+		l341:
+			position, tokenIndex = position341, tokenIndex341
 			return false
 		},
-		/* 24 sub <- <(open e1 close)> */
+		/* 89 sub <- <(open e1 close)> */
 		func() bool {
-			position107, tokenIndex107 := position, tokenIndex
+			position343, tokenIndex343 := position, tokenIndex
 			{
-				position108 := position
+				position344 := position
 				if !_rules[ruleopen]() {
-					goto l107
+					goto l343
 				}
 				if !_rules[rulee1]() {
-					goto l107
+					goto l343
 				}
 				if !_rules[ruleclose]() {
-					goto l107
+					goto l343
 				}
-				add(rulesub, position108)
+				add(rulesub, position344)
 			}
 			return true
-		l107:
-			position, tokenIndex = position107, tokenIndex107
+		l343:
+			position, tokenIndex = position343, tokenIndex343
 			return false
 		},
-		/* 25 add <- <('+' sp)> */
+		/* 90 add <- <('+' sp)> */
 		func() bool {
-			position109, tokenIndex109 := position, tokenIndex
+			position345, tokenIndex345 := position, tokenIndex
 			{
-				position110 := position
+				position346 := position
 				if buffer[position] != rune('+') {
-					goto l109
+					goto l345
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l109
+					goto l345
 				}
-				add(ruleadd, position110)
+				add(ruleadd, position346)
 			}
 			return true
-		l109:
-			position, tokenIndex = position109, tokenIndex109
+		l345:
+			position, tokenIndex = position345, tokenIndex345
 			return false
 		},
-		/* 26 minus <- <('-' sp)> */
+		/* 91 minus <- <('-' sp)> */
 		func() bool {
-			position111, tokenIndex111 := position, tokenIndex
+			position347, tokenIndex347 := position, tokenIndex
 			{
-				position112 := position
+				position348 := position
 				if buffer[position] != rune('-') {
-					goto l111
+					goto l347
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l111
+					goto l347
 				}
-				add(ruleminus, position112)
+				add(ruleminus, position348)
 			}
 			return true
-		l111:
-			position, tokenIndex = position111, tokenIndex111
+		l347:
+			position, tokenIndex = position347, tokenIndex347
 			return false
 		},
-		/* 27 multiply <- <('*' sp)> */
+		/* 92 multiply <- <('*' sp)> */
 		func() bool {
-			position113, tokenIndex113 := position, tokenIndex
+			position349, tokenIndex349 := position, tokenIndex
 			{
-				position114 := position
+				position350 := position
 				if buffer[position] != rune('*') {
-					goto l113
+					goto l349
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l113
+					goto l349
 				}
-				add(rulemultiply, position114)
+				add(rulemultiply, position350)
 			}
 			return true
-		l113:
-			position, tokenIndex = position113, tokenIndex113
+		l349:
+			position, tokenIndex = position349, tokenIndex349
 			return false
 		},
-		/* 28 divide <- <('/' sp)> */
+		/* 93 divide <- <('/' sp)> */
 		func() bool {
-			position115, tokenIndex115 := position, tokenIndex
+			position351, tokenIndex351 := position, tokenIndex
 			{
-				position116 := position
+				position352 := position
 				if buffer[position] != rune('/') {
-					goto l115
+					goto l351
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l115
+					goto l351
 				}
-				add(ruledivide, position116)
+				add(ruledivide, position352)
 			}
 			return true
-		l115:
-			position, tokenIndex = position115, tokenIndex115
+		l351:
+			position, tokenIndex = position351, tokenIndex351
 			return false
 		},
-		/* 29 modulus <- <('%' sp)> */
+		/* 94 modulus <- <('%' sp)> */
 		func() bool {
-			position117, tokenIndex117 := position, tokenIndex
+			position353, tokenIndex353 := position, tokenIndex
 			{
-				position118 := position
+				position354 := position
 				if buffer[position] != rune('%') {
-					goto l117
+					goto l353
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l117
+					goto l353
 				}
-				add(rulemodulus, position118)
+				add(rulemodulus, position354)
 			}
 			return true
-		l117:
-			position, tokenIndex = position117, tokenIndex117
+		l353:
+			position, tokenIndex = position353, tokenIndex353
 			return false
 		},
-		/* 30 exponentiation <- <('^' sp)> */
+		/* 95 exponentiation <- <('^' sp)> */
 		func() bool {
-			position119, tokenIndex119 := position, tokenIndex
+			position355, tokenIndex355 := position, tokenIndex
 			{
-				position120 := position
+				position356 := position
 				if buffer[position] != rune('^') {
-					goto l119
+					goto l355
+				}
+				position++
+				if !_rules[rulesp]() {
+					goto l355
+				}
+				add(ruleexponentiation, position356)
+			}
+			return true
+		l355:
+			position, tokenIndex = position355, tokenIndex355
+			return false
+		},
+		/* 96 factorial <- <('!' sp)> */
+		func() bool {
+			position357, tokenIndex357 := position, tokenIndex
+			{
+				position358 := position
+				if buffer[position] != rune('!') {
+					goto l357
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l119
+					goto l357
 				}
-				add(ruleexponentiation, position120)
+				add(rulefactorial, position358)
 			}
 			return true
-		l119:
-			position, tokenIndex = position119, tokenIndex119
+		l357:
+			position, tokenIndex = position357, tokenIndex357
 			return false
 		},
-		/* 31 open <- <('(' sp)> */
+		/* 97 open <- <('(' sp)> */
 		func() bool {
-			position121, tokenIndex121 := position, tokenIndex
+			position359, tokenIndex359 := position, tokenIndex
 			{
-				position122 := position
+				position360 := position
 				if buffer[position] != rune('(') {
-					goto l121
+					goto l359
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l121
+					goto l359
 				}
-				add(ruleopen, position122)
+				add(ruleopen, position360)
 			}
 			return true
-		l121:
-			position, tokenIndex = position121, tokenIndex121
+		l359:
+			position, tokenIndex = position359, tokenIndex359
 			return false
 		},
-		/* 32 close <- <(')' sp)> */
+		/* 98 close <- <(')' sp)> */
 		func() bool {
-			position123, tokenIndex123 := position, tokenIndex
+			position361, tokenIndex361 := position, tokenIndex
 			{
-				position124 := position
+				position362 := position
 				if buffer[position] != rune(')') {
-					goto l123
+					goto l361
+				}
+				position++
+				if !_rules[rulesp]() {
+					goto l361
+				}
+				add(ruleclose, position362)
+			}
+			return true
+		l361:
+			position, tokenIndex = position361, tokenIndex361
+			return false
+		},
+		/* 99 comma <- <(',' sp)> */
+		func() bool {
+			position363, tokenIndex363 := position, tokenIndex
+			{
+				position364 := position
+				if buffer[position] != rune(',') {
+					goto l363
+				}
+				position++
+				if !_rules[rulesp]() {
+					goto l363
+				}
+				add(rulecomma, position364)
+			}
+			return true
+		l363:
+			position, tokenIndex = position363, tokenIndex363
+			return false
+		},
+		/* 100 equals <- <('=' sp)> */
+		func() bool {
+			position365, tokenIndex365 := position, tokenIndex
+			{
+				position366 := position
+				if buffer[position] != rune('=') {
+					goto l365
+				}
+				position++
+				if !_rules[rulesp]() {
+					goto l365
+				}
+				add(ruleequals, position366)
+			}
+			return true
+		l365:
+			position, tokenIndex = position365, tokenIndex365
+			return false
+		},
+		/* 101 arrow <- <('-' '>' sp)> */
+		func() bool {
+			position367, tokenIndex367 := position, tokenIndex
+			{
+				position368 := position
+				if buffer[position] != rune('-') {
+					goto l367
+				}
+				position++
+				if buffer[position] != rune('>') {
+					goto l367
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l123
+					goto l367
 				}
-				add(ruleclose, position124)
+				add(rulearrow, position368)
 			}
 			return true
-		l123:
-			position, tokenIndex = position123, tokenIndex123
+		l367:
+			position, tokenIndex = position367, tokenIndex367
 			return false
 		},
-		/* 33 sp <- <(' ' / '\t')*> */
+		/* 102 sp <- <(' ' / '\t')*> */
 		func() bool {
 			{
-				position126 := position
-			l127:
+				position370 := position
+			l371:
 				{
-					position128, tokenIndex128 := position, tokenIndex
+					position372, tokenIndex372 := position, tokenIndex
 					{
-						position129, tokenIndex129 := position, tokenIndex
+						position373, tokenIndex373 := position, tokenIndex
 						if buffer[position] != rune(' ') {
-							goto l130
+							goto l374
 						}
 						position++
-						goto l129
-					l130:
-						position, tokenIndex = position129, tokenIndex129
+						goto l373
+					l374:
+						position, tokenIndex = position373, tokenIndex373
 						if buffer[position] != rune('\t') {
-							goto l128
+							goto l372
 						}
 						position++
 					}
-				l129:
-					goto l127
-				l128:
-					position, tokenIndex = position128, tokenIndex128
+				l373:
+					goto l371
+				l372:
+					position, tokenIndex = position372, tokenIndex372
 				}
-				add(rulesp, position126)
+				add(rulesp, position370)
 			}
 			return true
 		},
-		/* 34 row <- <(';' sp)> */
+		/* 103 row <- <(';' sp)> */
 		func() bool {
-			position131, tokenIndex131 := position, tokenIndex
+			position375, tokenIndex375 := position, tokenIndex
 			{
-				position132 := position
+				position376 := position
 				if buffer[position] != rune(';') {
-					goto l131
+					goto l375
 				}
 				position++
 				if !_rules[rulesp]() {
-					goto l131
+					goto l375
 				}
-				add(rulerow, position132)
+				add(rulerow, position376)
 			}
 			return true
-		l131:
-			position, tokenIndex = position131, tokenIndex131
+		l375:
+			position, tokenIndex = position375, tokenIndex375
 			return false
 		},
 	}
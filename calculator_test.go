@@ -0,0 +1,269 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// evaluate parses and evaluates the expression in the environment env
+func evaluate(env *Environment, expression string, options ...func(*Calculator) error) (Value, error) {
+	calc := &Calculator{Buffer: expression}
+	if err := calc.Init(append([]func(*Calculator) error{Env(env)}, options...)...); err != nil {
+		return Value{}, err
+	}
+	if err := calc.Parse(); err != nil {
+		return Value{}, err
+	}
+	return calc.EvalE()
+}
+
+// parse converts the expression into a tree without evaluating it
+func parse(t *testing.T, expression string) *Node {
+	t.Helper()
+	calc := &Calculator{Buffer: expression}
+	calc.Init(Env(NewEnvironment()), Prec(DefaultPrec))
+	if err := calc.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	for node := calc.AST().up; node != nil; node = node.next {
+		if node.pegRule == rulee1 {
+			n, err := calc.expression(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+	}
+	t.Fatalf("%s isn't an expression", expression)
+	return nil
+}
+
+// format is the string form of a value
+func format(value Value) string {
+	switch value.ValueType {
+	case ValueTypeMatrix:
+		return value.Matrix.String()
+	case ValueTypeExpression:
+		return value.Expression.String()
+	case ValueTypeRule:
+		return value.Rule.String()
+	}
+	return ""
+}
+
+// test is an expression and the string form of its value
+type test struct {
+	expression, result string
+}
+
+// run evaluates each of the expressions in a new environment and compares the
+// results
+func run(t *testing.T, tests []test) {
+	t.Helper()
+	for _, test := range tests {
+		value, err := evaluate(NewEnvironment(), test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if result := format(value); result != test.result {
+			t.Errorf("%s = %s, want %s", test.expression, result, test.result)
+		}
+	}
+}
+
+// runSession evaluates the expressions in order in one environment and
+// compares the results
+func runSession(t *testing.T, tests []test) {
+	t.Helper()
+	env := NewEnvironment()
+	for _, test := range tests {
+		value, err := evaluate(env, test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if result := format(value); result != test.result {
+			t.Errorf("%s = %s, want %s", test.expression, result, test.result)
+		}
+	}
+}
+
+// errorTest is an expression and the error it causes
+type errorTest struct {
+	expression string
+	errorType  ErrorType
+	begin, end int
+}
+
+// runErrors evaluates each of the expressions in a new environment and
+// compares the errors
+func runErrors(t *testing.T, tests []errorTest) {
+	t.Helper()
+	for _, test := range tests {
+		value, err := evaluate(NewEnvironment(), test.expression)
+		if err == nil {
+			t.Errorf("%s = %s, want an error", test.expression, format(value))
+			continue
+		}
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: %v is not an *Error", test.expression, err)
+			continue
+		}
+		if e.ErrorType != test.errorType {
+			t.Errorf("%s: %v, want %v", test.expression, err, test.errorType)
+		}
+		if test.begin != test.end && (e.Begin != test.begin || e.End != test.end) {
+			t.Errorf("%s: span %d - %d, want %d - %d", test.expression, e.Begin, e.End, test.begin, test.end)
+		}
+	}
+}
+
+func TestEvalE(t *testing.T) {
+	run(t, []test{
+		{"1 + 2*3", "7"},
+		{"2^10", "1024"},
+		{"7 % 3", "1"},
+		{"1e3 + 1", "1001"},
+		{"[1 2; 3 4] * [1; 1]", "[3;7]"},
+		{"sqrt(-4)", "0 + 2i"},
+	})
+}
+
+func TestEvalEErrors(t *testing.T) {
+	runErrors(t, []errorTest{
+		{"[1 2] + [1 2 3]", ErrorTypeDimension, 0, 15},
+		{"1/0", ErrorTypeDivisionByZero, 0, 3},
+		{"1 + y", ErrorTypeUnknownIdentifier, 4, 5},
+		{"7.5 % 2", ErrorTypeNonInteger, 0, 7},
+		{"2 * (7 % 0)", ErrorTypeDivisionByZero, 5, 10},
+	})
+}
+
+func TestEvalPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Eval of 1/0 didn't panic")
+		}
+	}()
+	calc := &Calculator{Buffer: "1/0"}
+	calc.Init()
+	if err := calc.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	calc.Eval()
+}
+
+func TestPrec(t *testing.T) {
+	calc := &Calculator{Buffer: "prec(64)"}
+	calc.Init()
+	if err := calc.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calc.EvalE(); err != nil {
+		t.Fatal(err)
+	}
+	if calc.Prec != 64 {
+		t.Errorf("prec(64) set the precision to %d", calc.Prec)
+	}
+
+	calc = &Calculator{Buffer: "withprec(20, pi)"}
+	calc.Init(Prec(128))
+	if err := calc.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	value, err := calc.EvalE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := format(value); result != "3.141593933" {
+		t.Errorf("withprec(20, pi) = %s, want 3.141593933", result)
+	}
+	if calc.Prec != 128 {
+		t.Errorf("withprec changed the precision to %d", calc.Prec)
+	}
+
+	if err := (&Calculator{}).Init(Prec(0)); err == nil {
+		t.Error("Prec(0) didn't fail")
+	}
+	runErrors(t, []errorTest{
+		{"withprec(0, pi)", ErrorTypeNonInteger, 9, 10},
+		{"withprec(2.5, 1)", ErrorTypeNonInteger, 9, 12},
+	})
+}
+
+// TestConcurrent evaluates with calculators of different precisions in
+// parallel, run it with -race
+func TestConcurrent(t *testing.T) {
+	expressions := []string{
+		"pi",
+		"exp(1)",
+		"sin(1) + cos(1)",
+		"log(2)",
+		"gamma(1/3)",
+		"withprec(300, sqrt(2))",
+	}
+	precs := []uint{53, 256, 1024, 2048}
+	want := make(map[string]string)
+	key := func(expression string, prec uint) string {
+		return fmt.Sprintf("%s@%d", expression, prec)
+	}
+	for _, prec := range precs {
+		for _, expression := range expressions {
+			value, err := evaluate(NewEnvironment(), expression, Prec(prec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want[key(expression, prec)] = format(value)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(precs)*len(expressions)*4)
+	for i := 0; i < 4; i++ {
+		for _, prec := range precs {
+			wg.Add(1)
+			go func(prec uint) {
+				defer wg.Done()
+				for _, expression := range expressions {
+					value, err := evaluate(NewEnvironment(), expression, Prec(prec))
+					if err != nil {
+						errs <- err
+					} else if result := format(value); result != want[key(expression, prec)] {
+						errs <- fmt.Errorf("%s at %d bits = %s, want %s",
+							expression, prec, result, want[key(expression, prec)])
+					}
+				}
+			}(prec)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	runSession(t, []test{
+		{"x = 3", "3"},
+		{"y = [1 2]", "[1 2]"},
+		{"x*y + 1", "[4 7]"},
+		{"x = x + 1", "4"},
+		{"x", "4"},
+	})
+	runErrors(t, []errorTest{
+		{"x + 1", ErrorTypeUnknownIdentifier, 0, 1},
+		{"e = 1", ErrorTypeDomain, 0, 1},
+		{"pi = 3", ErrorTypeDomain, 0, 2},
+		{"inf = 5", ErrorTypeDomain, 0, 3},
+		{"sin = 2", ErrorTypeDomain, 0, 3},
+		{"gcd = 1", ErrorTypeDomain, 0, 3},
+	})
+}
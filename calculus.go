@@ -0,0 +1,203 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// newMatrix creates a matrix of expressions
+func newMatrix(rows [][]*Node) *Node {
+	return &Node{
+		Operation: OperationMatrix,
+		Rows:      rows,
+	}
+}
+
+// Elements returns the elements of a matrix of expressions in row major
+// order, or the expression itself if it isn't a matrix
+func (n *Node) Elements() []*Node {
+	if n.Operation != OperationMatrix {
+		return []*Node{n}
+	}
+	var elements []*Node
+	for _, row := range n.Rows {
+		elements = append(elements, row...)
+	}
+	return elements
+}
+
+// matrix tests if the expression contains a matrix, the products of matrices
+// don't commute and the other operations on matrices are elementwise
+func matrix(n *Node) bool {
+	if n == nil {
+		return false
+	} else if n.Operation == OperationMatrix {
+		return true
+	}
+	for _, argument := range n.Arguments {
+		if matrix(argument) {
+			return true
+		}
+	}
+	return matrix(n.Left) || matrix(n.Right)
+}
+
+// DerivativeN takes the nth partial derivative of the equation with respect
+// to the variable name, simplifying after each step, or nil if a derivative
+// isn't supported
+func (n *Node) DerivativeN(name string, order int) *Node {
+	a := n
+	for i := 0; i < order; i++ {
+		if a = a.DerivativeWith(name); a == nil {
+			return nil
+		}
+		a = a.Simplify()
+	}
+	return a
+}
+
+// Gradient returns the column vector of the partial derivatives of the
+// equation with respect to each of the variables, or nil if a derivative isn't
+// supported
+func (n *Node) Gradient(variables []string) *Node {
+	rows := make([][]*Node, len(variables))
+	for i, variable := range variables {
+		a := n.DerivativeWith(variable)
+		if a == nil {
+			return nil
+		}
+		rows[i] = []*Node{a.Simplify()}
+	}
+	return newMatrix(rows)
+}
+
+// Jacobian returns the matrix of the partial derivatives of the elements of
+// a vector of equations, with a row for each equation and a column for each
+// of the variables, or nil if a derivative isn't supported
+func (n *Node) Jacobian(variables []string) *Node {
+	elements := n.Elements()
+	rows := make([][]*Node, len(elements))
+	for i, element := range elements {
+		for _, variable := range variables {
+			a := element.DerivativeWith(variable)
+			if a == nil {
+				return nil
+			}
+			rows[i] = append(rows[i], a.Simplify())
+		}
+	}
+	return newMatrix(rows)
+}
+
+// Hessian returns the matrix of the second partial derivatives of the
+// equation with respect to each pair of the variables, or nil if a derivative
+// isn't supported
+func (n *Node) Hessian(variables []string) *Node {
+	gradient := n.Gradient(variables)
+	if gradient == nil {
+		return nil
+	}
+	return gradient.Jacobian(variables)
+}
+
+// CheckDerivative compares the symbolic derivative of the equation with
+// respect to the variable name at the point with a finite difference computed
+// at a higher precision than prec, looking up the other variables and
+// functions in env. The result is a row of the symbolic derivative, the
+// finite difference and their relative difference.
+func (n *Node) CheckDerivative(env *Environment, name string, point *complex.Rational, prec uint) (*complex.Matrix, error) {
+	if err := checkPrec(n, prec); err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	return n.checkDerivative(env, name, point, prec)
+}
+
+// checkDerivative compares the symbolic derivative with a finite difference,
+// the caller must hold a read lock on piLock
+func (n *Node) checkDerivative(env *Environment, name string, point *complex.Rational, prec uint) (*complex.Matrix, error) {
+	derivative := n.DerivativeWith(name)
+	if derivative == nil {
+		return nil, newNodeError(ErrorTypeValue, n, "the derivative of %s is not supported", n.String())
+	}
+	derivative = derivative.Simplify()
+	// the rounding error of the difference is divided by the step
+	wp := 2*prec + guard
+	reservePI(wp)
+	scope := env.child()
+	at := func(e *Node, x *complex.Rational) (*complex.Rational, error) {
+		scope.values[name] = Value{
+			ValueType: ValueTypeMatrix,
+			Matrix:    newScalar(wp, x),
+		}
+		y, err := e.evaluate(scope, wp, 0)
+		if err != nil {
+			return nil, err
+		} else if !isScalar(y) {
+			return nil, newNodeError(ErrorTypeDimension, e, "the equation must be a 1x1 matrix")
+		}
+		return &y.Values[0][0], nil
+	}
+	symbolic, err := at(derivative, point)
+	if err != nil {
+		return nil, err
+	}
+	// the central difference (f(x + h) - f(x - h)) / 2h
+	central := func(h *big.Rat) (*complex.Rational, error) {
+		step := complex.NewRational(h, new(big.Rat))
+		x := complex.NewRational(new(big.Rat), new(big.Rat))
+		a, err := at(n, x.Add(point, step))
+		if err != nil {
+			return nil, err
+		}
+		a = complex.NewRational(new(big.Rat).Set(a.A), new(big.Rat).Set(a.B))
+		b, err := at(n, x.Sub(point, step))
+		if err != nil {
+			return nil, err
+		}
+		a.Sub(a, b)
+		twice := new(big.Rat).Add(h, h)
+		a.A.Quo(a.A, twice)
+		a.B.Quo(a.B, twice)
+		return a, nil
+	}
+	// Richardson extrapolation (4 D(h/2) - D(h)) / 3 has an error of h^4
+	h := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), prec/2))
+	d1, err := central(h)
+	if err != nil {
+		return nil, err
+	}
+	d2, err := central(new(big.Rat).Quo(h, big.NewRat(2, 1)))
+	if err != nil {
+		return nil, err
+	}
+	four, three := big.NewRat(4, 1), big.NewRat(3, 1)
+	difference := complex.NewRational(new(big.Rat).Mul(d2.A, four), new(big.Rat).Mul(d2.B, four))
+	difference.Sub(difference, d1)
+	difference.A.Quo(difference.A, three)
+	difference.B.Quo(difference.B, three)
+
+	// |symbolic - difference| / max(|symbolic|, 1)
+	s, d := toFloat(symbolic, prec), toFloat(difference, prec)
+	scale := magnitude(s, prec)
+	if scale.Cmp(big.NewFloat(1)) < 0 {
+		scale.SetInt64(1)
+	}
+	e := magnitude(newComplex(prec).Sub(s, d), prec)
+	e.Quo(e, scale)
+	relative := complex.NewRational(new(big.Rat), new(big.Rat))
+	e.Rat(relative.A)
+
+	m := complex.NewMatrix(prec)
+	m.Values = [][]complex.Rational{{*toRational(s), *toRational(d), *relative}}
+	return &m, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+func TestCalculus(t *testing.T) {
+	run(t, []test{
+		{"derivative(x^3, x, 0)", "x^3"},
+		{"derivative(x^3, x, 2)", "6 * x"},
+		{"derivative(x^3, x, 4)", "0"},
+		{"gradient(x^2*y, [x; y])", "[(2 * x * y);x^2]"},
+		{"jacobian([x*y; x+y], [x; y])", "[y x;1 1]"},
+		{"hessian(x^2*y, [x; y])", "[(2 * y) (2 * x);(2 * x) 0]"},
+		{"eval(hessian(x^3*y, [x; y]), x=1, y=2)", "[12 3;3 0]"},
+		// the products of matrices don't commute
+		{"derivative([y 1]*[y; 1])", "[1 0] * [y;1] + [y 1] * [1;0]"},
+		{"eval(derivative([y 1]*[y; 1]), y=1)", "2"},
+		{"eval(derivative([y 1; 0 y]*[1 y; y 0]), y=2)", "[2 4;4 0]"},
+		{"derivative(3*[y y^2])", "3 * [1 (2 * y)]"},
+	})
+	runErrors(t, []errorTest{
+		// the chain rules of the elementwise operations aren't matrix
+		// products
+		{"derivative([y 2]^2)", ErrorTypeValue, 0, 19},
+		{"derivative(sin([y 2]))", ErrorTypeValue, 0, 22},
+		{"derivative(x^3, x, 1.5)", ErrorTypeNonInteger, 19, 22},
+		{"derivative(x^3, x, 65)", ErrorTypeNonInteger, 19, 21},
+		{"gradient(x, [x; x])", ErrorTypeDomain, 12, 18},
+	})
+}
+
+// checkDerivatives evaluates each of the checkderivative expressions and tests
+// that the symbolic derivative agrees with the finite difference
+func checkDerivatives(t *testing.T, expressions []string) {
+	t.Helper()
+	for _, expression := range expressions {
+		value, err := evaluate(NewEnvironment(), expression)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		m := value.Matrix
+		relative := complex.NewMatrix(m.Prec)
+		relative.Values = [][]complex.Rational{{m.Values[0][2]}}
+		if !negligible(&relative, 40) {
+			t.Errorf("%s = %s, the derivatives differ", expression, m.String())
+		}
+	}
+}
+
+func TestCheckDerivative(t *testing.T) {
+	checkDerivatives(t, []string{
+		"checkderivative(x^x, x, 1/2)",
+		"checkderivative(x^x, x, 3)",
+		"checkderivative(x^sin(x), x, 2)",
+		"checkderivative((x^2 + 1)^(1/x), x, 7/10)",
+		"checkderivative(2^x, x, -1)",
+		"checkderivative(sin(x)*exp(x^2), x, 3/10)",
+		"checkderivative(sqrt(1 - x^2)/(x + 2), x, 1/3)",
+		"checkderivative(atan2(x, 2), x, 1)",
+		"checkderivative(log(x, 2)*x^3, x, 2)",
+		"checkderivative(x^x, x, 1 + 1i)",
+	})
+	run(t, []test{
+		{"derivative(x^x)", "(log(x) + 1) * x^x"},
+		{"derivative(x % 3)", "1"},
+		{"derivative(7 % x)", "-((-(7 % x) + 7) / x)"},
+	})
+	runErrors(t, []errorTest{
+		{"checkderivative(y*x, x, 1)", ErrorTypeUnknownIdentifier, 0, 0},
+		{"checkderivative(x % 3, x, 1/2)", ErrorTypeNonInteger, 0, 0},
+		{"checkderivative(y, e, 1)", ErrorTypeValue, 19, 20},
+	})
+
+	n := parse(t, "x^x")
+	point := complex.NewRational(big.NewRat(1, 2), new(big.Rat))
+	m, err := n.CheckDerivative(nil, "x", point, DefaultPrec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative := complex.NewMatrix(m.Prec)
+	relative.Values = [][]complex.Rational{{m.Values[0][2]}}
+	if !negligible(&relative, 40) {
+		t.Errorf("CheckDerivative of %s = %s, the derivatives differ", n, m.String())
+	}
+	if _, err := n.CheckDerivative(nil, "x", point, 0); err == nil {
+		t.Error("CheckDerivative at zero precision didn't fail")
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// maxExpansion is the largest number of terms which an expansion produces
+const maxExpansion = 4096
+
+// isUnit tests if the expression is the imaginary unit
+func isUnit(n *Node) bool {
+	if n.Operation != OperationImaginary {
+		return false
+	}
+	r, ok := number(n)
+	return ok && r.B.Cmp(big.NewRat(1, 1)) == 0
+}
+
+// rank orders the kinds of factors, constants come before variables,
+// variables come before the other expressions and matrices come last
+func rank(n *Node) int {
+	if matrix(n) {
+		return 4
+	}
+	switch n.Operation {
+	case OperationImaginary:
+		return 0
+	case OperationNumber, OperationNotation, OperationNatural, OperationPI, OperationInfinity:
+		return 1
+	case OperationVariable:
+		return 2
+	}
+	return 3
+}
+
+// less orders the bases of factors, the matrices keep their order
+func less(a, b *Node) bool {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	} else if ra == 4 {
+		return false
+	}
+	return a.String() < b.String()
+}
+
+// apply copies the node with the function applied to its children
+func (n *Node) apply(f func(n *Node) *Node) *Node {
+	a := *n
+	a.Left = nil
+	if n.Left != nil {
+		a.Left = f(n.Left)
+	}
+	// the variable of an integral is bound
+	if n.Right != nil && n.Operation != OperationIntegral {
+		a.Right = f(n.Right)
+	}
+	if n.Arguments != nil {
+		a.Arguments = make([]*Node, len(n.Arguments))
+		for i, argument := range n.Arguments {
+			a.Arguments[i] = f(argument)
+		}
+	}
+	if n.Rows != nil {
+		a.Rows = make([][]*Node, len(n.Rows))
+		for i, row := range n.Rows {
+			a.Rows[i] = make([]*Node, len(row))
+			for j, element := range row {
+				a.Rows[i][j] = f(element)
+			}
+		}
+	}
+	return &a
+}
+
+// normalize removes the factors with a zero exponent, reduces the powers of
+// the imaginary unit and sorts the factors
+func (p *product) normalize() {
+	var factors []factor
+	for _, f := range p.factors {
+		if f.exponent.Sign() == 0 {
+			continue
+		}
+		if isUnit(f.base) && f.exponent.IsInt() {
+			k := new(big.Int).Mod(f.exponent.Num(), big.NewInt(4)).Int64()
+			if k >= 2 {
+				// i^2 = -1
+				p.coefficient = new(big.Rat).Neg(p.coefficient)
+				k -= 2
+			}
+			if k == 0 {
+				continue
+			}
+			f = factor{
+				base:     f.base,
+				exponent: big.NewRat(1, 1),
+			}
+		}
+		factors = append(factors, f)
+	}
+	sort.SliceStable(factors, func(i, j int) bool {
+		return less(factors[i].base, factors[j].base)
+	})
+	p.factors = factors
+}
+
+// key identifies the factors of a normalized product
+func (p *product) key() string {
+	var b strings.Builder
+	for _, f := range p.factors {
+		b.WriteString(f.base.String())
+		b.WriteString("^")
+		b.WriteString(f.exponent.RatString())
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// degree is the sum of the exponents of the factors other than the
+// imaginary unit
+func (p *product) degree() *big.Rat {
+	d := new(big.Rat)
+	for _, f := range p.factors {
+		if !isUnit(f.base) {
+			d.Add(d, f.exponent)
+		}
+	}
+	return d
+}
+
+// imaginary tests if the product has the imaginary unit as a factor
+func (p *product) imaginary() bool {
+	for _, f := range p.factors {
+		if isUnit(f.base) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare orders normalized products by decreasing degree and then
+// lexicographically by their factors, the real products come first
+func (p *product) compare(q *product) int {
+	if c := q.degree().Cmp(p.degree()); c != 0 {
+		return c
+	}
+	a, b := p.factors, q.factors
+	if p.imaginary() {
+		a = a[1:]
+	}
+	if q.imaginary() {
+		b = b[1:]
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if x, y := a[i].base.String(), b[i].base.String(); x != y {
+			if less(a[i].base, b[i].base) {
+				return -1
+			}
+			return 1
+		} else if c := b[i].exponent.Cmp(a[i].exponent); c != 0 {
+			return c
+		}
+	}
+	if len(a) != len(b) {
+		if len(a) > len(b) {
+			return -1
+		}
+		return 1
+	}
+	if p.imaginary() == q.imaginary() {
+		return 0
+	} else if q.imaginary() {
+		return -1
+	}
+	return 1
+}
+
+// times computes the product of two products
+func (p *product) times(q *product) *product {
+	a := &product{
+		coefficient: new(big.Rat).Mul(p.coefficient, q.coefficient),
+	}
+	for _, f := range p.factors {
+		a.insert(f.base, f.exponent)
+	}
+	for _, f := range q.factors {
+		a.insert(f.base, f.exponent)
+	}
+	a.normalize()
+	return a
+}
+
+// power raises the product to the rational power e, integer powers are
+// distributed over the factors while other powers are only distributed over a
+// single factor. On the principal branch (x^f)^e = x^(f e) for an integer e,
+// otherwise only if -1 < f <= 1, so that sqrt(x^2) isn't x. The powers of
+// matrices are elementwise so they aren't distributed over matrix products.
+func (p *product) power(e *big.Rat) (*product, bool) {
+	for _, f := range p.factors {
+		if matrix(f.base) {
+			return nil, false
+		}
+	}
+	if !e.IsInt() && (len(p.factors) != 1 || p.coefficient.Cmp(big.NewRat(1, 1)) != 0 ||
+		isUnit(p.factors[0].base) || p.factors[0].exponent.Cmp(big.NewRat(-1, 1)) <= 0 ||
+		p.factors[0].exponent.Cmp(big.NewRat(1, 1)) > 0) {
+		return nil, false
+	}
+	coefficient, ok := big.NewRat(1, 1), true
+	if e.IsInt() {
+		coefficient, ok = exponentiate(p.coefficient, e)
+	}
+	if !ok {
+		return nil, false
+	}
+	a := &product{
+		coefficient: coefficient,
+	}
+	for _, f := range p.factors {
+		a.insert(f.base, new(big.Rat).Mul(f.exponent, e))
+	}
+	a.normalize()
+	return a, true
+}
+
+// sum is a sum of normalized products with distinct factors
+type sum struct {
+	terms []*product
+	index map[string]int
+}
+
+// newSum creates an empty sum
+func newSum() *sum {
+	return &sum{
+		index: make(map[string]int),
+	}
+}
+
+// add adds the product to the sum, merging the coefficients of the products
+// with the same factors
+func (s *sum) add(p *product) {
+	p.normalize()
+	key := p.key()
+	if i, ok := s.index[key]; ok {
+		t := s.terms[i]
+		t.coefficient = new(big.Rat).Add(t.coefficient, p.coefficient)
+		return
+	}
+	s.index[key] = len(s.terms)
+	s.terms = append(s.terms, &product{
+		coefficient: new(big.Rat).Set(p.coefficient),
+		factors:     p.factors,
+	})
+}
+
+// plus adds the terms of the sum t scaled by c to the sum
+func (s *sum) plus(t *sum, c *big.Rat) {
+	for _, p := range t.terms {
+		s.add(&product{
+			coefficient: new(big.Rat).Mul(p.coefficient, c),
+			factors:     p.factors,
+		})
+	}
+}
+
+// nonzero returns the terms with a non zero coefficient
+func (s *sum) nonzero() []*product {
+	var terms []*product
+	for _, p := range s.terms {
+		if p.coefficient.Sign() != 0 {
+			terms = append(terms, p)
+		}
+	}
+	return terms
+}
+
+// multiply computes the product of two sums, ok is false if the product has
+// too many terms
+func (s *sum) multiply(t *sum) (a *sum, ok bool) {
+	x, y := s.nonzero(), t.nonzero()
+	if len(x)*len(y) > maxExpansion {
+		return nil, false
+	}
+	a = newSum()
+	for _, p := range x {
+		for _, q := range y {
+			a.add(p.times(q))
+		}
+	}
+	return a, true
+}
+
+// single converts the sum into a product, a sum of several terms becomes a
+// factor
+func (s *sum) single() *product {
+	terms := s.nonzero()
+	switch len(terms) {
+	case 0:
+		return &product{
+			coefficient: new(big.Rat),
+		}
+	case 1:
+		return terms[0]
+	}
+	return &product{
+		coefficient: big.NewRat(1, 1),
+		factors: []factor{{
+			base:     s.Node(),
+			exponent: big.NewRat(1, 1),
+		}},
+	}
+}
+
+// addition adds the expression b to the expression a, subtracting it if it
+// is negated
+func addition(a, b *Node) *Node {
+	if a == nil {
+		return b
+	} else if b.Operation == OperationNegate {
+		return &Node{
+			Operation: OperationSubtract,
+			Left:      a,
+			Right:     b.Left,
+		}
+	}
+	return &Node{
+		Operation: OperationAdd,
+		Left:      a,
+		Right:     b,
+	}
+}
+
+// Node converts the sum into an expression with the terms in order
+func (s *sum) Node() *Node {
+	terms := s.nonzero()
+	if len(terms) == 0 {
+		return newRational(new(big.Rat))
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].compare(terms[j]) < 0
+	})
+	var a *Node
+	for _, p := range terms {
+		a = addition(a, p.Node())
+	}
+	return a
+}
+
+// converter converts expressions into sums of products, the sums of the
+// shared sub-expressions of a DAG are only computed once
+type converter struct {
+	expand bool
+	shared map[*Node]bool
+	sums   map[*Node]*sum
+}
+
+// polynomial converts the expression into a sum of products, if expand is
+// true the products and powers of sums are multiplied out
+func polynomial(n *Node, expand bool) *sum {
+	cv := &converter{
+		expand: expand,
+		shared: n.shared(),
+		sums:   make(map[*Node]*sum),
+	}
+	return cv.convert(n)
+}
+
+// convert converts the expression into a sum of products, the caller owns
+// the sum
+func (cv *converter) convert(n *Node) *sum {
+	if !cv.shared[n] {
+		return cv.polynomial(n)
+	}
+	s, ok := cv.sums[n]
+	if !ok {
+		s = cv.polynomial(n)
+		cv.sums[n] = s
+	}
+	a := newSum()
+	a.plus(s, big.NewRat(1, 1))
+	return a
+}
+
+// polynomial converts the expression into a sum of products
+func (cv *converter) polynomial(n *Node) *sum {
+	s := newSum()
+	switch n.Operation {
+	case OperationAdd, OperationSubtract:
+		a, b := cv.convert(n.Left), cv.convert(n.Right)
+		c := big.NewRat(1, 1)
+		if n.Operation == OperationSubtract {
+			c.Neg(c)
+		}
+		a.plus(b, c)
+		return a
+	case OperationNegate:
+		s.plus(cv.convert(n.Left), big.NewRat(-1, 1))
+		return s
+	case OperationMultiply:
+		a, b := cv.convert(n.Left), cv.convert(n.Right)
+		if cv.expand {
+			if c, ok := a.multiply(b); ok {
+				return c
+			}
+		}
+		s.add(a.single().times(b.single()))
+		return s
+	case OperationDivide:
+		a, b := cv.convert(n.Left), cv.convert(n.Right).single()
+		if inverse, ok := b.power(big.NewRat(-1, 1)); ok {
+			if cv.expand {
+				c := newSum()
+				c.add(inverse)
+				if c, ok := a.multiply(c); ok {
+					return c
+				}
+			}
+			s.add(a.single().times(inverse))
+			return s
+		}
+	case OperationExponentiation, OperationSquareRoot:
+		e, ok := big.NewRat(1, 2), true
+		if n.Operation == OperationExponentiation {
+			e, ok = rational(n.Right)
+		}
+		if !ok {
+			break
+		}
+		base := cv.convert(n.Left)
+		if cv.expand && !matrix(n.Left) && e.IsInt() && e.Sign() != 0 && e.Num().IsInt64() &&
+			len(base.nonzero()) > 1 && e.Num().Int64() <= maxExpansion {
+			a := newSum()
+			a.add(&product{
+				coefficient: big.NewRat(1, 1),
+			})
+			for i := int64(0); ok && i < abs(e.Num().Int64()); i++ {
+				a, ok = a.multiply(base)
+			}
+			if ok {
+				if e.Sign() > 0 {
+					return a
+				}
+				s.add(&product{
+					coefficient: big.NewRat(1, 1),
+					factors: []factor{{
+						base:     a.Node(),
+						exponent: big.NewRat(-1, 1),
+					}},
+				})
+				return s
+			}
+		}
+		b := base.single()
+		if p, ok := b.power(e); ok {
+			s.add(p)
+			return s
+		}
+		s.add(&product{
+			coefficient: big.NewRat(1, 1),
+			factors: []factor{{
+				base:     b.Node(),
+				exponent: e,
+			}},
+		})
+		return s
+	}
+	if r, ok := number(n); ok {
+		s.add(&product{
+			coefficient: r.A,
+		})
+		if r.B.Sign() != 0 {
+			s.add(&product{
+				coefficient: r.B,
+				factors: []factor{{
+					base: &Node{
+						Operation: OperationImaginary,
+						Value:     "1",
+					},
+					exponent: big.NewRat(1, 1),
+				}},
+			})
+		}
+		return s
+	}
+	s.add(&product{
+		coefficient: big.NewRat(1, 1),
+		factors: []factor{{
+			base: n.apply(func(n *Node) *Node {
+				return cv.convert(n).Node()
+			}),
+			exponent: big.NewRat(1, 1),
+		}},
+	})
+	return s
+}
+
+// canonical converts the expression into a canonical sum of products, the
+// sums and products are flattened, the terms and the factors are sorted and
+// the coefficients and the exponents of equal terms and factors are merged
+func (n *Node) canonical() *Node {
+	return polynomial(n, false).Node()
+}
+
+// Expand multiplies out the products and the integer powers of sums in the
+// expression
+func (n *Node) Expand() *Node {
+	return polynomial(n, true).Node()
+}
+
+// Collect expands the expression and then gathers the terms with the same
+// power of the variable name, the powers are in decreasing order
+func (n *Node) Collect(name string) *Node {
+	var exponents []*big.Rat
+	groups := make(map[string]*sum)
+	for _, p := range polynomial(n, true).nonzero() {
+		e, rest := new(big.Rat), &product{
+			coefficient: p.coefficient,
+		}
+		for _, f := range p.factors {
+			if f.base.Operation == OperationVariable && f.base.Value == name {
+				e = f.exponent
+				continue
+			}
+			rest.factors = append(rest.factors, f)
+		}
+		key := e.RatString()
+		if _, ok := groups[key]; !ok {
+			groups[key] = newSum()
+			exponents = append(exponents, e)
+		}
+		groups[key].add(rest)
+	}
+	sort.Slice(exponents, func(i, j int) bool {
+		return exponents[i].Cmp(exponents[j]) > 0
+	})
+	var a *Node
+	for _, e := range exponents {
+		x := factor{
+			base: &Node{
+				Operation: OperationVariable,
+				Value:     name,
+			},
+			exponent: e,
+		}
+		coefficient := groups[e.RatString()]
+		var p *product
+		if terms := coefficient.nonzero(); len(terms) == 0 {
+			continue
+		} else if len(terms) == 1 {
+			p = terms[0]
+			p.insert(x.base, x.exponent)
+			p.normalize()
+		} else {
+			p = &product{
+				coefficient: big.NewRat(1, 1),
+				factors: []factor{{
+					base:     coefficient.Node(),
+					exponent: big.NewRat(1, 1),
+				}, x},
+			}
+		}
+		a = addition(a, p.Node())
+	}
+	if a == nil {
+		return newRational(new(big.Rat))
+	}
+	return a
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	run(t, []test{
+		{"simplify(x + x)", "2 * x"},
+		{"simplify(x*x)", "x^2"},
+		{"simplify(y*x + x*y)", "2 * x * y"},
+		{"simplify(b + a + 2*a)", "3 * a + b"},
+		{"simplify(3*x - x*3)", "0"},
+		{"simplify(x^(1/2)*x^(1/2))", "x"},
+		{"simplify((x^2)^3)", "x^6"},
+		{"simplify((x^(1/2))^2)", "x"},
+		{"simplify((x^(1/3))^(3/5))", "x^(1 / 5)"},
+		{"simplify(sqrt(x^2))", "sqrt(x^2)"},
+		{"simplify((x^3)^(1/3))", "(x^3)^(1 / 3)"},
+		{"simplify((x^-2)^(1/2))", "sqrt(1 / x^2)"},
+		{"expand((x + 1)^3)", "x^3 + 3 * x^2 + 3 * x + 1"},
+		{"expand((x + y)*(x - y))", "x^2 - y^2"},
+		{"expand((1i*x + 1)^2)", "-(x^2) + 2i * x + 1"},
+		{"collect(x*y + x + 2*x^2 + y*x^2, x)", "(y + 2) * x^2 + (y + 1) * x"},
+	})
+	runErrors(t, []errorTest{
+		{"collect(y*e, e)", ErrorTypeValue, 13, 14},
+	})
+}
+
+// TestCanonicalValues checks that simplify and expand don't change the values
+// of expressions, including at negative and complex points where the powers
+// are on the principal branch
+func TestCanonicalValues(t *testing.T) {
+	expressions := []string{
+		"sqrt(x^2)",
+		"(x^2)^(1/2)",
+		"(x^3)^(1/3)",
+		"(x^(1/3))^(3/5)",
+		"(x^(1/2))^2",
+		"(x^-2)^(1/2)",
+		"(1/x)^(1/2)",
+		"sqrt(4*x^2)",
+		"(x + 1)^3 - x^3",
+		"(x^2)^3*x",
+		"x^(1/2)*x^(1/2)",
+	}
+	points := []string{"-3", "-1/2", "2", "1 + 2i", "-1 - 1i"}
+	for _, expression := range expressions {
+		for _, operation := range []string{"simplify", "expand"} {
+			for _, point := range points {
+				check := fmt.Sprintf("eval(%s(%s), x=%s) - eval(%s, x=%s)", operation, expression, point, expression, point)
+				value, err := evaluate(NewEnvironment(), check)
+				if err != nil {
+					t.Errorf("%s: %v", check, err)
+				} else if !negligible(value.Matrix, 900) {
+					t.Errorf("%s = %s, want 0", check, format(value))
+				}
+			}
+		}
+	}
+}
+
+// TestCanonicalMatrices checks that the products of matrices keep their order
+// and that matrix products aren't merged into elementwise powers
+func TestCanonicalMatrices(t *testing.T) {
+	run(t, []test{
+		{"simplify([y 1; 2 3]*[y 1; 2 3])", "[y 1;2 3] * [y 1;2 3]"},
+		{"simplify([1; 1]*[y 2])", "[1;1] * [y 2]"},
+		{"simplify(y*[1 2]*y)", "y^2 * [1 2]"},
+		{"simplify(2*[y 1]*3)", "6 * [y 1]"},
+		{"expand(([y 1] + [1 1])^2)", "([y 1] + [1 1])^2"},
+		{"eval(simplify([y 2]*[1; 1]), y=1)", "3"},
+		{"eval(simplify([y 1; 2 3]*[y 1; 2 3]), y=1)", "[3 4;8 11]"},
+		{"eval(expand(([y 1; 1 1] + [1 1; 1 1])*([y 1; 1 1] - [1 1; 1 1])), y=2)", "[3 0;2 0]"},
+	})
+	runErrors(t, []errorTest{
+		{"eval(simplify([1 2]*[1 2]), y=1)", ErrorTypeDimension, 0, 0},
+		{"eval(simplify([y 1]^2*[y 1]), y=1)", ErrorTypeDimension, 0, 0},
+	})
+}
@@ -5,7 +5,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/c-bata/go-prompt"
 
@@ -17,37 +19,141 @@ func completer(d prompt.Document) []prompt.Suggest {
 		{Text: "exp", Description: "The natural number raised to a value"},
 		{Text: "e", Description: "The natural number"},
 		{Text: "pi", Description: "The constant PI"},
+		{Text: "inf", Description: "Infinity, the target or the value of a limit"},
 		{Text: "prec", Description: "Sets the precision for calculations"},
+		{Text: "withprec", Description: "Evaluates an expression at a precision"},
 		{Text: "simplify", Description: "Simplifies the expression"},
+		{Text: "expand", Description: "Multiplies out the products and powers of sums"},
+		{Text: "collect", Description: "Gathers the terms with the same power of a variable"},
 		{Text: "derivative", Description: "Computes the symbolic derivative of the expression"},
-		{Text: "log", Description: "The natural logarithm of the input"},
+		{Text: "checkderivative", Description: "Compares the symbolic derivative at a point with a finite difference"},
+		{Text: "gradient", Description: "Computes the gradient of the expression"},
+		{Text: "jacobian", Description: "Computes the jacobian of a vector of expressions"},
+		{Text: "hessian", Description: "Computes the hessian of the expression"},
+		{Text: "integrate", Description: "Computes the symbolic or definite numeric integral of the expression"},
+		{Text: "solve", Description: "Finds a root of the expression near a guess"},
+		{Text: "series", Description: "Computes the Taylor or Laurent series of the expression around a point"},
+		{Text: "limit", Description: "Computes the limit of the expression, optionally from the left or the right"},
+		{Text: "eval", Description: "Evaluates an expression with variables bound to values"},
+		{Text: "log", Description: "The natural logarithm of the input, or the logarithm to a base"},
+		{Text: "log2", Description: "The base 2 logarithm of the input"},
+		{Text: "log10", Description: "The base 10 logarithm of the input"},
+		{Text: "exp2", Description: "Raises 2 to the power of the input"},
 		{Text: "sqrt", Description: "The square root of the value"},
 		{Text: "cos", Description: "The cosine of the value"},
 		{Text: "sin", Description: "The sine of the value"},
 		{Text: "tan", Description: "The tangent of the value"},
+		{Text: "asin", Description: "The inverse sine of the value"},
+		{Text: "acos", Description: "The inverse cosine of the value"},
+		{Text: "atan", Description: "The inverse tangent of the value"},
+		{Text: "atan2", Description: "The angle of the point with the coordinates y and x"},
+		{Text: "sinh", Description: "The hyperbolic sine of the value"},
+		{Text: "cosh", Description: "The hyperbolic cosine of the value"},
+		{Text: "tanh", Description: "The hyperbolic tangent of the value"},
+		{Text: "asinh", Description: "The inverse hyperbolic sine of the value"},
+		{Text: "acosh", Description: "The inverse hyperbolic cosine of the value"},
+		{Text: "atanh", Description: "The inverse hyperbolic tangent of the value"},
+		{Text: "sec", Description: "The secant of the value"},
+		{Text: "csc", Description: "The cosecant of the value"},
+		{Text: "cot", Description: "The cotangent of the value"},
+		{Text: "gamma", Description: "The gamma function of the value"},
+		{Text: "lgamma", Description: "The principal logarithm of the gamma function of the value"},
+		{Text: "beta", Description: "The beta function of two values"},
+		{Text: "erf", Description: "The error function of the value"},
+		{Text: "erfc", Description: "The complementary error function of the value"},
+		{Text: "zeta", Description: "The Riemann zeta function of the value, or its derivative of an order"},
+		{Text: "digamma", Description: "The digamma function of the value"},
+		{Text: "polygamma", Description: "The polygamma function of an order of the value"},
+		{Text: "lambertw", Description: "The Lambert W function of the value on a branch"},
+		{Text: "besselj", Description: "The Bessel function of the first kind of an order of the value"},
+		{Text: "bessely", Description: "The Bessel function of the second kind of an order of the value"},
+		{Text: "besseli", Description: "The modified Bessel function of the first kind of an order of the value"},
+		{Text: "besselk", Description: "The modified Bessel function of the second kind of an order of the value"},
+		{Text: "airyai", Description: "The Airy function Ai of the value"},
+		{Text: "airybi", Description: "The Airy function Bi of the value"},
+		{Text: "airyaiprime", Description: "The derivative of the Airy function Ai of the value"},
+		{Text: "airybiprime", Description: "The derivative of the Airy function Bi of the value"},
+		{Text: "ellipk", Description: "The complete elliptic integral of the first kind of the parameter"},
+		{Text: "ellipe", Description: "The complete elliptic integral of the second kind of the parameter"},
+		{Text: "gcd", Description: "The greatest common divisor of two integers"},
+		{Text: "lcm", Description: "The least common multiple of two integers"},
+		{Text: "isprime", Description: "One if the integer is prime and zero otherwise"},
+		{Text: "nextprime", Description: "The smallest prime greater than the integer"},
+		{Text: "factor", Description: "The prime factors of the integer and their exponents"},
+		{Text: "totient", Description: "The Euler totient of the integer"},
+		{Text: "powmod", Description: "The integer raised to a power modulo a modulus"},
+		{Text: "invmod", Description: "The inverse of the integer modulo a modulus"},
+		{Text: "crt", Description: "The solution of congruences by the Chinese remainder theorem"},
+		{Text: "jacobi", Description: "The Jacobi symbol of two integers"},
+		{Text: "output", Description: "Sets the output mode to pretty, ascii, text, latex or mathml"},
 		{Text: "exit", Description: "Exit the application"},
 	}
 	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
 }
 
+// output modes
+const (
+	outputPretty = "pretty"
+	outputASCII  = "ascii"
+	outputText   = "text"
+	outputLaTeX  = "latex"
+	outputMathML = "mathml"
+)
+
+// format formats a result in the output mode
+func format(result calc.Value, mode string) string {
+	switch {
+	case result.Rule != nil:
+		return result.Rule.String()
+	case result.Matrix != nil && mode == outputLaTeX:
+		return calc.MatrixLaTeX(result.Matrix)
+	case result.Matrix != nil && mode == outputMathML:
+		return calc.MatrixMathML(result.Matrix)
+	case result.Matrix != nil:
+		return result.Matrix.String()
+	case mode == outputLaTeX:
+		return result.Expression.LaTeX()
+	case mode == outputMathML:
+		return result.Expression.MathML()
+	case mode == outputPretty:
+		return result.Expression.Pretty()
+	case mode == outputASCII:
+		return result.Expression.PrettyASCII()
+	}
+	return result.Expression.String()
+}
+
 func main() {
+	mode := flag.String("output", outputPretty, "the output mode: pretty, ascii, text, latex or mathml")
+	flag.Parse()
+
+	prec, env := calc.DefaultPrec, calc.NewEnvironment()
 	for {
 		value := prompt.Input("> ", completer)
 		if value == "exit" {
 			return
+		} else if fields := strings.Fields(value); len(fields) == 2 && fields[0] == "output" {
+			switch fields[1] {
+			case outputPretty, outputASCII, outputText, outputLaTeX, outputMathML:
+				*mode = fields[1]
+			default:
+				fmt.Println("the output mode must be pretty, ascii, text, latex or mathml")
+			}
+			continue
 		}
 
 		cal := &calc.Calculator{Buffer: value}
-		cal.Init()
+		cal.Init(calc.Prec(prec), calc.Env(env))
 		if err := cal.Parse(); err != nil {
 			fmt.Println(err)
 			continue
 		}
-		result := cal.Eval()
-		if result.Matrix != nil {
-			fmt.Printf("%s\n", result.Matrix.String())
-		} else {
-			fmt.Printf("%s\n", result.Expression.String())
+		result, err := cal.EvalE()
+		prec = cal.Prec
+		if err != nil {
+			fmt.Println(err)
+			continue
 		}
+		fmt.Println(format(result, *mode))
 	}
 }
@@ -0,0 +1,245 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// hashes is a memo of the structural hashes of the nodes of an expression
+type hashes map[*Node]uint64
+
+// combine hashes the node from the hashes of its children
+func combine(n *Node, child func(n *Node) uint64) uint64 {
+	h := fnv.New64a()
+	var buffer [8]byte
+	write := func(x uint64) {
+		binary.LittleEndian.PutUint64(buffer[:], x)
+		h.Write(buffer[:])
+	}
+	write(uint64(n.Operation))
+	write(uint64(len(n.Value)))
+	h.Write([]byte(n.Value))
+	write(child(n.Left))
+	write(child(n.Right))
+	write(uint64(len(n.Arguments)))
+	for _, argument := range n.Arguments {
+		write(child(argument))
+	}
+	write(uint64(len(n.Rows)))
+	for _, row := range n.Rows {
+		write(uint64(len(row)))
+		for _, element := range row {
+			write(child(element))
+		}
+	}
+	return h.Sum64()
+}
+
+// hash computes the structural hash of the node, a nil node hashes to zero
+func (h hashes) hash(n *Node) uint64 {
+	if n == nil {
+		return 0
+	} else if x, ok := h[n]; ok {
+		return x
+	}
+	x := combine(n, h.hash)
+	h[n] = x
+	return x
+}
+
+// Hash computes a structural hash of the expression, expressions which are
+// Identical have the same hash
+func (n *Node) Hash() uint64 {
+	return hashes{}.hash(n)
+}
+
+// Identical tests if two expressions have the same structure, the shared
+// sub-expressions of a DAG are only compared once
+func (n *Node) Identical(m *Node) bool {
+	type pair struct {
+		a, b *Node
+	}
+	equal := make(map[pair]bool)
+	var process func(a, b *Node) bool
+	process = func(a, b *Node) bool {
+		if a == b {
+			return true
+		} else if a == nil || b == nil {
+			return false
+		} else if equal[pair{a, b}] {
+			return true
+		}
+		if a.Operation != b.Operation || a.Value != b.Value ||
+			len(a.Arguments) != len(b.Arguments) || len(a.Rows) != len(b.Rows) ||
+			!process(a.Left, b.Left) || !process(a.Right, b.Right) {
+			return false
+		}
+		for i, argument := range a.Arguments {
+			if !process(argument, b.Arguments[i]) {
+				return false
+			}
+		}
+		for i, row := range a.Rows {
+			if len(row) != len(b.Rows[i]) {
+				return false
+			}
+			for j, element := range row {
+				if !process(element, b.Rows[i][j]) {
+					return false
+				}
+			}
+		}
+		equal[pair{a, b}] = true
+		return true
+	}
+	return process(n, m)
+}
+
+// Interner hash conses expressions, identical sub-expressions of the
+// interned expressions are replaced by a single shared node which turns the
+// trees into a DAG. This eliminates the common sub-expressions.
+type Interner struct {
+	buckets map[uint64][]*Node
+	nodes   map[*Node]*Node
+	hashes  hashes
+}
+
+// NewInterner creates an empty interning table
+func NewInterner() *Interner {
+	return &Interner{
+		buckets: make(map[uint64][]*Node),
+		nodes:   make(map[*Node]*Node),
+		hashes:  make(hashes),
+	}
+}
+
+// same tests if two nodes with interned children are the same
+func same(a, b *Node) bool {
+	if a.Operation != b.Operation || a.Value != b.Value || a.Left != b.Left || a.Right != b.Right ||
+		len(a.Arguments) != len(b.Arguments) || len(a.Rows) != len(b.Rows) {
+		return false
+	}
+	for i, argument := range a.Arguments {
+		if argument != b.Arguments[i] {
+			return false
+		}
+	}
+	for i, row := range a.Rows {
+		if len(row) != len(b.Rows[i]) {
+			return false
+		}
+		for j, element := range row {
+			if element != b.Rows[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Intern returns the shared node which is identical to the expression, the
+// interned nodes must not be modified
+func (t *Interner) Intern(n *Node) *Node {
+	if n == nil {
+		return nil
+	} else if a, ok := t.nodes[n]; ok {
+		return a
+	}
+	a := *n
+	a.Left, a.Right = t.Intern(n.Left), t.Intern(n.Right)
+	if n.Arguments != nil {
+		a.Arguments = make([]*Node, len(n.Arguments))
+		for i, argument := range n.Arguments {
+			a.Arguments[i] = t.Intern(argument)
+		}
+	}
+	if n.Rows != nil {
+		a.Rows = make([][]*Node, len(n.Rows))
+		for i, row := range n.Rows {
+			a.Rows[i] = make([]*Node, len(row))
+			for j, element := range row {
+				a.Rows[i][j] = t.Intern(element)
+			}
+		}
+	}
+	x := combine(&a, t.hashes.hash)
+	for _, b := range t.buckets[x] {
+		if same(&a, b) {
+			t.nodes[n] = b
+			return b
+		}
+	}
+	b := &a
+	t.buckets[x] = append(t.buckets[x], b)
+	t.hashes[b] = x
+	t.nodes[n], t.nodes[b] = b, b
+	return b
+}
+
+// Intern converts the expression into a DAG without common sub-expressions
+func (n *Node) Intern() *Node {
+	return NewInterner().Intern(n)
+}
+
+// children calls f for each child of the node
+func (n *Node) children(f func(n *Node)) {
+	if n.Left != nil {
+		f(n.Left)
+	}
+	if n.Right != nil {
+		f(n.Right)
+	}
+	for _, argument := range n.Arguments {
+		f(argument)
+	}
+	for _, row := range n.Rows {
+		for _, element := range row {
+			f(element)
+		}
+	}
+}
+
+// Size counts the distinct nodes of the expression, the shared
+// sub-expressions of a DAG are counted once
+func (n *Node) Size() int {
+	seen := make(map[*Node]bool)
+	var process func(n *Node)
+	process = func(n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		n.children(process)
+	}
+	if n != nil {
+		process(n)
+	}
+	return len(seen)
+}
+
+// shared finds the nodes of the expression which have more than one parent,
+// their values can be computed once
+func (n *Node) shared() map[*Node]bool {
+	parents := make(map[*Node]int)
+	var process func(n *Node)
+	process = func(n *Node) {
+		parents[n]++
+		if parents[n] == 1 {
+			n.children(process)
+		}
+	}
+	if n != nil {
+		process(n)
+	}
+	shared := make(map[*Node]bool)
+	for node, count := range parents {
+		if count > 1 {
+			shared[node] = true
+		}
+	}
+	return shared
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+func TestIdentical(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		identical bool
+	}{
+		{"x^2 + sin(y)", "x^2 + sin(y)", true},
+		{"[x 1; 2 y]", "[x 1; 2 y]", true},
+		{"log(x, 2)", "log(x, 2)", true},
+		{"x + y", "y + x", false},
+		{"x^2", "x^3", false},
+		{"sin(x)", "cos(x)", false},
+		{"log(x, 2)", "log(x, 3)", false},
+		{"[x 1]", "[x; 1]", false},
+	}
+	for _, test := range tests {
+		a, b := parse(t, test.a), parse(t, test.b)
+		if identical := a.Identical(b); identical != test.identical {
+			t.Errorf("%s identical to %s = %t, want %t", test.a, test.b, identical, test.identical)
+		}
+		if test.identical && a.Hash() != b.Hash() {
+			t.Errorf("%s and %s are identical but their hashes differ", test.a, test.b)
+		}
+	}
+}
+
+func TestIntern(t *testing.T) {
+	n := parse(t, "(x + 1)*(x + 1) + sin(x + 1)")
+	if size := n.Size(); size != 12 {
+		t.Errorf("the size of the tree is %d, want 12", size)
+	}
+	a := n.Intern()
+	if size := a.Size(); size != 6 {
+		t.Errorf("the size of the DAG is %d, want 6", size)
+	}
+	if !a.Identical(n) || a.String() != n.String() {
+		t.Errorf("interning changed %s to %s", n, a)
+	}
+	if left := a.Left; left.Left != left.Right || left.Left != a.Right.Left {
+		t.Error("the sub-expressions x + 1 aren't shared")
+	}
+
+	interner := NewInterner()
+	b, c := interner.Intern(parse(t, "x^2 + 1")), interner.Intern(parse(t, "x^2 + 1"))
+	if b != c {
+		t.Error("an interner returned different nodes for identical expressions")
+	}
+}
+
+// TestRepeatedDerivative checks that the repeated derivatives are DAGs which
+// grow polynomially, while their string forms grow exponentially
+func TestRepeatedDerivative(t *testing.T) {
+	d := parse(t, "sin(x^2)*exp(x^2) + (x + 1)*(x + 1)")
+	for i := 1; i <= 6; i++ {
+		d = d.DerivativeWith("x")
+	}
+	if size := d.Size(); size > 1000 {
+		t.Errorf("the size of the sixth derivative is %d, want at most 1000", size)
+	}
+	if length := len(d.String()); length < 100*d.Size() {
+		t.Errorf("the sixth derivative has %d nodes and is %d characters, expected more sharing", d.Size(), length)
+	}
+
+	env := NewEnvironment()
+	env.Set("x", Value{ValueType: ValueTypeMatrix, Matrix: newScalar(DefaultPrec, complex.NewRational(big.NewRat(1, 2), new(big.Rat)))})
+	value, err := d.Evaluate(env, DefaultPrec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := evaluate(NewEnvironment(), "eval(derivative(sin(x^2)*exp(x^2) + (x + 1)*(x + 1), x, 6), x=1/2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := format(Value{ValueType: ValueTypeMatrix, Matrix: value}); result != format(want) {
+		t.Errorf("the sixth derivative at 1/2 = %s, want %s", result, format(want))
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"sort"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// MaxDepth is the maximum depth of nested calls to user defined functions
+const MaxDepth = 64
+
+// Function is a user defined function
+type Function struct {
+	Parameters []string
+	Body       *Node
+}
+
+// Environment is a set of variable and function bindings which persists
+// across evaluations. An environment is not safe for concurrent use.
+type Environment struct {
+	parent    *Environment
+	values    map[string]Value
+	functions map[string]*Function
+	rules     []*Rule
+}
+
+// NewEnvironment creates a new environment
+func NewEnvironment() *Environment {
+	return &Environment{
+		values:    make(map[string]Value),
+		functions: make(map[string]*Function),
+	}
+}
+
+// scope creates a scope for the evaluation of the body of a function, which
+// sees the global bindings but not the bindings of its caller
+func (e *Environment) scope() *Environment {
+	global := e
+	for global.parent != nil {
+		global = global.parent
+	}
+	scope := NewEnvironment()
+	scope.parent = global
+	return scope
+}
+
+// child creates a scope which sees all of the bindings of e
+func (e *Environment) child() *Environment {
+	child := NewEnvironment()
+	child.parent = e
+	return child
+}
+
+// Env sets the environment of the calculator
+func Env(env *Environment) func(*Calculator) error {
+	return func(c *Calculator) error {
+		c.Env = env
+		return nil
+	}
+}
+
+// Set binds a copy of value to name
+func (e *Environment) Set(name string, value Value) {
+	e.values[name] = value.Copy()
+}
+
+// Get returns a copy of the value bound to name
+func (e *Environment) Get(name string) (Value, bool) {
+	for e != nil {
+		if value, ok := e.values[name]; ok {
+			return value.Copy(), true
+		}
+		e = e.parent
+	}
+	return Value{}, false
+}
+
+// Delete removes the binding for name
+func (e *Environment) Delete(name string) {
+	delete(e.values, name)
+}
+
+// Names returns the sorted names of the bindings
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.values))
+	for name := range e.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetFunction binds a function to name
+func (e *Environment) SetFunction(name string, function *Function) {
+	e.functions[name] = function
+}
+
+// GetFunction returns the function bound to name
+func (e *Environment) GetFunction(name string) (*Function, bool) {
+	for e != nil {
+		if function, ok := e.functions[name]; ok {
+			return function, true
+		}
+		e = e.parent
+	}
+	return nil, false
+}
+
+// DeleteFunction removes the function bound to name
+func (e *Environment) DeleteFunction(name string) {
+	delete(e.functions, name)
+}
+
+// Functions returns the sorted names of the functions
+func (e *Environment) Functions() []string {
+	names := make([]string, 0, len(e.functions))
+	for name := range e.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddRule adds a rewrite rule which is used by simplify
+func (e *Environment) AddRule(rule *Rule) {
+	e.rules = append(e.rules, rule)
+}
+
+// Rules returns the rewrite rules in the order they were added
+func (e *Environment) Rules() []*Rule {
+	var rules []*Rule
+	for e != nil {
+		rules = append(e.rules[:len(e.rules):len(e.rules)], rules...)
+		e = e.parent
+	}
+	return rules
+}
+
+// function looks up a function called with arity arguments at the given call depth
+func (e *Environment) function(name string, arity, depth int) (*Function, error) {
+	if depth >= MaxDepth {
+		return nil, newArithmeticError(ErrorTypeRecursion, "more than %d nested calls", MaxDepth)
+	}
+	function, ok := e.GetFunction(name)
+	if !ok {
+		return nil, newArithmeticError(ErrorTypeUnknownIdentifier, "%s is not defined", name)
+	}
+	if arity != len(function.Parameters) {
+		return nil, newArithmeticError(ErrorTypeArity, "%s takes %d arguments but %d were given",
+			name, len(function.Parameters), arity)
+	}
+	return function, nil
+}
+
+// Copy returns a deep copy of the value, matrix operations modify their operands
+func (v Value) Copy() Value {
+	if v.Matrix == nil {
+		return v
+	}
+	m := complex.NewMatrix(v.Matrix.Prec)
+	m.Values = make([][]complex.Rational, len(v.Matrix.Values))
+	for i, row := range v.Matrix.Values {
+		m.Values[i] = make([]complex.Rational, len(row))
+		for j, value := range row {
+			m.Values[i][j] = *complex.NewRational(big.NewRat(0, 1).Set(value.A), big.NewRat(0, 1).Set(value.B))
+		}
+	}
+	v.Matrix = &m
+	return v
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+func TestEnvironment(t *testing.T) {
+	env := NewEnvironment()
+	m := complex.NewMatrix(DefaultPrec)
+	m.Values = [][]complex.Rational{{*complex.NewRational(big.NewRat(5, 1), big.NewRat(0, 1))}}
+	env.Set("a", Value{Matrix: &m})
+	// the binding is a copy
+	m.Values[0][0].A.SetInt64(6)
+
+	value, err := evaluate(env, "b = 2*a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := format(value); result != "10" {
+		t.Errorf("b = 2*a = %s, want 10", result)
+	}
+	if names := env.Names(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("names are %v, want [a b]", names)
+	}
+	b, ok := env.Get("b")
+	if !ok || format(b) != "10" {
+		t.Errorf("b is %s, want 10", format(b))
+	}
+	// the value returned is a copy
+	b.Matrix.Values[0][0].A.SetInt64(11)
+	if b, _ := env.Get("b"); format(b) != "10" {
+		t.Errorf("b changed to %s", format(b))
+	}
+
+	env.Delete("a")
+	if _, ok := env.Get("a"); ok {
+		t.Error("a wasn't deleted")
+	}
+	if _, err := evaluate(env, "a"); err == nil {
+		t.Error("a is still defined")
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	runSession(t, []test{
+		{"f(x, y) = x^2 + y", "x^2 + y"},
+		{"f(3, 4)", "13"},
+		{"derivative(f(x, 2))", "2 * x"},
+		{"derivative(f(x, y), y)", "1"},
+		{"simplify(f(x, x))", "x^2 + x"},
+		{"g(x) = f(x, 1) + 1", "f(x, 1) + 1"},
+		{"g(2)", "6"},
+		{"k = 10", "10"},
+		{"h(x) = x*k", "x * k"},
+		{"h(2)", "20"},
+	})
+
+	env := NewEnvironment()
+	for _, expression := range []string{"f(x, y) = x + y", "r(n) = r(n)"} {
+		if _, err := evaluate(env, expression); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		expression string
+		errorType  ErrorType
+	}{
+		{"f(1)", ErrorTypeArity},
+		{"f(1, 2, 3)", ErrorTypeArity},
+		{"r(1)", ErrorTypeRecursion},
+		{"u(1)", ErrorTypeUnknownIdentifier},
+		{"f(x, x) = 1", ErrorTypeDomain},
+		{"sin(x) = x", ErrorTypeDomain},
+		{"f(pi) = 1", ErrorTypeDomain},
+	}
+	for _, test := range tests {
+		_, err := evaluate(env, test.expression)
+		if e, ok := err.(*Error); !ok || e.ErrorType != test.errorType {
+			t.Errorf("%s: %v, want %v", test.expression, err, test.errorType)
+		}
+	}
+	if names := env.Functions(); !reflect.DeepEqual(names, []string{"f", "r"}) {
+		t.Errorf("functions are %v, want [f r]", names)
+	}
+}
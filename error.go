@@ -0,0 +1,109 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+)
+
+// ErrorType is an evaluation error type
+type ErrorType int
+
+const (
+	// ErrorTypeDimension is a matrix dimension mismatch
+	ErrorTypeDimension ErrorType = iota
+	// ErrorTypeDivisionByZero is a division by zero
+	ErrorTypeDivisionByZero
+	// ErrorTypeUnknownIdentifier is a reference to an unknown identifier
+	ErrorTypeUnknownIdentifier
+	// ErrorTypeNonInteger is a non-integer where an integer is required
+	ErrorTypeNonInteger
+	// ErrorTypeValue is an expression where a matrix is required or vice versa
+	ErrorTypeValue
+	// ErrorTypeDomain is an argument outside of the domain of a function
+	ErrorTypeDomain
+	// ErrorTypeArity is a call with the wrong number of arguments
+	ErrorTypeArity
+	// ErrorTypeRecursion is a chain of calls that is nested too deeply
+	ErrorTypeRecursion
+	// ErrorTypeAmbiguous is an expression with more than one candidate variable
+	ErrorTypeAmbiguous
+	// ErrorTypeConvergence is a numerical method which failed to converge
+	ErrorTypeConvergence
+)
+
+var errorTypeNames = [...]string{
+	ErrorTypeDimension:         "dimension mismatch",
+	ErrorTypeDivisionByZero:    "division by zero",
+	ErrorTypeUnknownIdentifier: "unknown identifier",
+	ErrorTypeNonInteger:        "non-integer",
+	ErrorTypeValue:             "invalid value",
+	ErrorTypeDomain:            "domain error",
+	ErrorTypeArity:             "wrong number of arguments",
+	ErrorTypeRecursion:         "recursion too deep",
+	ErrorTypeAmbiguous:         "ambiguous variable",
+	ErrorTypeConvergence:       "no convergence",
+}
+
+func (e ErrorType) String() string {
+	if int(e) < len(errorTypeNames) {
+		return errorTypeNames[e]
+	}
+	return fmt.Sprintf("ErrorType(%d)", int(e))
+}
+
+// Error is an evaluation error which spans the offending sub-expression
+type Error struct {
+	ErrorType  ErrorType
+	Begin, End int
+	Text       string
+	Message    string
+}
+
+// Error returns the string form of the error
+func (e *Error) Error() string {
+	if e.Begin == e.End {
+		return fmt.Sprintf("%v: %s in %q", e.ErrorType, e.Message, e.Text)
+	}
+	return fmt.Sprintf("%v: %s in %q (symbol %d - symbol %d)",
+		e.ErrorType, e.Message, e.Text, e.Begin, e.End)
+}
+
+// newNodeError creates an error for an expression which has no source position
+func newNodeError(errorType ErrorType, n *Node, format string, a ...interface{}) *Error {
+	return &Error{
+		ErrorType: errorType,
+		Text:      n.String(),
+		Message:   fmt.Sprintf(format, a...),
+	}
+}
+
+// newError creates an error spanning the nodes begin through end
+func (c *Calculator) newError(errorType ErrorType, begin, end *node32, format string, a ...interface{}) *Error {
+	start, stop := int(begin.begin), int(end.end)
+	for stop > start && (c.buffer[stop-1] == ' ' || c.buffer[stop-1] == '\t') {
+		stop--
+	}
+	return &Error{
+		ErrorType: errorType,
+		Begin:     start,
+		End:       stop,
+		Text:      string(c.buffer[start:stop]),
+		Message:   fmt.Sprintf(format, a...),
+	}
+}
+
+// locate sets the span of an arithmetic error to the nodes begin through end
+func (c *Calculator) locate(err error, begin, end *node32) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+	located := c.newError(e.ErrorType, begin, end, "%s", e.Message)
+	if e.Text != "" && e.Text != located.Text {
+		located.Message = fmt.Sprintf("%s in %q", e.Message, e.Text)
+	}
+	return located
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+
+	"github.com/ALTree/bigfloat"
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// checkPrec checks that the precision of an evaluation of n is positive
+func checkPrec(n *Node, prec uint) error {
+	if prec == 0 {
+		return newNodeError(ErrorTypeNonInteger, n, "precision must be a positive integer")
+	}
+	return nil
+}
+
+// Evaluate numerically evaluates the expression at precision prec, looking up
+// variables and functions in env
+func (n *Node) Evaluate(env *Environment, prec uint) (*complex.Matrix, error) {
+	if err := checkPrec(n, prec); err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	reservePI(prec)
+	return n.evaluate(env, prec, 0)
+}
+
+// evaluate numerically evaluates the expression at the given call depth
+func (n *Node) evaluate(env *Environment, prec uint, depth int) (*complex.Matrix, error) {
+	var process, value func(n *Node) (*complex.Matrix, error)
+	binary := func(n *Node, operation func(a, b *complex.Matrix) error) (*complex.Matrix, error) {
+		a, err := process(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		b, err := process(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		if err := operation(a, b); err != nil {
+			err.(*Error).Text = n.String()
+			return nil, err
+		}
+		return a, nil
+	}
+	unary := func(n *Node, operation func(a *complex.Matrix) *complex.Matrix) (*complex.Matrix, error) {
+		a, err := process(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		return operation(a), nil
+	}
+	// the shared sub-expressions of a DAG are evaluated once, the matrix
+	// operations modify their operands so copies of the values are returned
+	shared, values := n.shared(), make(map[*Node]*complex.Matrix)
+	process = func(n *Node) (*complex.Matrix, error) {
+		if !shared[n] {
+			return value(n)
+		}
+		a, ok := values[n]
+		if !ok {
+			var err error
+			if a, err = value(n); err != nil {
+				return nil, err
+			}
+			values[n] = a
+		}
+		return Value{Matrix: a}.Copy().Matrix, nil
+	}
+	value = func(n *Node) (*complex.Matrix, error) {
+		if n == nil {
+			return nil, &Error{
+				ErrorType: ErrorTypeValue,
+				Message:   "missing expression",
+			}
+		}
+		switch n.Operation {
+		case OperationAdd:
+			return binary(n, add)
+		case OperationSubtract:
+			return binary(n, subtract)
+		case OperationMultiply:
+			return binary(n, multiply)
+		case OperationDivide:
+			return binary(n, divide)
+		case OperationModulus:
+			return binary(n, modulus)
+		case OperationExponentiation:
+			return binary(n, power)
+		case OperationNegate:
+			a, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			return a.Neg(a), nil
+		case OperationVariable:
+			a, ok := env.Get(n.Value)
+			if !ok {
+				return nil, newNodeError(ErrorTypeUnknownIdentifier, n, "%s is not defined", n.Value)
+			} else if a.Matrix == nil {
+				return nil, newNodeError(ErrorTypeValue, n, "%s is not a matrix", n.Value)
+			}
+			a.Matrix.Prec = prec
+			return a.Matrix, nil
+		case OperationImaginary:
+			a := parseNumber(n.Value, "", prec)
+			a.A, a.B = a.B, a.A
+			return newScalar(prec, a), nil
+		case OperationNumber:
+			return newScalar(prec, parseNumber(n.Value, "", prec)), nil
+		case OperationNotation:
+			a := parseNumber(n.Left.Value, n.Right.Value, prec)
+			if n.Left.Operation == OperationImaginary {
+				a.A, a.B = a.B, a.A
+			}
+			return newScalar(prec, a), nil
+		case OperationNaturalExponentiation:
+			return unary(n, exponential)
+		case OperationNatural:
+			a := newScalar(prec, complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1)))
+			return exponential(a), nil
+		case OperationPI:
+			a := big.NewRat(1, 1)
+			bigfloat.PI(prec).Rat(a)
+			return newScalar(prec, complex.NewRational(a, big.NewRat(0, 1))), nil
+		case OperationInfinity:
+			return nil, newNodeError(ErrorTypeDomain, n, "inf is not a number")
+		case OperationNaturalLogarithm:
+			a, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			if err := logarithm(a); err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			return a, nil
+		case OperationSquareRoot:
+			return unary(n, squareRoot)
+		case OperationCosine:
+			return unary(n, cosine)
+		case OperationSine:
+			return unary(n, sine)
+		case OperationTangent:
+			return unary(n, tangent)
+		case OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			a, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			if err := functionMatrices[n.Operation](a); err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			return a, nil
+		case OperationArctangent2:
+			return binary(n, arctangent2)
+		case OperationLogarithm:
+			return binary(n, logarithmBase)
+		case OperationFactorial:
+			a, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			if err := factorialFunction(a); err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			return a, nil
+		case OperationBeta:
+			return binary(n, betaFunction)
+		case OperationZetaDerivative:
+			return binary(n, zetaDerivative)
+		case OperationPolygamma:
+			return binary(n, polygammaFunction)
+		case OperationLambertW:
+			return binary(n, lambertFunction)
+		case OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			return binary(n, besselFunction(n.Operation))
+		case OperationGCD, OperationLCM, OperationPowMod, OperationInvMod, OperationCRT, OperationJacobi:
+			var operands []*complex.Matrix
+			for _, operand := range n.operands() {
+				a, err := process(operand)
+				if err != nil {
+					return nil, err
+				}
+				operands = append(operands, a)
+			}
+			if err := integerMatrices[n.Operation](operands...); err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			return operands[0], nil
+		case OperationCall:
+			function, err := env.function(n.Value, len(n.Arguments), depth)
+			if err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			scope := env.scope()
+			for i, parameter := range function.Parameters {
+				a, err := process(n.Arguments[i])
+				if err != nil {
+					return nil, err
+				}
+				scope.values[parameter] = Value{
+					ValueType: ValueTypeMatrix,
+					Matrix:    a,
+				}
+			}
+			return function.Body.evaluate(scope, prec, depth+1)
+		case OperationMatrix:
+			a := complex.NewMatrix(prec)
+			a.Values = make([][]complex.Rational, len(n.Rows))
+			for i, row := range n.Rows {
+				if len(row) == 0 || len(row) != len(n.Rows[0]) {
+					return nil, newNodeError(ErrorTypeDimension, n, "rows must have the same number of columns")
+				}
+				for _, element := range row {
+					b, err := process(element)
+					if err != nil {
+						return nil, err
+					} else if !isScalar(b) {
+						return nil, newNodeError(ErrorTypeDimension, n, "matrix within matrix not allowed")
+					}
+					a.Values[i] = append(a.Values[i], b.Values[0][0])
+				}
+			}
+			return &a, nil
+		}
+		return nil, newNodeError(ErrorTypeValue, n, "can not evaluate the expression")
+	}
+	a, err := process(n)
+	if err != nil {
+		return nil, err
+	}
+	a.Prec = prec
+	return a, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+func TestEval(t *testing.T) {
+	run(t, []test{
+		{"eval(derivative(x^3), x=2)", "12"},
+		{"eval(x*y, x=2, y=3)", "6"},
+		{"eval(x^2, x=[1 2; 3 4])", "[1 4;9 16]"},
+		{"eval(x^2, x=derivative(y^2))", "4 * y^2"},
+		{"eval(x + z, x=derivative(y^2), z=3)", "2 * y + 3"},
+		{"eval(derivative(x^2*y, x), y=derivative(t^2), x=3)", "12 * t"},
+		{"eval(derivative(x^3) - x, x=2)", "10"},
+		{"eval(1 + solve(x^2 - a, x, 1), a=4)", "3"},
+		{"eval(withprec(20, pi*x), x=1)", "3.141593933"},
+	})
+	runErrors(t, []errorTest{
+		{"eval(x^2, x=1, x=2)", ErrorTypeDomain, 15, 16},
+		{"eval(x^2, x=1/0)", ErrorTypeDivisionByZero, 12, 15},
+		{"eval(x^2 + y, x=1)", ErrorTypeUnknownIdentifier, 0, 0},
+		{"eval(pi, pi=3)", ErrorTypeValue, 9, 11},
+		{"eval(y, sin=3)", ErrorTypeValue, 8, 11},
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	value, err := evaluate(NewEnvironment(), "derivative(x^3 + y, x)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := NewEnvironment()
+	x := complex.NewMatrix(DefaultPrec)
+	x.Values = [][]complex.Rational{{*complex.NewRational(big.NewRat(1, 2), big.NewRat(0, 1))}}
+	env.Set("x", Value{Matrix: &x})
+	m, err := value.Expression.Evaluate(env, DefaultPrec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := m.String(); result != "0.75" {
+		t.Errorf("3*x^2 at x=1/2 is %s, want 0.75", result)
+	}
+
+	if _, err := value.Expression.Evaluate(nil, DefaultPrec); err == nil {
+		t.Error("x is defined in a nil environment")
+	}
+	_, err = value.Expression.Evaluate(env, 0)
+	if e, ok := err.(*Error); !ok || e.ErrorType != ErrorTypeNonInteger {
+		t.Errorf("evaluation at a precision of 0: %v", err)
+	}
+}
@@ -6,6 +6,11 @@ package calc
 
 import (
 	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+
+	complex "github.com/pointlander/c0mpl3x"
 )
 
 // Operation is a mathematical operation
@@ -52,35 +57,258 @@ const (
 	OperationTangent
 	// OperationNotation is E notation operation
 	OperationNotation
+	// OperationCall calls a user defined function
+	OperationCall
+	// OperationMatrix is a matrix of expressions
+	OperationMatrix
+	// OperationIntegral is an unevaluated integral of the left node with
+	// respect to the variable on the right
+	OperationIntegral
+	// OperationInfinity is positive infinity, the target or the value of a
+	// limit
+	OperationInfinity
+	// OperationArcsine computes the inverse sine of a number
+	OperationArcsine
+	// OperationArccosine computes the inverse cosine of a number
+	OperationArccosine
+	// OperationArctangent computes the inverse tangent of a number
+	OperationArctangent
+	// OperationArctangent2 computes the angle of the point with the x
+	// coordinate on the right and the y coordinate on the left
+	OperationArctangent2
+	// OperationHyperbolicSine computes the hyperbolic sine of a number
+	OperationHyperbolicSine
+	// OperationHyperbolicCosine computes the hyperbolic cosine of a number
+	OperationHyperbolicCosine
+	// OperationHyperbolicTangent computes the hyperbolic tangent of a number
+	OperationHyperbolicTangent
+	// OperationHyperbolicArcsine computes the inverse hyperbolic sine of a
+	// number
+	OperationHyperbolicArcsine
+	// OperationHyperbolicArccosine computes the inverse hyperbolic cosine of a
+	// number
+	OperationHyperbolicArccosine
+	// OperationHyperbolicArctangent computes the inverse hyperbolic tangent of
+	// a number
+	OperationHyperbolicArctangent
+	// OperationSecant computes the secant of a number
+	OperationSecant
+	// OperationCosecant computes the cosecant of a number
+	OperationCosecant
+	// OperationCotangent computes the cotangent of a number
+	OperationCotangent
+	// OperationLogarithm is the logarithm of the left node to the base on the
+	// right
+	OperationLogarithm
+	// OperationFactorial computes the factorial of a number
+	OperationFactorial
+	// OperationGamma computes the gamma function of a number
+	OperationGamma
+	// OperationLogGamma computes the principal logarithm of the gamma
+	// function of a number
+	OperationLogGamma
+	// OperationBeta computes the beta function of the left and the right
+	// nodes
+	OperationBeta
+	// OperationErf computes the error function of a number
+	OperationErf
+	// OperationErfc computes the complementary error function of a number
+	OperationErfc
+	// OperationZeta computes the Riemann zeta function of a number
+	OperationZeta
+	// OperationZetaDerivative computes the derivative of the Riemann zeta
+	// function of the left node with the order on the right
+	OperationZetaDerivative
+	// OperationDigamma computes the digamma function of a number
+	OperationDigamma
+	// OperationPolygamma computes the polygamma function of the left node
+	// with the order on the right
+	OperationPolygamma
+	// OperationLambertW computes the Lambert W function of the left node on
+	// the branch on the right
+	OperationLambertW
+	// OperationBesselJ computes the Bessel function of the first kind of the
+	// left node with the order on the right
+	OperationBesselJ
+	// OperationBesselY computes the Bessel function of the second kind of the
+	// left node with the order on the right
+	OperationBesselY
+	// OperationBesselI computes the modified Bessel function of the first
+	// kind of the left node with the order on the right
+	OperationBesselI
+	// OperationBesselK computes the modified Bessel function of the second
+	// kind of the left node with the order on the right
+	OperationBesselK
+	// OperationAiryAi computes the Airy function Ai of a number
+	OperationAiryAi
+	// OperationAiryBi computes the Airy function Bi of a number
+	OperationAiryBi
+	// OperationAiryAiPrime computes the derivative of the Airy function Ai of
+	// a number
+	OperationAiryAiPrime
+	// OperationAiryBiPrime computes the derivative of the Airy function Bi of
+	// a number
+	OperationAiryBiPrime
+	// OperationEllipticK computes the complete elliptic integral of the first
+	// kind of a parameter
+	OperationEllipticK
+	// OperationEllipticE computes the complete elliptic integral of the
+	// second kind of a parameter
+	OperationEllipticE
+	// OperationGCD computes the greatest common divisor of the left and the
+	// right integers
+	OperationGCD
+	// OperationLCM computes the least common multiple of the left and the
+	// right integers
+	OperationLCM
+	// OperationIsPrime is one if an integer is a prime and zero otherwise
+	OperationIsPrime
+	// OperationNextPrime computes the smallest prime larger than an integer
+	OperationNextPrime
+	// OperationFactor computes the matrix of the prime factors of an integer
+	// and their multiplicities
+	OperationFactor
+	// OperationTotient computes Euler's totient function of an integer
+	OperationTotient
+	// OperationPowMod computes the power of the first argument to the second
+	// modulo the third
+	OperationPowMod
+	// OperationInvMod computes the inverse of the left integer modulo the
+	// right
+	OperationInvMod
+	// OperationCRT solves the congruences with the residues on the left and
+	// the moduli on the right with the Chinese remainder theorem
+	OperationCRT
+	// OperationJacobi computes the Jacobi symbol of the left integer over the
+	// right
+	OperationJacobi
 )
 
+// functionNames are the names of the functions which are written with their
+// arguments in parentheses
+var functionNames = map[Operation]string{
+	OperationArcsine:              "asin",
+	OperationArccosine:            "acos",
+	OperationArctangent:           "atan",
+	OperationArctangent2:          "atan2",
+	OperationHyperbolicSine:       "sinh",
+	OperationHyperbolicCosine:     "cosh",
+	OperationHyperbolicTangent:    "tanh",
+	OperationHyperbolicArcsine:    "asinh",
+	OperationHyperbolicArccosine:  "acosh",
+	OperationHyperbolicArctangent: "atanh",
+	OperationSecant:               "sec",
+	OperationCosecant:             "csc",
+	OperationCotangent:            "cot",
+	OperationGamma:                "gamma",
+	OperationLogGamma:             "lgamma",
+	OperationBeta:                 "beta",
+	OperationErf:                  "erf",
+	OperationErfc:                 "erfc",
+	OperationZeta:                 "zeta",
+	OperationZetaDerivative:       "zeta",
+	OperationDigamma:              "digamma",
+	OperationPolygamma:            "polygamma",
+	OperationLambertW:             "lambertw",
+	OperationBesselJ:              "besselj",
+	OperationBesselY:              "bessely",
+	OperationBesselI:              "besseli",
+	OperationBesselK:              "besselk",
+	OperationAiryAi:               "airyai",
+	OperationAiryBi:               "airybi",
+	OperationAiryAiPrime:          "airyaiprime",
+	OperationAiryBiPrime:          "airybiprime",
+	OperationEllipticK:            "ellipk",
+	OperationEllipticE:            "ellipe",
+	OperationGCD:                  "gcd",
+	OperationLCM:                  "lcm",
+	OperationIsPrime:              "isprime",
+	OperationNextPrime:            "nextprime",
+	OperationFactor:               "factor",
+	OperationTotient:              "totient",
+	OperationPowMod:               "powmod",
+	OperationInvMod:               "invmod",
+	OperationCRT:                  "crt",
+	OperationJacobi:               "jacobi",
+}
+
 // Node is a node in an expression binary tree
 type Node struct {
 	Operation   Operation
 	Value       string
 	Left, Right *Node
+	Arguments   []*Node
+	Rows        [][]*Node
 }
 
 // Equals test if value is equal to x
 func (n *Node) Equals(x int64) bool {
-	if n.Operation == OperationNotation {
-		a := big.NewInt(0)
-		a.SetString(n.Left.Value, 10)
-		b := big.NewInt(10)
-		c := big.NewInt(0)
-		c.SetString(n.Right.Value, 10)
-		b.Exp(b, c, nil)
-		a.Mul(a, b)
-		return a.Cmp(big.NewInt(x)) == 0
-	}
-	value := big.NewInt(0)
-	value.SetString(n.Value, 10)
-	return value.Cmp(big.NewInt(x)) == 0
+	value, ok := number(n)
+	return ok && value.B.Sign() == 0 && value.A.Cmp(big.NewRat(x, 1)) == 0
+}
+
+// operands returns the arguments of the operation, which are the left and the
+// right nodes unless there are more than two
+func (n *Node) operands() []*Node {
+	if n.Arguments != nil {
+		return n.Arguments
+	} else if n.Right == nil {
+		return []*Node{n.Left}
+	}
+	return []*Node{n.Left, n.Right}
+}
+
+// The precedence levels of the grammar from the loosest to the tightest
+const (
+	precedenceSum = iota + 1
+	precedenceProduct
+	precedencePower
+	precedenceNegation
+	precedenceFactorial
+	precedenceValue
+)
+
+// precedence returns the precedence level of the operation at the root of
+// the expression
+func (n *Node) precedence() int {
+	switch n.Operation {
+	case OperationAdd, OperationSubtract:
+		return precedenceSum
+	case OperationMultiply, OperationDivide, OperationModulus:
+		return precedenceProduct
+	case OperationExponentiation, OperationNaturalExponentiation:
+		return precedencePower
+	case OperationNegate:
+		return precedenceNegation
+	case OperationFactorial:
+		return precedenceFactorial
+	case OperationNumber, OperationImaginary:
+		if strings.HasPrefix(n.Value, "-") {
+			return precedenceNegation
+		}
+	case OperationNotation:
+		return n.Left.precedence()
+	}
+	return precedenceValue
 }
 
-// String returns the string form of the equation
+// String returns the string form of the equation with the minimal parentheses
+// required for it to parse to the same expression. The operators are left
+// associative and negation binds tighter than exponentiation, so the operands
+// of an exponentiation are parenthesized unless they are values or factorials.
 func (n *Node) String() string {
 	var process func(n *Node) string
+	// operand parenthesizes the operand if it binds looser than the minimum
+	operand := func(n *Node, minimum int) string {
+		s := process(n)
+		if n.precedence() < minimum {
+			return "(" + s + ")"
+		}
+		return s
+	}
+	binary := func(n *Node, operator string, precedence int) string {
+		return operand(n.Left, precedence) + operator + operand(n.Right, precedence+1)
+	}
 	process = func(n *Node) string {
 		if n == nil {
 			return ""
@@ -89,19 +317,27 @@ func (n *Node) String() string {
 		case OperationNoop:
 			return "(" + process(n.Left) + "???" + process(n.Right) + ")"
 		case OperationAdd:
-			return "(" + process(n.Left) + " + " + process(n.Right) + ")"
+			return binary(n, " + ", precedenceSum)
 		case OperationSubtract:
-			return "(" + process(n.Left) + " - " + process(n.Right) + ")"
+			return binary(n, " - ", precedenceSum)
 		case OperationMultiply:
-			return "(" + process(n.Left) + " * " + process(n.Right) + ")"
+			return binary(n, " * ", precedenceProduct)
 		case OperationDivide:
-			return "(" + process(n.Left) + " / " + process(n.Right) + ")"
+			return binary(n, " / ", precedenceProduct)
 		case OperationModulus:
-			return "(" + process(n.Left) + " % " + process(n.Right) + ")"
+			return binary(n, " % ", precedenceProduct)
 		case OperationExponentiation:
-			return "(" + process(n.Left) + "^" + process(n.Right) + ")"
+			base := operand(n.Left, precedenceFactorial)
+			if n.Left.Operation == OperationNatural {
+				// e^ is the natural exponentiation
+				base = "(" + base + ")"
+			}
+			return base + "^" + operand(n.Right, precedenceNegation)
 		case OperationNegate:
-			return "-(" + process(n.Left) + ")"
+			return "-" + operand(n.Left, precedenceFactorial)
+		case OperationFactorial:
+			// y!! would read as the double factorial
+			return operand(n.Left, precedenceValue) + "!"
 		case OperationVariable:
 			return n.Value
 		case OperationImaginary:
@@ -114,11 +350,16 @@ func (n *Node) String() string {
 			}
 			return process(n.Left) + "e" + process(n.Right)
 		case OperationNaturalExponentiation:
-			return "(e^" + process(n.Left) + ")"
+			if n.Left.Operation == OperationNatural {
+				return "e^(e)"
+			}
+			return "e^" + operand(n.Left, precedenceValue)
 		case OperationNatural:
 			return "e"
 		case OperationPI:
 			return "pi"
+		case OperationInfinity:
+			return "inf"
 		case OperationNaturalLogarithm:
 			return "log(" + process(n.Left) + ")"
 		case OperationSquareRoot:
@@ -129,21 +370,206 @@ func (n *Node) String() string {
 			return "sin(" + process(n.Left) + ")"
 		case OperationTangent:
 			return "tan(" + process(n.Left) + ")"
+		case OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			return functionNames[n.Operation] + "(" + process(n.Left) + ")"
+		case OperationArctangent2, OperationBeta,
+			OperationGCD, OperationLCM, OperationInvMod, OperationCRT, OperationJacobi:
+			return functionNames[n.Operation] + "(" + process(n.Left) + ", " + process(n.Right) + ")"
+		case OperationPowMod:
+			arguments := make([]string, len(n.Arguments))
+			for i, argument := range n.Arguments {
+				arguments[i] = process(argument)
+			}
+			return "powmod(" + strings.Join(arguments, ", ") + ")"
+		case OperationZetaDerivative, OperationPolygamma,
+			OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			// the order is the first argument
+			return functionNames[n.Operation] + "(" + process(n.Right) + ", " + process(n.Left) + ")"
+		case OperationLambertW:
+			// the principal branch is the default
+			if n.Right.Operation == OperationNumber && n.Right.Value == "0" {
+				return "lambertw(" + process(n.Left) + ")"
+			}
+			return "lambertw(" + process(n.Left) + ", " + process(n.Right) + ")"
+		case OperationLogarithm:
+			switch {
+			case n.Right.Operation == OperationNumber && n.Right.Value == "2":
+				return "log2(" + process(n.Left) + ")"
+			case n.Right.Operation == OperationNumber && n.Right.Value == "10":
+				return "log10(" + process(n.Left) + ")"
+			}
+			return "log(" + process(n.Left) + ", " + process(n.Right) + ")"
+		case OperationCall:
+			s := n.Value + "("
+			for i, argument := range n.Arguments {
+				if i > 0 {
+					s += ", "
+				}
+				s += process(argument)
+			}
+			return s + ")"
+		case OperationMatrix:
+			s := "["
+			for i, row := range n.Rows {
+				if i > 0 {
+					s += ";"
+				}
+				elements := make([]string, len(row))
+				for j, element := range row {
+					elements[j] = process(element)
+					// the elements are separated by spaces and a leading sign
+					// would be parsed as a binary operator
+					if element.precedence() < precedencePower ||
+						element.precedence() == precedenceNegation {
+						elements[j] = "(" + elements[j] + ")"
+					}
+				}
+				for j, element := range elements {
+					// a name followed by a parenthesis would be parsed as a call
+					if j+1 < len(elements) && strings.HasPrefix(elements[j+1], "(") {
+						if last := element[len(element)-1]; unicode.IsLetter(rune(last)) {
+							element = "(" + element + ")"
+						}
+					}
+					if j > 0 {
+						s += " "
+					}
+					s += element
+				}
+			}
+			return s + "]"
+		case OperationIntegral:
+			return "integrate(" + process(n.Left) + ", " + process(n.Right) + ")"
 		}
 		return ""
 	}
 	return process(n)
 }
 
-// Derivative takes the derivative of the equation
-// https://www.cs.utexas.edu/users/novak/asg-symdif.html#:~:text=Introduction,numeric%20calculations%20based%20on%20formulas.
+// Variables returns the sorted names of the variables in the expression
+func (n *Node) Variables() []string {
+	seen := make(map[string]bool)
+	var process func(n *Node)
+	process = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if n.Operation == OperationVariable {
+			seen[n.Value] = true
+		} else if n.Operation == OperationIntegral {
+			// the variable of integration is bound
+			for _, variable := range n.Left.Variables() {
+				if variable != n.Right.Value {
+					seen[variable] = true
+				}
+			}
+			return
+		}
+		process(n.Left)
+		process(n.Right)
+		for _, argument := range n.Arguments {
+			process(argument)
+		}
+		for _, row := range n.Rows {
+			for _, element := range row {
+				process(element)
+			}
+		}
+	}
+	process(n)
+	variables := make([]string, 0, len(seen))
+	for variable := range seen {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+	return variables
+}
+
+// Derivative takes the derivative of the equation, the derivative of each
+// variable is one so the derivative of an equation of several variables is
+// the sum of its partial derivatives. The result is nil if the derivative
+// isn't supported.
 func (n *Node) Derivative() *Node {
-	var process func(n *Node) *Node
+	variables := n.Variables()
+	if len(variables) == 0 {
+		return n.DerivativeWith("")
+	}
+	var a *Node
+	for _, variable := range variables {
+		b := n.DerivativeWith(variable)
+		if b == nil {
+			return nil
+		} else if a == nil {
+			a = b
+			continue
+		}
+		a = &Node{
+			Operation: OperationAdd,
+			Left:      a,
+			Right:     b,
+		}
+	}
+	return a
+}
+
+// DerivativeE takes the derivative of the equation with respect to its only
+// variable and returns an error if it has several variables
+func (n *Node) DerivativeE() (*Node, error) {
+	variables := n.Variables()
+	switch len(variables) {
+	case 0:
+		return n.DerivativeWith(""), nil
+	case 1:
+		return n.DerivativeWith(variables[0]), nil
+	}
+	return nil, newNodeError(ErrorTypeAmbiguous, n, "the variable must be one of %s",
+		strings.Join(variables, ", "))
+}
+
+// DerivativeWith takes the partial derivative of the equation with respect to
+// the variable name, other variables are constants. The result is nil if the
+// derivative of a sub-expression isn't supported.
+// https://www.cs.utexas.edu/users/novak/asg-symdif.html#:~:text=Introduction,numeric%20calculations%20based%20on%20formulas.
+func (n *Node) DerivativeWith(name string) *Node {
+	// the derivative of a shared sub-expression is taken once and shared,
+	// so the repeated copies of n and n.Left don't grow exponentially
+	t := NewInterner()
+	derivatives := make(map[*Node]*Node)
+	unsupported := false
+	var process, derivative func(n *Node) *Node
 	process = func(n *Node) *Node {
+		if a, ok := derivatives[n]; ok {
+			return a
+		}
+		a := derivative(n)
+		if a == nil && n != nil {
+			unsupported = true
+		}
+		derivatives[n] = a
+		return a
+	}
+	derivative = func(n *Node) *Node {
 		if n == nil {
 			return nil
 		}
 		switch n.Operation {
+		case OperationAdd, OperationSubtract, OperationMultiply, OperationNegate, OperationMatrix:
+		default:
+			// the chain rule of an elementwise operation on a matrix isn't a
+			// matrix product
+			if matrix(n.Left) || matrix(n.Right) {
+				return nil
+			}
+		}
+		switch n.Operation {
 		case OperationNoop:
 			return n
 		case OperationAdd:
@@ -161,15 +587,17 @@ func (n *Node) Derivative() *Node {
 			}
 			return a
 		case OperationMultiply:
+			// the factors keep their order because the products of matrices
+			// don't commute
 			left := &Node{
 				Operation: OperationMultiply,
-				Left:      n.Left,
-				Right:     process(n.Right),
+				Left:      process(n.Left),
+				Right:     n.Right,
 			}
 			right := &Node{
 				Operation: OperationMultiply,
-				Left:      n.Right,
-				Right:     process(n.Left),
+				Left:      n.Left,
+				Right:     process(n.Right),
 			}
 			a := &Node{
 				Operation: OperationAdd,
@@ -208,8 +636,58 @@ func (n *Node) Derivative() *Node {
 			}
 			return a
 		case OperationModulus:
-			return n
+			// f % g = f - g floor(f / g) where floor(f / g) = (f - f % g) / g
+			// is constant between the jumps
+			floor := &Node{
+				Operation: OperationDivide,
+				Left: &Node{
+					Operation: OperationSubtract,
+					Left:      n.Left,
+					Right:     n,
+				},
+				Right: n.Right,
+			}
+			a := &Node{
+				Operation: OperationSubtract,
+				Left:      process(n.Left),
+				Right: &Node{
+					Operation: OperationMultiply,
+					Left:      process(n.Right),
+					Right:     floor,
+				},
+			}
+			return a
 		case OperationExponentiation:
+			if n.Right.depends(name) {
+				// d(f^g) = f^g (g' log f + g f' / f)
+				log := &Node{
+					Operation: OperationMultiply,
+					Left:      process(n.Right),
+					Right: &Node{
+						Operation: OperationNaturalLogarithm,
+						Left:      n.Left,
+					},
+				}
+				power := &Node{
+					Operation: OperationDivide,
+					Left: &Node{
+						Operation: OperationMultiply,
+						Left:      n.Right,
+						Right:     process(n.Left),
+					},
+					Right: n.Left,
+				}
+				a := &Node{
+					Operation: OperationMultiply,
+					Left:      n,
+					Right: &Node{
+						Operation: OperationAdd,
+						Left:      log,
+						Right:     power,
+					},
+				}
+				return a
+			}
 			one := &Node{
 				Operation: OperationNumber,
 				Value:     "1",
@@ -244,7 +722,10 @@ func (n *Node) Derivative() *Node {
 		case OperationVariable:
 			a := &Node{
 				Operation: OperationNumber,
-				Value:     "1",
+				Value:     "0",
+			}
+			if n.Value == name {
+				a.Value = "1"
 			}
 			return a
 		case OperationImaginary:
@@ -284,6 +765,12 @@ func (n *Node) Derivative() *Node {
 				Value:     "0",
 			}
 			return a
+		case OperationInfinity:
+			a := &Node{
+				Operation: OperationNumber,
+				Value:     "0",
+			}
+			return a
 		case OperationNaturalLogarithm:
 			a := &Node{
 				Operation: OperationDivide,
@@ -358,10 +845,520 @@ func (n *Node) Derivative() *Node {
 				Right:     process(n.Left),
 			}
 			return a
+		case OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationFactorial, OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE:
+			a := &Node{
+				Operation: OperationMultiply,
+				Left:      outer(n),
+				Right:     process(n.Left),
+			}
+			return a
+		case OperationLambertW, OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			// the derivative with respect to the branch or the order isn't
+			// supported
+			if n.Right.depends(name) {
+				return nil
+			}
+			a := &Node{
+				Operation: OperationMultiply,
+				Left:      outer(n),
+				Right:     process(n.Left),
+			}
+			return a
+		case OperationZetaDerivative, OperationPolygamma:
+			// the order is an integer so the derivative only increments it,
+			// the derivative with respect to the order isn't supported
+			if n.Right.depends(name) {
+				return nil
+			}
+			a := &Node{
+				Operation: OperationMultiply,
+				Left: &Node{
+					Operation: n.Operation,
+					Left:      n.Left,
+					Right: &Node{
+						Operation: OperationAdd,
+						Left:      n.Right,
+						Right: &Node{
+							Operation: OperationNumber,
+							Value:     "1",
+						},
+					},
+				},
+				Right: process(n.Left),
+			}
+			return a
+		case OperationBeta:
+			// d B(a, b) = B(a, b) (ψ(a) a' + ψ(b) b' - ψ(a + b) (a' + b'))
+			digamma := func(n *Node) *Node {
+				return &Node{
+					Operation: OperationDigamma,
+					Left:      n,
+				}
+			}
+			left, right := process(n.Left), process(n.Right)
+			sum := &Node{
+				Operation: OperationAdd,
+				Left: &Node{
+					Operation: OperationMultiply,
+					Left:      digamma(n.Left),
+					Right:     left,
+				},
+				Right: &Node{
+					Operation: OperationMultiply,
+					Left:      digamma(n.Right),
+					Right:     right,
+				},
+			}
+			difference := &Node{
+				Operation: OperationSubtract,
+				Left:      sum,
+				Right: &Node{
+					Operation: OperationMultiply,
+					Left: digamma(&Node{
+						Operation: OperationAdd,
+						Left:      n.Left,
+						Right:     n.Right,
+					}),
+					Right: &Node{
+						Operation: OperationAdd,
+						Left:      left,
+						Right:     right,
+					},
+				},
+			}
+			a := &Node{
+				Operation: OperationMultiply,
+				Left:      n,
+				Right:     difference,
+			}
+			return a
+		case OperationArctangent2:
+			// d atan2(y, x) = (x y' - y x') / (x^2 + y^2)
+			square := func(n *Node) *Node {
+				return &Node{
+					Operation: OperationExponentiation,
+					Left:      n,
+					Right: &Node{
+						Operation: OperationNumber,
+						Value:     "2",
+					},
+				}
+			}
+			difference := &Node{
+				Operation: OperationSubtract,
+				Left: &Node{
+					Operation: OperationMultiply,
+					Left:      n.Right,
+					Right:     process(n.Left),
+				},
+				Right: &Node{
+					Operation: OperationMultiply,
+					Left:      n.Left,
+					Right:     process(n.Right),
+				},
+			}
+			a := &Node{
+				Operation: OperationDivide,
+				Left:      difference,
+				Right: &Node{
+					Operation: OperationAdd,
+					Left:      square(n.Right),
+					Right:     square(n.Left),
+				},
+			}
+			return a
+		case OperationLogarithm:
+			log := func(n *Node) *Node {
+				return &Node{
+					Operation: OperationNaturalLogarithm,
+					Left:      n,
+				}
+			}
+			if n.Right.depends(name) {
+				// log(f, g) = log(f) / log(g)
+				return process(&Node{
+					Operation: OperationDivide,
+					Left:      log(n.Left),
+					Right:     log(n.Right),
+				})
+			}
+			// d log(f, b) = f' / (f log(b))
+			a := &Node{
+				Operation: OperationDivide,
+				Left:      process(n.Left),
+				Right: &Node{
+					Operation: OperationMultiply,
+					Left:      n.Left,
+					Right:     log(n.Right),
+				},
+			}
+			return a
+		case OperationMatrix:
+			a := &Node{
+				Operation: OperationMatrix,
+				Rows:      make([][]*Node, len(n.Rows)),
+			}
+			for i, row := range n.Rows {
+				for _, element := range row {
+					a.Rows[i] = append(a.Rows[i], process(element))
+				}
+			}
+			return a
+		case OperationGCD, OperationLCM, OperationIsPrime, OperationNextPrime, OperationTotient,
+			OperationPowMod, OperationInvMod, OperationCRT, OperationJacobi:
+			// the functions of integers are constant where they are defined
+			a := &Node{
+				Operation: OperationNumber,
+				Value:     "0",
+			}
+			return a
+		case OperationIntegral:
+			if n.Right.Value == name {
+				return n.Left
+			}
+			a := &Node{
+				Operation: OperationIntegral,
+				Left:      process(n.Left),
+				Right:     n.Right,
+			}
+			return a
 		}
 		return nil
 	}
-	return process(n)
+	a := process(t.Intern(n))
+	if unsupported {
+		return nil
+	}
+	return t.Intern(a)
+}
+
+// outer is the derivative of the function at the root of the expression with
+// respect to its first argument, the order of a Bessel function is a parameter
+func outer(n *Node) *Node {
+	u := n.Left
+	number := func(value string) *Node {
+		return &Node{
+			Operation: OperationNumber,
+			Value:     value,
+		}
+	}
+	unary := func(operation Operation, left *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+		}
+	}
+	binary := func(operation Operation, left, right *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+			Right:     right,
+		}
+	}
+	square := binary(OperationExponentiation, u, number("2"))
+	switch n.Operation {
+	case OperationArcsine:
+		// 1 / sqrt(1 - u^2)
+		return binary(OperationDivide, number("1"),
+			unary(OperationSquareRoot, binary(OperationSubtract, number("1"), square)))
+	case OperationArccosine:
+		// -1 / sqrt(1 - u^2)
+		return unary(OperationNegate, binary(OperationDivide, number("1"),
+			unary(OperationSquareRoot, binary(OperationSubtract, number("1"), square))))
+	case OperationArctangent:
+		// 1 / (1 + u^2)
+		return binary(OperationDivide, number("1"), binary(OperationAdd, number("1"), square))
+	case OperationHyperbolicSine:
+		return unary(OperationHyperbolicCosine, u)
+	case OperationHyperbolicCosine:
+		return unary(OperationHyperbolicSine, u)
+	case OperationHyperbolicTangent:
+		// 1 - tanh(u)^2
+		return binary(OperationSubtract, number("1"), binary(OperationExponentiation, n, number("2")))
+	case OperationHyperbolicArcsine:
+		// 1 / sqrt(u^2 + 1)
+		return binary(OperationDivide, number("1"),
+			unary(OperationSquareRoot, binary(OperationAdd, square, number("1"))))
+	case OperationHyperbolicArccosine:
+		// 1 / (sqrt(u - 1) sqrt(u + 1)), sqrt(u^2 - 1) has the wrong sign for
+		// u < -1
+		return binary(OperationDivide, number("1"), binary(OperationMultiply,
+			unary(OperationSquareRoot, binary(OperationSubtract, u, number("1"))),
+			unary(OperationSquareRoot, binary(OperationAdd, u, number("1")))))
+	case OperationHyperbolicArctangent:
+		// 1 / (1 - u^2)
+		return binary(OperationDivide, number("1"), binary(OperationSubtract, number("1"), square))
+	case OperationSecant:
+		return binary(OperationMultiply, n, unary(OperationTangent, u))
+	case OperationCosecant:
+		return unary(OperationNegate, binary(OperationMultiply, n, unary(OperationCotangent, u)))
+	case OperationCotangent:
+		// -(1 + cot(u)^2)
+		return unary(OperationNegate, binary(OperationAdd, number("1"),
+			binary(OperationExponentiation, n, number("2"))))
+	case OperationFactorial:
+		// u! ψ(u + 1)
+		return binary(OperationMultiply, n, unary(OperationDigamma, binary(OperationAdd, u, number("1"))))
+	case OperationGamma:
+		return binary(OperationMultiply, n, unary(OperationDigamma, u))
+	case OperationLogGamma:
+		return unary(OperationDigamma, u)
+	case OperationErf, OperationErfc:
+		// 2 e^(-u^2) / sqrt(pi)
+		a := binary(OperationDivide,
+			binary(OperationMultiply, number("2"), unary(OperationNaturalExponentiation, unary(OperationNegate, square))),
+			unary(OperationSquareRoot, &Node{
+				Operation: OperationPI,
+			}))
+		if n.Operation == OperationErfc {
+			return unary(OperationNegate, a)
+		}
+		return a
+	case OperationZeta:
+		return binary(OperationZetaDerivative, u, number("1"))
+	case OperationDigamma:
+		return binary(OperationPolygamma, u, number("1"))
+	case OperationLambertW:
+		// e^(-W(u)) / (1 + W(u))
+		return binary(OperationDivide, unary(OperationNaturalExponentiation, unary(OperationNegate, n)),
+			binary(OperationAdd, number("1"), n))
+	case OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+		// the recurrences in the order, J' = (J_(v-1) - J_(v+1)) / 2 and the
+		// same for Y, I' = (I_(v-1) + I_(v+1)) / 2 and K' = -(K_(v-1) +
+		// K_(v+1)) / 2
+		lower := binary(n.Operation, u, binary(OperationSubtract, n.Right, number("1")))
+		upper := binary(n.Operation, u, binary(OperationAdd, n.Right, number("1")))
+		switch n.Operation {
+		case OperationBesselJ, OperationBesselY:
+			return binary(OperationDivide, binary(OperationSubtract, lower, upper), number("2"))
+		case OperationBesselI:
+			return binary(OperationDivide, binary(OperationAdd, lower, upper), number("2"))
+		}
+		return unary(OperationNegate, binary(OperationDivide, binary(OperationAdd, lower, upper), number("2")))
+	case OperationAiryAi:
+		return unary(OperationAiryAiPrime, u)
+	case OperationAiryBi:
+		return unary(OperationAiryBiPrime, u)
+	case OperationAiryAiPrime:
+		// Ai'' = u Ai
+		return binary(OperationMultiply, u, unary(OperationAiryAi, u))
+	case OperationAiryBiPrime:
+		return binary(OperationMultiply, u, unary(OperationAiryBi, u))
+	case OperationEllipticK:
+		// (E(u) - (1 - u) K(u)) / (2 u (1 - u))
+		complement := binary(OperationSubtract, number("1"), u)
+		return binary(OperationDivide,
+			binary(OperationSubtract, unary(OperationEllipticE, u), binary(OperationMultiply, complement, n)),
+			binary(OperationMultiply, binary(OperationMultiply, number("2"), u), complement))
+	case OperationEllipticE:
+		// (E(u) - K(u)) / (2 u)
+		return binary(OperationDivide, binary(OperationSubtract, n, unary(OperationEllipticK, u)),
+			binary(OperationMultiply, number("2"), u))
+	}
+	return nil
+}
+
+// newNumber converts a complex rational into an expression
+func newNumber(r *complex.Rational) *Node {
+	real := func(r *big.Rat, operation Operation) *Node {
+		a := &Node{
+			Operation: operation,
+			Value:     new(big.Int).Abs(r.Num()).String(),
+		}
+		if !r.IsInt() {
+			a = &Node{
+				Operation: OperationDivide,
+				Left:      a,
+				Right: &Node{
+					Operation: OperationNumber,
+					Value:     r.Denom().String(),
+				},
+			}
+		}
+		if r.Sign() < 0 {
+			a = &Node{
+				Operation: OperationNegate,
+				Left:      a,
+			}
+		}
+		return a
+	}
+	if r.B.Sign() == 0 {
+		return real(r.A, OperationNumber)
+	} else if r.A.Sign() == 0 {
+		return real(r.B, OperationImaginary)
+	}
+	return &Node{
+		Operation: OperationAdd,
+		Left:      real(r.A, OperationNumber),
+		Right:     real(r.B, OperationImaginary),
+	}
+}
+
+// number computes the value of a constant expression exactly, powers are
+// only computed for small integer exponents
+func number(n *Node) (*complex.Rational, bool) {
+	if n == nil {
+		return nil, false
+	}
+	zero := func() *complex.Rational {
+		return complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1))
+	}
+	switch n.Operation {
+	case OperationNumber, OperationImaginary:
+		a, ok := new(big.Rat).SetString(n.Value)
+		if !ok {
+			return nil, false
+		}
+		if n.Operation == OperationImaginary {
+			return complex.NewRational(big.NewRat(0, 1), a), true
+		}
+		return complex.NewRational(a, big.NewRat(0, 1)), true
+	case OperationNotation:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		b, ok := new(big.Int).SetString(n.Right.Value, 10)
+		if !ok || !b.IsInt64() || abs(b.Int64()) > maxExponent {
+			return nil, false
+		}
+		e := power10(b.Int64())
+		a.A.Mul(a.A, e)
+		a.B.Mul(a.B, e)
+		return a, true
+	case OperationNegate:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		a.A.Neg(a.A)
+		a.B.Neg(a.B)
+		return a, true
+	case OperationAdd, OperationSubtract, OperationMultiply, OperationDivide,
+		OperationModulus, OperationExponentiation:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		b, ok := number(n.Right)
+		if !ok {
+			return nil, false
+		}
+		switch n.Operation {
+		case OperationAdd:
+			a.Add(a, b)
+		case OperationSubtract:
+			a.Sub(a, b)
+		case OperationMultiply:
+			a.Mul(a, b)
+		case OperationDivide:
+			if isZero(b) {
+				return nil, false
+			}
+			a.Div(a, b)
+		case OperationModulus:
+			if !isInteger(a) || !isInteger(b) || isZero(b) {
+				return nil, false
+			}
+			a.A.SetInt(new(big.Int).Mod(a.A.Num(), b.A.Num()))
+		case OperationExponentiation:
+			switch {
+			case isZero(a) && isZero(b):
+				return complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1)), true
+			case isZero(a) && b.B.Sign() == 0 && b.A.Sign() > 0:
+				return zero(), true
+			case isZero(a) || b.B.Sign() != 0 || !b.A.Num().IsInt64() ||
+				abs(b.A.Num().Int64()) > maxExponent:
+				return nil, false
+			case !isInteger(b):
+				// a rational power is exact if the root is rational
+				if !b.A.Denom().IsInt64() || b.A.Denom().Int64() > maxRootDegree {
+					return nil, false
+				}
+				r, ok := root(a, b.A.Denom().Int64())
+				if !ok {
+					return nil, false
+				}
+				return integerPower(r, b.A.Num()), true
+			}
+			return integerPower(a, b.A.Num()), true
+		}
+		return a, true
+	case OperationSquareRoot:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		return root(a, 2)
+	case OperationFactorial, OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+		OperationZeta, OperationEllipticE:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		return exactValue(n.Operation, a)
+	case OperationLambertW:
+		// W(0) = 0 on the principal branch
+		a, ok := number(n.Left)
+		if !ok || !isZero(a) || !n.Right.Equals(0) {
+			return nil, false
+		}
+		return a, true
+	case OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		b, ok := number(n.Right)
+		if !ok {
+			return nil, false
+		}
+		return exactBessel(n.Operation, b, a)
+	case OperationBeta:
+		a, ok := number(n.Left)
+		if !ok {
+			return nil, false
+		}
+		b, ok := number(n.Right)
+		if !ok {
+			return nil, false
+		}
+		return exactBeta(a, b)
+	case OperationGCD, OperationLCM, OperationIsPrime, OperationNextPrime, OperationTotient,
+		OperationPowMod, OperationInvMod, OperationJacobi:
+		var arguments []*big.Int
+		for _, operand := range n.operands() {
+			a, ok := number(operand)
+			if !ok {
+				return nil, false
+			}
+			b, ok := integerOf(a)
+			if !ok {
+				return nil, false
+			}
+			arguments = append(arguments, b)
+		}
+		r, err := integerValue(n.Operation, arguments...)
+		if err != nil {
+			return nil, false
+		}
+		return realRational(new(big.Rat).SetInt(r)), true
+	case OperationZetaDerivative:
+		a, ok := number(n.Left)
+		if !ok || !n.Right.Equals(0) {
+			return nil, false
+		}
+		return exactZeta(a)
+	}
+	return nil, false
 }
 
 var numeric = map[Operation]bool{
@@ -374,10 +1371,79 @@ func isNumeric(operation Operation) bool {
 	return numeric[operation]
 }
 
-// Simplify simplifies an expression
+// undefined tests if the expression contains a division by zero which
+// evaluation would report, so that simplify doesn't remove it
+func undefined(n *Node) bool {
+	if n == nil {
+		return false
+	}
+	switch n.Operation {
+	case OperationDivide, OperationModulus:
+		if b, ok := number(n.Right); ok && isZero(b) {
+			return true
+		}
+	case OperationExponentiation:
+		// zero raised to a power with a non-positive real part
+		if a, ok := number(n.Left); ok && isZero(a) {
+			if b, ok := number(n.Right); ok && !isZero(b) && b.A.Sign() <= 0 {
+				return true
+			}
+		}
+	}
+	if undefined(n.Left) || undefined(n.Right) {
+		return true
+	}
+	for _, argument := range n.Arguments {
+		if undefined(argument) {
+			return true
+		}
+	}
+	for _, row := range n.Rows {
+		for _, element := range row {
+			if undefined(element) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Simplify simplifies an expression with the default rules
 func (n *Node) Simplify() *Node {
-	var process func(n *Node) *Node
+	return n.SimplifyWith(DefaultRules)
+}
+
+// simplify simplifies an expression without rewrite rules, the constant
+// sub-expressions are computed exactly and the result is in canonical form
+func (n *Node) simplify() *Node {
+	simplified := make(map[*Node]*Node)
+	var process, simplify func(n *Node) *Node
 	process = func(n *Node) *Node {
+		if a, ok := simplified[n]; ok {
+			return a
+		}
+		// the constant sub-expressions are computed before the identities
+		// for 0 and 1 are applied
+		if r, ok := number(n); ok {
+			a := newNumber(r)
+			simplified[n] = a
+			return a
+		}
+		a := simplify(n)
+		if a == nil {
+			return nil
+		}
+		switch a.Operation {
+		case OperationNumber, OperationImaginary, OperationVariable:
+		default:
+			if r, ok := number(a); ok {
+				a = newNumber(r)
+			}
+		}
+		simplified[n] = a
+		return a
+	}
+	simplify = func(n *Node) *Node {
 		if n == nil {
 			return nil
 		}
@@ -416,13 +1482,13 @@ func (n *Node) Simplify() *Node {
 			return a
 		case OperationMultiply:
 			left, right := process(n.Left), process(n.Right)
-			if isNumeric(left.Operation) && left.Equals(0) {
+			if isNumeric(left.Operation) && left.Equals(0) && !undefined(right) {
 				a := &Node{
 					Operation: OperationNumber,
 					Value:     "0",
 				}
 				return a
-			} else if isNumeric(right.Operation) && right.Equals(0) {
+			} else if isNumeric(right.Operation) && right.Equals(0) && !undefined(left) {
 				a := &Node{
 					Operation: OperationNumber,
 					Value:     "0",
@@ -441,18 +1507,13 @@ func (n *Node) Simplify() *Node {
 			return a
 		case OperationDivide:
 			left, right := process(n.Left), process(n.Right)
-			if isNumeric(left.Operation) && left.Equals(0) {
+			if isNumeric(left.Operation) && left.Equals(0) && !undefined(right) &&
+				!(isNumeric(right.Operation) && right.Equals(0)) {
 				a := &Node{
 					Operation: OperationNumber,
 					Value:     "0",
 				}
 				return a
-			} else if isNumeric(right.Operation) && right.Equals(0) {
-				a := &Node{
-					Operation: OperationNumber,
-					Value:     "+Inf",
-				}
-				return a
 			} else if isNumeric(right.Operation) && right.Equals(1) {
 				return left
 			}
@@ -464,8 +1525,13 @@ func (n *Node) Simplify() *Node {
 			return a
 		case OperationModulus:
 			left, right := process(n.Left), process(n.Right)
-			if isNumeric(right.Operation) && right.Equals(1) {
-				return left
+			if isNumeric(right.Operation) && right.Equals(1) && !undefined(left) {
+				// the operands of a modulus are integers
+				a := &Node{
+					Operation: OperationNumber,
+					Value:     "0",
+				}
+				return a
 			}
 			a := &Node{
 				Operation: OperationModulus,
@@ -474,20 +1540,15 @@ func (n *Node) Simplify() *Node {
 			}
 			return a
 		case OperationExponentiation:
+			// 0^x depends on the sign of the real part of x
 			left, right := process(n.Left), process(n.Right)
-			if isNumeric(left.Operation) && left.Equals(0) {
-				a := &Node{
-					Operation: OperationNumber,
-					Value:     "0",
-				}
-				return a
-			} else if isNumeric(right.Operation) && right.Equals(0) {
+			if isNumeric(right.Operation) && right.Equals(0) && !undefined(left) {
 				a := &Node{
 					Operation: OperationNumber,
 					Value:     "1",
 				}
 				return a
-			} else if isNumeric(left.Operation) && left.Equals(1) {
+			} else if isNumeric(left.Operation) && left.Equals(1) && !undefined(right) {
 				a := &Node{
 					Operation: OperationNumber,
 					Value:     "1",
@@ -534,8 +1595,7 @@ func (n *Node) Simplify() *Node {
 				return a
 			} else if isNumeric(left.Operation) && left.Equals(1) {
 				a := &Node{
-					Operation: OperationVariable,
-					Value:     "e",
+					Operation: OperationNatural,
 				}
 				return a
 			}
@@ -548,10 +1608,16 @@ func (n *Node) Simplify() *Node {
 			return n
 		case OperationPI:
 			return n
+		case OperationInfinity:
+			return n
 		case OperationNaturalLogarithm:
 			left := process(n.Left)
 			if left.Operation == OperationNatural {
-				return left
+				a := &Node{
+					Operation: OperationNumber,
+					Value:     "1",
+				}
+				return a
 			}
 			a := &Node{
 				Operation: OperationNaturalLogarithm,
@@ -596,8 +1662,316 @@ func (n *Node) Simplify() *Node {
 				Left:      process(n.Left),
 			}
 			return a
+		case OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent:
+			a := &Node{
+				Operation: n.Operation,
+				Left:      process(n.Left),
+			}
+			return a
+		case OperationArctangent2:
+			left, right := process(n.Left), process(n.Right)
+			y, oky := number(left)
+			x, okx := number(right)
+			if oky && okx && y.B.Sign() == 0 && x.B.Sign() == 0 && (x.A.Sign() != 0 || y.A.Sign() != 0) {
+				// the angle of a real point is the inverse tangent of y / x
+				// within the quadrant of the point
+				pi := &Node{
+					Operation: OperationPI,
+				}
+				switch {
+				case x.A.Sign() == 0:
+					a := &Node{
+						Operation: OperationDivide,
+						Left:      pi,
+						Right: &Node{
+							Operation: OperationNumber,
+							Value:     "2",
+						},
+					}
+					if y.A.Sign() < 0 {
+						return process(negate(a))
+					}
+					return process(a)
+				}
+				a := &Node{
+					Operation: OperationArctangent,
+					Left:      newNumber(complex.NewRational(new(big.Rat).Quo(y.A, x.A), new(big.Rat))),
+				}
+				switch {
+				case x.A.Sign() > 0:
+					return process(a)
+				case y.A.Sign() < 0:
+					return process(&Node{
+						Operation: OperationSubtract,
+						Left:      a,
+						Right:     pi,
+					})
+				}
+				return process(addition(a, pi))
+			}
+			a := &Node{
+				Operation: OperationArctangent2,
+				Left:      left,
+				Right:     right,
+			}
+			return a
+		case OperationLogarithm:
+			left, right := process(n.Left), process(n.Right)
+			if x, ok := rational(left); ok {
+				if b, ok := rational(right); ok {
+					if r, ok := rationalLogarithm(x, b); ok {
+						return newRational(r)
+					}
+				}
+			}
+			a := &Node{
+				Operation: OperationLogarithm,
+				Left:      left,
+				Right:     right,
+			}
+			return a
+		case OperationFactorial, OperationGamma:
+			left := process(n.Left)
+			if x, ok := number(left); ok {
+				if n.Operation == OperationFactorial {
+					x.A.Add(x.A, big.NewRat(1, 1))
+				}
+				if r, ok := halfGamma(x); ok {
+					a := &Node{
+						Operation: OperationMultiply,
+						Left:      newRational(r),
+						Right: &Node{
+							Operation: OperationSquareRoot,
+							Left: &Node{
+								Operation: OperationPI,
+							},
+						},
+					}
+					return process(a)
+				}
+			}
+			a := &Node{
+				Operation: n.Operation,
+				Left:      left,
+			}
+			return a
+		case OperationZeta:
+			left := process(n.Left)
+			if x, ok := number(left); ok {
+				if r, ok := evenZeta(x); ok {
+					a := &Node{
+						Operation: OperationMultiply,
+						Left:      newRational(r),
+						Right: &Node{
+							Operation: OperationExponentiation,
+							Left: &Node{
+								Operation: OperationPI,
+							},
+							Right: left,
+						},
+					}
+					return process(a)
+				}
+			}
+			a := &Node{
+				Operation: OperationZeta,
+				Left:      left,
+			}
+			return a
+		case OperationLogGamma, OperationErf, OperationErfc, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			a := &Node{
+				Operation: n.Operation,
+				Left:      process(n.Left),
+			}
+			return a
+		case OperationPowMod:
+			a := &Node{
+				Operation: OperationPowMod,
+			}
+			for _, argument := range n.Arguments {
+				a.Arguments = append(a.Arguments, process(argument))
+			}
+			return a
+		case OperationBeta, OperationLambertW,
+			OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK,
+			OperationGCD, OperationLCM, OperationInvMod, OperationCRT, OperationJacobi:
+			a := &Node{
+				Operation: n.Operation,
+				Left:      process(n.Left),
+				Right:     process(n.Right),
+			}
+			return a
+		case OperationZetaDerivative, OperationPolygamma:
+			left, right := process(n.Left), process(n.Right)
+			if isNumeric(right.Operation) && right.Equals(0) {
+				// the derivative of order zero is the function
+				operation := OperationZeta
+				if n.Operation == OperationPolygamma {
+					operation = OperationDigamma
+				}
+				return process(&Node{
+					Operation: operation,
+					Left:      left,
+				})
+			}
+			a := &Node{
+				Operation: n.Operation,
+				Left:      left,
+				Right:     right,
+			}
+			return a
+		case OperationCall:
+			a := &Node{
+				Operation: OperationCall,
+				Value:     n.Value,
+			}
+			for _, argument := range n.Arguments {
+				a.Arguments = append(a.Arguments, process(argument))
+			}
+			return a
+		case OperationMatrix:
+			a := &Node{
+				Operation: OperationMatrix,
+				Rows:      make([][]*Node, len(n.Rows)),
+			}
+			for i, row := range n.Rows {
+				for _, element := range row {
+					a.Rows[i] = append(a.Rows[i], process(element))
+				}
+			}
+			return a
+		case OperationIntegral:
+			a := &Node{
+				Operation: OperationIntegral,
+				Left:      process(n.Left),
+				Right:     n.Right,
+			}
+			return a
 		}
 		return nil
 	}
+	a := process(n)
+	if undefined(a) {
+		// the canonical form would cancel the division by zero
+		return a
+	}
+	return a.canonical()
+}
+
+// Substitute replaces the variables in the expression with the expressions
+// they are bound to
+func (n *Node) Substitute(bindings map[string]*Node) *Node {
+	substituted := make(map[*Node]*Node)
+	var process, substitute func(n *Node) *Node
+	process = func(n *Node) *Node {
+		if n == nil {
+			return nil
+		} else if a, ok := substituted[n]; ok {
+			return a
+		}
+		a := substitute(n)
+		substituted[n] = a
+		return a
+	}
+	substitute = func(n *Node) *Node {
+		if n.Operation == OperationVariable {
+			if a, ok := bindings[n.Value]; ok {
+				return a
+			}
+			return n
+		} else if n.Operation == OperationIntegral {
+			// the variable of integration is bound
+			if _, ok := bindings[n.Right.Value]; ok {
+				inner := make(map[string]*Node, len(bindings))
+				for name, binding := range bindings {
+					inner[name] = binding
+				}
+				delete(inner, n.Right.Value)
+				a := *n
+				a.Left = n.Left.Substitute(inner)
+				return &a
+			}
+		}
+		a := *n
+		a.Left, a.Right = process(n.Left), process(n.Right)
+		if n.Arguments != nil {
+			a.Arguments = make([]*Node, len(n.Arguments))
+			for i, argument := range n.Arguments {
+				a.Arguments[i] = process(argument)
+			}
+		}
+		if n.Rows != nil {
+			a.Rows = make([][]*Node, len(n.Rows))
+			for i, row := range n.Rows {
+				a.Rows[i] = make([]*Node, len(row))
+				for j, element := range row {
+					a.Rows[i][j] = process(element)
+				}
+			}
+		}
+		return &a
+	}
 	return process(n)
 }
+
+// Inline replaces calls to user defined functions with the bodies of the
+// functions, which is required before the derivative is taken
+func (n *Node) Inline(env *Environment) (*Node, error) {
+	var process func(n *Node, depth int) (*Node, error)
+	process = func(n *Node, depth int) (*Node, error) {
+		if n == nil {
+			return nil, nil
+		}
+		if n.Operation == OperationCall {
+			function, err := env.function(n.Value, len(n.Arguments), depth)
+			if err != nil {
+				err.(*Error).Text = n.String()
+				return nil, err
+			}
+			bindings := make(map[string]*Node, len(function.Parameters))
+			for i, parameter := range function.Parameters {
+				argument, err := process(n.Arguments[i], depth)
+				if err != nil {
+					return nil, err
+				}
+				bindings[parameter] = argument
+			}
+			return process(function.Body.Substitute(bindings), depth+1)
+		}
+		var err error
+		a := *n
+		if a.Left, err = process(n.Left, depth); err != nil {
+			return nil, err
+		}
+		if a.Right, err = process(n.Right, depth); err != nil {
+			return nil, err
+		}
+		if n.Arguments != nil {
+			a.Arguments = make([]*Node, len(n.Arguments))
+			for i, argument := range n.Arguments {
+				if a.Arguments[i], err = process(argument, depth); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if n.Rows != nil {
+			a.Rows = make([][]*Node, len(n.Rows))
+			for i, row := range n.Rows {
+				a.Rows[i] = make([]*Node, len(row))
+				for j, element := range row {
+					if a.Rows[i][j], err = process(element, depth); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		return &a, nil
+	}
+	return process(n, 0)
+}
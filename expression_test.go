@@ -0,0 +1,192 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+func TestDerivativeWith(t *testing.T) {
+	run(t, []test{
+		{"derivative(x*y, x)", "y"},
+		{"derivative(x*y, y)", "x"},
+		{"derivative(x*y, z)", "0"},
+		{"derivative(x^2)", "2 * x"},
+		{"derivative(5)", "0"},
+		{"derivative(sin(x)*y^2, y)", "2 * y * sin(x)"},
+		{"derivative(exp(x*y), x)", "y * e^(x * y)"},
+	})
+	runErrors(t, []errorTest{
+		{"derivative(x*y)", ErrorTypeAmbiguous, 0, 15},
+		// the built in names aren't variables
+		{"derivative(e^y, e)", ErrorTypeValue, 16, 17},
+		{"derivative(sin(y), sin)", ErrorTypeValue, 19, 22},
+	})
+
+	env := NewEnvironment()
+	for name, value := range map[string]int64{"x": 2, "y": 3} {
+		env.Set(name, Value{ValueType: ValueTypeMatrix, Matrix: newScalar(DefaultPrec, complex.NewRational(big.NewRat(value, 1), new(big.Rat)))})
+	}
+	tests := []test{
+		{"x^2", "4"},
+		{"5", "0"},
+		// each of the variables has a derivative of one
+		{"x*y", "5"},
+	}
+	for _, test := range tests {
+		n := parse(t, test.expression)
+		a, err := n.Derivative().Evaluate(env, DefaultPrec)
+		if err != nil {
+			t.Errorf("the derivative of %s: %v", test.expression, err)
+		} else if a.String() != test.result {
+			t.Errorf("the derivative of %s at x=2, y=3 = %s, want %s", test.expression, a, test.result)
+		}
+	}
+	if a, err := parse(t, "x^2").DerivativeE(); err != nil || a.String() != parse(t, "x^2").DerivativeWith("x").String() {
+		t.Errorf("DerivativeE of x^2 = %v, %v", a, err)
+	}
+	if _, err := parse(t, "x*y").DerivativeE(); err == nil || err.(*Error).ErrorType != ErrorTypeAmbiguous {
+		t.Errorf("DerivativeE of x*y = %v, want an ambiguous variable", err)
+	}
+}
+
+// TestSimplifyEval checks that simplify agrees with evaluation, including the
+// expressions which evaluation rejects
+func TestSimplifyEval(t *testing.T) {
+	expressions := []string{
+		"2*3 + 4",
+		"1/3 + 1/6",
+		"(1 + 2i)*(3 - 1i)",
+		"1.5e2 - 50",
+		"2^-3",
+		"8^(1/3)",
+		"(-8)^(1/3)",
+		"0^0",
+		"0^2",
+		"0^(1 + 1i)",
+		"7 % 3",
+		"sqrt(16/9)",
+		"0*5",
+		"0/0",
+		"1/0",
+		"0*(1/0)",
+		"0^-1",
+		"0^(1i)",
+		"(1/0)^0",
+		"1^(1/0)",
+		"0 % 0",
+		"(2/0) % 1",
+	}
+	for _, expression := range expressions {
+		want, err := evaluate(NewEnvironment(), expression)
+		simplified, serr := evaluate(NewEnvironment(), "simplify("+expression+")")
+		if serr != nil {
+			t.Errorf("simplify(%s): %v", expression, serr)
+			continue
+		}
+		got, gerr := simplified.Expression.Evaluate(nil, DefaultPrec)
+		if err != nil || gerr != nil {
+			if err == nil || gerr == nil || err.(*Error).ErrorType != gerr.(*Error).ErrorType {
+				t.Errorf("simplify(%s) = %s: %v, want %v", expression, simplified.Expression, gerr, err)
+			}
+			continue
+		}
+		if got.String() != format(want) {
+			t.Errorf("simplify(%s) = %s = %s, want %s", expression, simplified.Expression, got, format(want))
+		}
+	}
+
+	run(t, []test{
+		{"simplify(2*3 + x)", "x + 6"},
+		{"simplify(0^0)", "1"},
+		{"simplify(0/0)", "0 / 0"},
+		{"simplify(0*(1/0))", "0 * (1 / 0)"},
+		{"simplify(0*x)", "0"},
+		{"simplify(x^0)", "1"},
+		{"simplify(0^x)", "0^x"},
+		{"simplify(x % 1)", "0"},
+		{"simplify(1i*1i + x)", "x - 1"},
+		// e is the constant and not a variable
+		{"simplify(exp(1))", "e"},
+		{"eval(simplify(exp(1)))", "2.718281828"},
+		{"derivative(simplify(exp(1))*x)", "e"},
+		{"solve(x - simplify(exp(1)), x, 1)", "2.718281828"},
+		{"integrate(simplify(exp(1)), x)", "e * x"},
+	})
+}
+
+// TestStringParse checks that the string form of an expression parses to an
+// identical tree
+func TestStringParse(t *testing.T) {
+	tests := []test{
+		{"x^2 + 2*x", "x^2 + 2 * x"},
+		{"(x^2) + (2*x)", "x^2 + 2 * x"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a - b) - c", "a - b - c"},
+		{"a/(b*c)", "a / (b * c)"},
+		{"(a/b)/c", "a / b / c"},
+		{"a/b*c", "a / b * c"},
+		{"(a^b)^c", "(a^b)^c"},
+		{"(e^a)^b", "(e^a)^b"},
+		{"a^(b^c)", "a^(b^c)"},
+		{"-(x)", "-x"},
+		{"-(x + 1)", "-(x + 1)"},
+		{"-(-x)", "-(-x)"},
+		{"(-x)^2", "(-x)^2"},
+		{"2^-x", "2^-x"},
+		{"a % (b % c)", "a % (b % c)"},
+		{"1.5e-3*x", "1.5e-3 * x"},
+		{"2i*x + 3i", "2i * x + 3i"},
+		{"sin(x)^2 + cos(-x)", "sin(x)^2 + cos(-x)"},
+		{"log(x, 3) + log(x, 2) + atan2(y, x)", "log(x, 3) + log2(x) + atan2(y, x)"},
+		{"besselj(2, x) + lambertw(x, -1)", "besselj(2, x) + lambertw(x, -1)"},
+		{"powmod(a, b, 7) + gcd(a, b)", "powmod(a, b, 7) + gcd(a, b)"},
+		{"x! + gamma(x)", "x! + gamma(x)"},
+		{"(x!)!", "(x!)!"},
+		{"[x 1; -y 2]", "[x 1;(-y) 2]"},
+		{"e^x + pi", "e^x + pi"},
+	}
+	for _, test := range tests {
+		n := parse(t, test.expression)
+		s := n.String()
+		if s != test.result {
+			t.Errorf("%s prints as %s, want %s", test.expression, s, test.result)
+		}
+		if m := parse(t, s); !m.Identical(n) {
+			t.Errorf("%s prints as %s which parses as %s", test.expression, s, m)
+		}
+	}
+}
+
+// TestResultParse checks that the string forms of computed expressions parse
+// to identical trees
+func TestResultParse(t *testing.T) {
+	expressions := []string{
+		"derivative(x^x)",
+		"derivative(atan2(y, x), x)",
+		"derivative(sqrt(1 - x^2))",
+		"integrate(x*exp(-x), x)",
+		"series(exp(x), x, 0, 4)",
+		"series(1/sin(x), x, 0, 3)",
+		"expand((1 - x/2)^3)",
+		"simplify(-1/2*x - 3)",
+		"simplify(x - (2 + 3i)*y)",
+		"hessian(x^3*y - y/x, [x; y])",
+	}
+	for _, expression := range expressions {
+		value, err := evaluate(NewEnvironment(), expression)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		s := value.Expression.String()
+		if m := parse(t, s); !m.Identical(value.Expression) {
+			t.Errorf("%s = %s which parses as %s", expression, s, m)
+		}
+	}
+}
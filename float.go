@@ -0,0 +1,532 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/ALTree/bigfloat"
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// guard is the number of extra bits used by the elementary functions
+const guard = 64
+
+// halvings is the number of times an argument is halved before a series is summed
+const halvings = 12
+
+// newFloat creates a float at precision prec
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+// naturalLog2 computes ln 2 = 2 atanh(1/3)
+func naturalLog2(prec uint) *big.Float {
+	wp := prec + guard
+	a := newFloat(wp).Mul(big.NewFloat(2), atanhInverse(3, wp))
+	return newFloat(prec).Set(a)
+}
+
+// constant returns ln 2 and pi at precision prec from the caches reserved by
+// reservePI, the caller must hold a read lock on piLock
+func constant(prec uint) (ln2, pi *big.Float) {
+	reservePI(prec)
+	return newFloat(prec).Set(ln2Cache), bigfloat.PI(prec)
+}
+
+// atanhInverse computes atanh(1/n) with the Taylor series
+func atanhInverse(n int64, prec uint) *big.Float {
+	x := newFloat(prec).Quo(big.NewFloat(1), newFloat(prec).SetInt64(n))
+	square := newFloat(prec).Mul(x, x)
+	sum, term := newFloat(prec).Set(x), newFloat(prec).Set(x)
+	a := newFloat(prec)
+	for k := int64(1); ; k++ {
+		term.Mul(term, square)
+		a.Quo(term, newFloat(prec).SetInt64(2*k+1))
+		if a.Sign() == 0 || a.MantExp(nil) < sum.MantExp(nil)-int(prec) {
+			break
+		}
+		sum.Add(sum, a)
+	}
+	return sum
+}
+
+// exponent returns the binary exponent of x
+func exponent(x *big.Float) int {
+	if x.Sign() == 0 {
+		return math.MinInt32
+	}
+	return x.MantExp(nil)
+}
+
+// positive returns e if it is positive and zero otherwise
+func positive(e int) uint {
+	if e < 0 {
+		return 0
+	}
+	return uint(e)
+}
+
+// expFloat computes e^x
+func expFloat(x *big.Float, prec uint) *big.Float {
+	if x.Sign() == 0 {
+		return newFloat(prec).SetInt64(1)
+	} else if x.IsInf() || exponent(x) > 32 {
+		if x.Sign() < 0 {
+			return newFloat(prec)
+		}
+		return newFloat(prec).SetInf(false)
+	}
+	// x = k ln 2 + r
+	wp := prec + guard + halvings + positive(exponent(x))
+	ln2, _ := constant(wp)
+	f, _ := newFloat(53).Quo(x, ln2).Float64()
+	k := int64(math.Round(f))
+	r := newFloat(wp).Mul(ln2, newFloat(wp).SetInt64(k))
+	r.Sub(newFloat(wp).Set(x), r)
+	r.SetMantExp(r, -halvings)
+
+	sum, term := newFloat(wp).SetInt64(1), newFloat(wp).SetInt64(1)
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, newFloat(wp).SetInt64(i))
+		if term.Sign() == 0 || exponent(term) < -int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for i := 0; i < halvings; i++ {
+		sum.Mul(sum, sum)
+	}
+	sum.SetMantExp(sum, int(k))
+	return newFloat(prec).Set(sum)
+}
+
+// logFloat computes the natural logarithm of x which must be positive
+func logFloat(x *big.Float, prec uint) *big.Float {
+	if x.Cmp(big.NewFloat(1)) == 0 {
+		return newFloat(prec)
+	}
+	// x = m 2^e
+	wp := prec + guard + halvings
+	m := newFloat(wp)
+	e := x.MantExp(m)
+	for i := 0; i < halvings; i++ {
+		m.Sqrt(m)
+	}
+	// log(m) = 2 atanh((m - 1)/(m + 1))
+	z := newFloat(wp).Sub(m, big.NewFloat(1))
+	z.Quo(z, newFloat(wp).Add(m, big.NewFloat(1)))
+	square := newFloat(wp).Mul(z, z)
+	sum, term := newFloat(wp).Set(z), newFloat(wp).Set(z)
+	a := newFloat(wp)
+	for k := int64(1); z.Sign() != 0; k++ {
+		term.Mul(term, square)
+		a.Quo(term, newFloat(wp).SetInt64(2*k+1))
+		if a.Sign() == 0 || exponent(a) < exponent(sum)-int(wp) {
+			break
+		}
+		sum.Add(sum, a)
+	}
+	sum.SetMantExp(sum, halvings+1)
+	ln2, _ := constant(wp)
+	ln2.Mul(ln2, newFloat(wp).SetInt64(int64(e)))
+	return newFloat(prec).Add(sum, ln2)
+}
+
+// sinCosFloat computes the sine and cosine of x
+func sinCosFloat(x *big.Float, prec uint) (sin, cos *big.Float) {
+	if x.Sign() == 0 {
+		return newFloat(prec), newFloat(prec).SetInt64(1)
+	}
+	// x = q pi/2 + r
+	wp := prec + guard + halvings + positive(exponent(x))
+	_, pi := constant(wp)
+	pi.SetMantExp(pi, -1)
+	q := newFloat(wp).Quo(x, pi)
+	n, _ := q.Int(nil)
+	if q.Sub(q, newFloat(wp).SetInt(n)); q.Cmp(big.NewFloat(.5)) > 0 {
+		n.Add(n, big.NewInt(1))
+	} else if q.Cmp(big.NewFloat(-.5)) < 0 {
+		n.Sub(n, big.NewInt(1))
+	}
+	r := newFloat(wp).Mul(pi, newFloat(wp).SetInt(n))
+	r.Sub(newFloat(wp).Set(x), r)
+	r.SetMantExp(r, -halvings)
+
+	square := newFloat(wp).Mul(r, r)
+	s, term := newFloat(wp).Set(r), newFloat(wp).Set(r)
+	for k := int64(1); r.Sign() != 0; k++ {
+		term.Mul(term, square)
+		term.Quo(term, newFloat(wp).SetInt64(2*k*(2*k+1)))
+		term.Neg(term)
+		if term.Sign() == 0 || exponent(term) < exponent(s)-int(wp) {
+			break
+		}
+		s.Add(s, term)
+	}
+	c := newFloat(wp).Mul(s, s)
+	c.Sub(big.NewFloat(1), c)
+	c.Sqrt(c)
+	for i := 0; i < halvings; i++ {
+		// sin 2a = 2 sin a cos a, cos 2a = 1 - 2 sin^2 a
+		a := newFloat(wp).Mul(s, s)
+		s.Mul(s, c)
+		s.SetMantExp(s, 1)
+		c.SetMantExp(a, 1)
+		c.Sub(big.NewFloat(1), c)
+	}
+
+	switch new(big.Int).And(n, big.NewInt(3)).Int64() {
+	case 1:
+		s, c = c, s.Neg(s)
+	case 2:
+		s, c = s.Neg(s), c.Neg(c)
+	case 3:
+		s, c = c.Neg(c), s
+	}
+	return newFloat(prec).Set(s), newFloat(prec).Set(c)
+}
+
+// sinhCoshFloat computes the hyperbolic sine and cosine of x
+func sinhCoshFloat(x *big.Float, prec uint) (sinh, cosh *big.Float) {
+	if x.Sign() == 0 {
+		return newFloat(prec), newFloat(prec).SetInt64(1)
+	}
+	// e^x - e^-x cancels for small x
+	wp := prec + guard
+	if e := exponent(x); e < 0 {
+		wp += uint(-e)
+	}
+	a := expFloat(x, wp)
+	b := newFloat(wp).Quo(big.NewFloat(1), a)
+	sinh, cosh = newFloat(wp).Sub(a, b), newFloat(wp).Add(a, b)
+	sinh.SetMantExp(sinh, -1)
+	cosh.SetMantExp(cosh, -1)
+	return newFloat(prec).Set(sinh), newFloat(prec).Set(cosh)
+}
+
+// atanFloat computes the arctangent of x
+func atanFloat(x *big.Float, prec uint) *big.Float {
+	wp := prec + guard + halvings
+	if x.Sign() == 0 {
+		return newFloat(prec)
+	} else if x.Sign() < 0 {
+		a := atanFloat(newFloat(wp).Neg(x), prec)
+		return a.Neg(a)
+	} else if x.Cmp(big.NewFloat(1)) > 0 {
+		_, pi := constant(wp)
+		pi.SetMantExp(pi, -1)
+		return newFloat(prec).Sub(pi, atanFloat(newFloat(wp).Quo(big.NewFloat(1), x), wp))
+	}
+	// atan x = 2 atan(x / (1 + sqrt(1 + x^2)))
+	z := newFloat(wp).Set(x)
+	for i := 0; i < halvings; i++ {
+		a := newFloat(wp).Mul(z, z)
+		a.Add(a, big.NewFloat(1))
+		a.Sqrt(a)
+		a.Add(a, big.NewFloat(1))
+		z.Quo(z, a)
+	}
+	square := newFloat(wp).Mul(z, z)
+	sum, term := newFloat(wp).Set(z), newFloat(wp).Set(z)
+	a := newFloat(wp)
+	for k := int64(1); ; k++ {
+		term.Mul(term, square)
+		term.Neg(term)
+		a.Quo(term, newFloat(wp).SetInt64(2*k+1))
+		if a.Sign() == 0 || exponent(a) < exponent(sum)-int(wp) {
+			break
+		}
+		sum.Add(sum, a)
+	}
+	sum.SetMantExp(sum, halvings)
+	return newFloat(prec).Set(sum)
+}
+
+// atan2Float computes the angle of the point (x, y)
+func atan2Float(y, x *big.Float, prec uint) *big.Float {
+	wp := prec + guard
+	_, pi := constant(wp)
+	switch {
+	case x.Sign() == 0 && y.Sign() == 0:
+		return newFloat(prec)
+	case x.Sign() == 0:
+		pi.SetMantExp(pi, -1)
+		if y.Sign() < 0 {
+			pi.Neg(pi)
+		}
+		return newFloat(prec).Set(pi)
+	}
+	a := atanFloat(newFloat(wp).Quo(y, x), wp)
+	if x.Sign() < 0 {
+		if y.Sign() < 0 {
+			a.Sub(a, pi)
+		} else {
+			a.Add(a, pi)
+		}
+	}
+	return newFloat(prec).Set(a)
+}
+
+// newComplex creates a complex float at precision prec
+func newComplex(prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec), newFloat(prec))
+}
+
+// cexp computes e^x
+func cexp(x *complex.Float, prec uint) *complex.Float {
+	e := expFloat(x.A, prec+guard)
+	sin, cos := sinCosFloat(x.B, prec+guard)
+	return complex.NewFloat(newFloat(prec).Mul(e, cos), newFloat(prec).Mul(e, sin))
+}
+
+// clog computes the principal natural logarithm of x which must not be zero
+func clog(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	a := newFloat(wp).Mul(x.A, x.A)
+	a.Add(a, newFloat(wp).Mul(x.B, x.B))
+	a = logFloat(a, wp)
+	a.SetMantExp(a, -1)
+	return complex.NewFloat(newFloat(prec).Set(a), atan2Float(x.B, x.A, prec))
+}
+
+// csin computes the sine of x
+func csin(x *complex.Float, prec uint) *complex.Float {
+	sin, cos := sinCosFloat(x.A, prec+guard)
+	sinh, cosh := sinhCoshFloat(x.B, prec+guard)
+	return complex.NewFloat(newFloat(prec).Mul(sin, cosh), newFloat(prec).Mul(cos, sinh))
+}
+
+// ccos computes the cosine of x
+func ccos(x *complex.Float, prec uint) *complex.Float {
+	sin, cos := sinCosFloat(x.A, prec+guard)
+	sinh, cosh := sinhCoshFloat(x.B, prec+guard)
+	b := newFloat(prec).Mul(sin, sinh)
+	return complex.NewFloat(newFloat(prec).Mul(cos, cosh), b.Neg(b))
+}
+
+// ctan computes the tangent of x
+func ctan(x *complex.Float, prec uint) *complex.Float {
+	return cquo(csin(x, prec+guard), ccos(x, prec+guard), prec)
+}
+
+// cquo computes x / y
+func cquo(x, y *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	d := newFloat(wp).Mul(y.A, y.A)
+	d.Add(d, newFloat(wp).Mul(y.B, y.B))
+	a := newFloat(wp).Mul(x.A, y.A)
+	a.Add(a, newFloat(wp).Mul(x.B, y.B))
+	b := newFloat(wp).Mul(x.B, y.A)
+	b.Sub(b, newFloat(wp).Mul(x.A, y.B))
+	return complex.NewFloat(newFloat(prec).Quo(a, d), newFloat(prec).Quo(b, d))
+}
+
+// cmul computes x * y
+func cmul(x, y *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	a := newFloat(wp).Mul(x.A, y.A)
+	a.Sub(a, newFloat(wp).Mul(x.B, y.B))
+	b := newFloat(wp).Mul(x.A, y.B)
+	b.Add(b, newFloat(wp).Mul(x.B, y.A))
+	return complex.NewFloat(newFloat(prec).Set(a), newFloat(prec).Set(b))
+}
+
+// csqrt computes the principal square root of x
+func csqrt(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	if x.B.Sign() == 0 {
+		a := newFloat(wp).Abs(x.A)
+		a.Sqrt(a)
+		if x.A.Sign() < 0 {
+			return complex.NewFloat(newFloat(prec), newFloat(prec).Set(a))
+		}
+		return complex.NewFloat(newFloat(prec).Set(a), newFloat(prec))
+	}
+	// sqrt(x) = sqrt((|x| + a)/2) + i sign(b) sqrt((|x| - a)/2)
+	l := newFloat(wp).Mul(x.A, x.A)
+	l.Add(l, newFloat(wp).Mul(x.B, x.B))
+	l.Sqrt(l)
+	a := newFloat(wp).Add(l, x.A)
+	a.SetMantExp(a, -1)
+	a.Sqrt(a)
+	b := newFloat(wp).Sub(l, x.A)
+	b.SetMantExp(b, -1)
+	b.Sqrt(b)
+	if x.B.Sign() < 0 {
+		b.Neg(b)
+	}
+	return complex.NewFloat(newFloat(prec).Set(a), newFloat(prec).Set(b))
+}
+
+// cpow computes the principal value of x^y, x must not be zero
+func cpow(x, y *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	return cexp(cmul(y, clog(x, wp), wp), prec)
+}
+
+// toFloat converts a complex rational into a complex float
+func toFloat(r *complex.Rational, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).SetRat(r.A), newFloat(prec).SetRat(r.B))
+}
+
+// toRational converts a complex float into a complex rational
+func toRational(f *complex.Float) *complex.Rational {
+	r := complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1))
+	f.A.Rat(r.A)
+	f.B.Rat(r.B)
+	return r
+}
+
+// asinhFloat computes the inverse hyperbolic sine of x
+func asinhFloat(x *big.Float, prec uint) *big.Float {
+	if x.Sign() == 0 {
+		return newFloat(prec)
+	} else if x.Sign() < 0 {
+		a := asinhFloat(newFloat(x.Prec()).Neg(x), prec)
+		return a.Neg(a)
+	}
+	// asinh x = log(x + sqrt(x^2 + 1)), the logarithm of a number near one
+	// loses the bits of small x
+	wp := prec + guard + positive(-exponent(x))
+	a := newFloat(wp).Mul(x, x)
+	a.Add(a, big.NewFloat(1))
+	a.Sqrt(a)
+	a.Add(a, x)
+	return logFloat(a, prec)
+}
+
+// cexponent returns the larger binary exponent of the parts of x, or zero if
+// x is zero
+func cexponent(x *complex.Float) int {
+	a, b := exponent(x.A), exponent(x.B)
+	if b > a {
+		a = b
+	}
+	if a == math.MinInt32 {
+		return 0
+	}
+	return a
+}
+
+// csinh computes the hyperbolic sine of x
+func csinh(x *complex.Float, prec uint) *complex.Float {
+	sin, cos := sinCosFloat(x.B, prec+guard)
+	sinh, cosh := sinhCoshFloat(x.A, prec+guard)
+	return complex.NewFloat(newFloat(prec).Mul(sinh, cos), newFloat(prec).Mul(cosh, sin))
+}
+
+// ccosh computes the hyperbolic cosine of x
+func ccosh(x *complex.Float, prec uint) *complex.Float {
+	sin, cos := sinCosFloat(x.B, prec+guard)
+	sinh, cosh := sinhCoshFloat(x.A, prec+guard)
+	return complex.NewFloat(newFloat(prec).Mul(cosh, cos), newFloat(prec).Mul(sinh, sin))
+}
+
+// ctanh computes the hyperbolic tangent of x
+func ctanh(x *complex.Float, prec uint) *complex.Float {
+	return cquo(csinh(x, prec+guard), ccosh(x, prec+guard), prec)
+}
+
+// csec computes the secant of x
+func csec(x *complex.Float, prec uint) *complex.Float {
+	one := complex.NewFloat(big.NewFloat(1), newFloat(prec))
+	return cquo(one, ccos(x, prec+guard), prec)
+}
+
+// ccsc computes the cosecant of x which must not be zero
+func ccsc(x *complex.Float, prec uint) *complex.Float {
+	one := complex.NewFloat(big.NewFloat(1), newFloat(prec))
+	return cquo(one, csin(x, prec+guard), prec)
+}
+
+// ccot computes the cotangent of x which must not be zero
+func ccot(x *complex.Float, prec uint) *complex.Float {
+	return cquo(ccos(x, prec+guard), csin(x, prec+guard), prec)
+}
+
+// casin computes the principal inverse sine of x with Kahan's formulas, the
+// branch cuts are the real numbers less than -1 and greater than 1. The values
+// on the branch cuts of the inverse functions are continuous with the values
+// counterclockwise around the branch points.
+func casin(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	one := big.NewFloat(1)
+	s := csqrt(complex.NewFloat(newFloat(wp).Sub(one, x.A), newFloat(wp).Neg(x.B)), wp)
+	t := csqrt(complex.NewFloat(newFloat(wp).Add(one, x.A), newFloat(wp).Set(x.B)), wp)
+	// asin x = atan(Re x / Re(s t)) + i asinh(Im(conj(s) t))
+	a := newFloat(wp).Mul(s.A, t.A)
+	a.Sub(a, newFloat(wp).Mul(s.B, t.B))
+	b := newFloat(wp).Mul(s.A, t.B)
+	b.Sub(b, newFloat(wp).Mul(s.B, t.A))
+	return complex.NewFloat(atan2Float(x.A, a, prec), asinhFloat(b, prec))
+}
+
+// cacos computes the principal inverse cosine of x with Kahan's formulas, the
+// branch cuts are the real numbers less than -1 and greater than 1
+func cacos(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	one := big.NewFloat(1)
+	s := csqrt(complex.NewFloat(newFloat(wp).Sub(one, x.A), newFloat(wp).Neg(x.B)), wp)
+	t := csqrt(complex.NewFloat(newFloat(wp).Add(one, x.A), newFloat(wp).Set(x.B)), wp)
+	// acos x = 2 atan(Re s / Re t) + i asinh(Im(conj(t) s))
+	a := atan2Float(s.A, t.A, wp)
+	a.SetMantExp(a, 1)
+	b := newFloat(wp).Mul(t.A, s.B)
+	b.Sub(b, newFloat(wp).Mul(t.B, s.A))
+	return complex.NewFloat(newFloat(prec).Set(a), asinhFloat(b, prec))
+}
+
+// catanh computes the principal inverse hyperbolic tangent of x which must not
+// be 1 or -1, the branch cuts are the real numbers less than -1 and greater
+// than 1
+func catanh(x *complex.Float, prec uint) *complex.Float {
+	// the logarithms cancel for small and large x
+	e := cexponent(x)
+	if e < 0 {
+		e = -e
+	}
+	wp := prec + guard + 2*uint(e)
+	one := big.NewFloat(1)
+	// atanh x = (log(1 + x) - log(1 - x))/2
+	a := clog(complex.NewFloat(newFloat(wp).Add(one, x.A), newFloat(wp).Set(x.B)), wp)
+	b := clog(complex.NewFloat(newFloat(wp).Sub(one, x.A), newFloat(wp).Neg(x.B)), wp)
+	a.A.Sub(a.A, b.A)
+	a.B.Sub(a.B, b.B)
+	a.A.SetMantExp(a.A, -1)
+	a.B.SetMantExp(a.B, -1)
+	return complex.NewFloat(newFloat(prec).Set(a.A), newFloat(prec).Set(a.B))
+}
+
+// catan computes the principal inverse tangent of x which must not be i or
+// -i, atan x = -i atanh(i x)
+func catan(x *complex.Float, prec uint) *complex.Float {
+	a := catanh(complex.NewFloat(newFloat(x.B.Prec()).Neg(x.B), x.A), prec)
+	return complex.NewFloat(a.B, a.A.Neg(a.A))
+}
+
+// casinh computes the principal inverse hyperbolic sine of x, asinh x =
+// -i asin(i x)
+func casinh(x *complex.Float, prec uint) *complex.Float {
+	a := casin(complex.NewFloat(newFloat(x.B.Prec()).Neg(x.B), x.A), prec)
+	return complex.NewFloat(a.B, a.A.Neg(a.A))
+}
+
+// cacosh computes the principal inverse hyperbolic cosine of x with Kahan's
+// formulas, the branch cut is the real numbers less than 1
+func cacosh(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	one := big.NewFloat(1)
+	s := csqrt(complex.NewFloat(newFloat(wp).Sub(x.A, one), newFloat(wp).Set(x.B)), wp)
+	t := csqrt(complex.NewFloat(newFloat(wp).Add(x.A, one), newFloat(wp).Set(x.B)), wp)
+	// acosh x = asinh(Re(conj(s) t)) + 2 i atan(Im s / Re t)
+	a := newFloat(wp).Mul(s.A, t.A)
+	a.Add(a, newFloat(wp).Mul(s.B, t.B))
+	b := atan2Float(s.B, t.A, wp)
+	b.SetMantExp(b, 1)
+	return complex.NewFloat(asinhFloat(a, prec), newFloat(prec).Set(b))
+}
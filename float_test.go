@@ -0,0 +1,129 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// runZeros evaluates each of the expressions, which are identities of the form
+// a - b, and tests that the results are zero to the given number of bits
+func runZeros(t *testing.T, expressions []string, bits int) {
+	t.Helper()
+	for _, expression := range expressions {
+		value, err := evaluate(NewEnvironment(), expression)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+		} else if !negligible(value.Matrix, bits) {
+			t.Errorf("%s = %s, want 0", expression, format(value))
+		}
+	}
+}
+
+// toComplex128 evaluates the expression to a complex128
+func toComplex128(t *testing.T, expression string) complex128 {
+	t.Helper()
+	value, err := evaluate(NewEnvironment(), expression)
+	if err != nil {
+		t.Fatalf("%s: %v", expression, err)
+	}
+	a, _ := value.Matrix.Values[0][0].A.Float64()
+	b, _ := value.Matrix.Values[0][0].B.Float64()
+	return complex(a, b)
+}
+
+// near tests if a and b agree to about 13 significant digits
+func near(a, b complex128) bool {
+	return cmplx.Abs(a-b) <= 1e-13*math.Max(cmplx.Abs(b), 1)
+}
+
+func TestInverseFunctions(t *testing.T) {
+	runZeros(t, []string{
+		"asin(1/2) - pi/6",
+		"acos(-1/2) - 2*pi/3",
+		"atan(1) - pi/4",
+		"atan2(-1, -1) + 3*pi/4",
+		"atan2(1, 0) - pi/2",
+		"atan2(0, -1) - pi",
+		"sinh(1) - (e - 1/e)/2",
+		"cosh(1) - (e + 1/e)/2",
+		"tanh(1) - (e^2 - 1)/(e^2 + 1)",
+		"asinh(1) - log(1 + sqrt(2))",
+		"acosh(2) - log(2 + sqrt(3))",
+		"atanh(1/2) - log(3)/2",
+		"sec(1)*cos(1) - 1",
+		"csc(1)*sin(1) - 1",
+		"cot(1)*tan(1) - 1",
+		"sin(asin(3/10 + 2/5i)) - (3/10 + 2/5i)",
+		"cosh(acosh(-3 + 1i)) - (-3 + 1i)",
+		"tan(atan(2 - 1i)) - (2 - 1i)",
+	}, 1000)
+
+	// the reference values are computed with float64
+	functions := []struct {
+		name string
+		f    func(complex128) complex128
+	}{
+		{"sin", cmplx.Sin}, {"cos", cmplx.Cos}, {"tan", cmplx.Tan},
+		{"asin", cmplx.Asin}, {"acos", cmplx.Acos}, {"atan", cmplx.Atan},
+		{"sinh", cmplx.Sinh}, {"cosh", cmplx.Cosh}, {"tanh", cmplx.Tanh},
+		{"asinh", cmplx.Asinh}, {"acosh", cmplx.Acosh}, {"atanh", cmplx.Atanh},
+	}
+	points := []complex128{0.3, -0.7, 3 + 2i, -1.5 - 0.5i, 0.25i, -2 + 4i}
+	for _, function := range functions {
+		for _, point := range points {
+			expression := fmt.Sprintf("%s(%g + %gi)", function.name, real(point), imag(point))
+			if a, b := toComplex128(t, expression), function.f(point); !near(a, b) {
+				t.Errorf("%s = %v, want %v", expression, a, b)
+			}
+		}
+	}
+}
+
+// TestBranchCuts checks that the values on the branch cuts are continuous
+// with the values counterclockwise around the branch points, and that the
+// values on the other side of the branch cuts are different
+func TestBranchCuts(t *testing.T) {
+	tests := []struct {
+		function, point, side string
+	}{
+		{"asin", "2", "-1i"},
+		{"asin", "-2", "1i"},
+		{"acos", "2", "-1i"},
+		{"acos", "-2", "1i"},
+		{"atan", "2i", "1"},
+		{"atan", "-2i", "-1"},
+		{"asinh", "2i", "1"},
+		{"asinh", "-2i", "-1"},
+		{"acosh", "1/2", "1i"},
+		{"acosh", "-2", "1i"},
+		{"atanh", "2", "-1i"},
+		{"atanh", "-2", "1i"},
+	}
+	for _, test := range tests {
+		near := fmt.Sprintf("%s(%s) - %s(%s + (%s)/2^100)", test.function, test.point, test.function, test.point, test.side)
+		far := fmt.Sprintf("%s(%s) - %s(%s - (%s)/2^100)", test.function, test.point, test.function, test.point, test.side)
+		runZeros(t, []string{near}, 90)
+		if a := toComplex128(t, far); cmplx.Abs(a) < 1 {
+			t.Errorf("%s = %v, %s isn't discontinuous at %s", far, a, test.function, test.point)
+		}
+	}
+	run(t, []test{
+		{"asin(2)", "1.570796327 + -1.316957897i"},
+		{"acos(2)", "0 + 1.316957897i"},
+		{"atan(2i)", "1.570796327 + 0.5493061443i"},
+		{"asinh(2i)", "1.316957897 + 1.570796327i"},
+		{"acosh(-2)", "1.316957897 + 3.141592654i"},
+		{"atanh(2)", "0.5493061443 + -1.570796327i"},
+	})
+	runErrors(t, []errorTest{
+		{"atanh(1)", ErrorTypeDomain, 0, 8},
+		{"atan(1i)", ErrorTypeDomain, 0, 0},
+		{"cot(0)", ErrorTypeDomain, 0, 6},
+	})
+}
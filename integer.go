@@ -0,0 +1,622 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"math/bits"
+	"sort"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// maxTrial is the bound of the trial division before Pollard's rho method
+const maxTrial = 1 << 12
+
+// maxRho is the largest number of steps of Pollard's rho method for one
+// sequence, and maxSequences is the number of sequences which are tried
+// before the elliptic curve method
+const (
+	maxRho       = 1 << 16
+	maxSequences = 2
+)
+
+// ecmBounds are the stage one bounds of the elliptic curve method with the
+// numbers of curves which are tried, they find the factors of up to about 15
+// digits and many of up to 20 digits
+var ecmBounds = []struct {
+	bound  uint64
+	curves int
+}{{2000, 25}, {11000, 60}}
+
+// ecmStep is the distance of the giant steps of the second stage of the
+// elliptic curve method, which searches up to ecmRatio times the bound
+const (
+	ecmStep  = 210
+	ecmRatio = 100
+)
+
+// primeTests is the number of Miller-Rabin rounds of the primality test, which
+// also has a Baillie-PSW test
+const primeTests = 20
+
+// gcd computes the non-negative greatest common divisor of a and b, which is
+// zero if both are zero
+func gcd(a, b *big.Int) *big.Int {
+	x, y := new(big.Int).Abs(a), new(big.Int).Abs(b)
+	switch {
+	case x.Sign() == 0:
+		return y
+	case y.Sign() == 0:
+		return x
+	}
+	return x.GCD(nil, nil, x, y)
+}
+
+// lcm computes the non-negative least common multiple of a and b
+func lcm(a, b *big.Int) *big.Int {
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Int)
+	}
+	x := new(big.Int).Quo(a, gcd(a, b))
+	return x.Abs(x.Mul(x, b))
+}
+
+// isPrime tests if n is a prime
+func isPrime(n *big.Int) bool {
+	return n.Sign() > 0 && n.ProbablyPrime(primeTests)
+}
+
+// nextPrime computes the smallest prime larger than n
+func nextPrime(n *big.Int) *big.Int {
+	two := big.NewInt(2)
+	if n.Cmp(two) < 0 {
+		return two
+	}
+	// the candidates are odd
+	p := new(big.Int).Add(n, big.NewInt(1))
+	if p.Bit(0) == 0 {
+		p.Add(p, big.NewInt(1))
+	}
+	for !isPrime(p) {
+		p.Add(p, two)
+	}
+	return p
+}
+
+// inverse computes the inverse of a modulo m in [0, |m|)
+func inverse(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() == 0 {
+		return nil, newArithmeticError(ErrorTypeDivisionByZero, "modulus is zero")
+	}
+	modulus := new(big.Int).Abs(m)
+	if gcd(a, modulus).Cmp(big.NewInt(1)) != 0 {
+		return nil, newArithmeticError(ErrorTypeDomain, "%s has no inverse modulo %s", a, m)
+	} else if modulus.Cmp(big.NewInt(1)) == 0 {
+		return new(big.Int), nil
+	}
+	x := new(big.Int).Mod(a, modulus)
+	return x.ModInverse(x, modulus), nil
+}
+
+// powerMod computes a^b modulo m in [0, |m|), a negative exponent is a power
+// of the inverse of a
+func powerMod(a, b, m *big.Int) (*big.Int, error) {
+	if m.Sign() == 0 {
+		return nil, newArithmeticError(ErrorTypeDivisionByZero, "modulus is zero")
+	}
+	modulus := new(big.Int).Abs(m)
+	x := new(big.Int).Mod(a, modulus)
+	if b.Sign() < 0 {
+		var err error
+		if x, err = inverse(a, m); err != nil {
+			return nil, err
+		}
+	}
+	return x.Exp(x, new(big.Int).Abs(b), modulus), nil
+}
+
+// jacobi computes the Jacobi symbol (a/n) of a positive odd n
+func jacobi(a, n *big.Int) (*big.Int, error) {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		return nil, newArithmeticError(ErrorTypeDomain, "jacobi requires a positive odd modulus")
+	}
+	return big.NewInt(int64(big.Jacobi(a, n))), nil
+}
+
+// rho finds a non-trivial factor of the odd composite n, which is not a
+// perfect power, with Brent's variant of Pollard's rho method
+func rho(n *big.Int) (*big.Int, bool) {
+	one := big.NewInt(1)
+	// the steps of the sequence between the greatest common divisors
+	const m = 128
+	for c := int64(1); c <= maxSequences; c++ {
+		// the sequence y = y^2 + c modulo n
+		next := func(y *big.Int) {
+			y.Mul(y, y)
+			y.Add(y, big.NewInt(c))
+			y.Mod(y, n)
+		}
+		y, x, saved := big.NewInt(2), new(big.Int), new(big.Int)
+		q, g := big.NewInt(1), big.NewInt(1)
+		d := new(big.Int)
+		for r := 1; g.Cmp(one) == 0 && r <= maxRho; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				next(y)
+			}
+			for k := 0; k < r && g.Cmp(one) == 0; k += m {
+				saved.Set(y)
+				for i := 0; i < m && i < r-k; i++ {
+					next(y)
+					q.Mul(q, d.Abs(d.Sub(x, y)))
+					q.Mod(q, n)
+				}
+				g = gcd(q, n)
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// the product of the differences is a multiple of n, the steps
+			// since the last greatest common divisor are repeated one by one
+			for g = one; g.Cmp(one) == 0; {
+				next(saved)
+				g = gcd(d.Sub(x, saved), n)
+			}
+		}
+		if g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// point is a point of a Montgomery curve in the projective coordinates X:Z
+type point struct {
+	x, z *big.Int
+}
+
+// curve is the Montgomery curve B y^2 = x^3 + A x^2 + x modulo n with a24 =
+// (A + 2) / 4
+type curve struct {
+	n, a24 *big.Int
+}
+
+// double computes 2p
+func (c *curve) double(p point) point {
+	s := new(big.Int).Add(p.x, p.z)
+	s.Mod(s.Mul(s, s), c.n)
+	d := new(big.Int).Sub(p.x, p.z)
+	d.Mod(d.Mul(d, d), c.n)
+	t := new(big.Int).Sub(s, d)
+	x := new(big.Int).Mul(s, d)
+	z := new(big.Int).Mul(c.a24, t)
+	z.Add(z, d)
+	z.Mul(z, t)
+	return point{x.Mod(x, c.n), z.Mod(z, c.n)}
+}
+
+// add computes p + q from the difference p - q
+func (c *curve) add(p, q, difference point) point {
+	u := new(big.Int).Sub(p.x, p.z)
+	u.Mul(u, new(big.Int).Add(q.x, q.z))
+	v := new(big.Int).Add(p.x, p.z)
+	v.Mul(v, new(big.Int).Sub(q.x, q.z))
+	x := new(big.Int).Add(u, v)
+	x.Mod(x.Mul(x, x), c.n)
+	z := new(big.Int).Sub(u, v)
+	z.Mod(z.Mul(z, z), c.n)
+	x.Mul(x, difference.z)
+	z.Mul(z, difference.x)
+	return point{x.Mod(x, c.n), z.Mod(z, c.n)}
+}
+
+// multiply computes k p for k > 0 with the Montgomery ladder
+func (c *curve) multiply(k uint64, p point) point {
+	if k == 1 {
+		return p
+	}
+	// the ladder keeps r1 - r0 = p
+	r0, r1 := p, c.double(p)
+	for i := 62 - bits.LeadingZeros64(k); i >= 0; i-- {
+		if k&(1<<uint(i)) != 0 {
+			r0, r1 = c.add(r1, r0, p), c.double(r1)
+		} else {
+			r0, r1 = c.double(r0), c.add(r1, r0, p)
+		}
+	}
+	return r0
+}
+
+// sieve computes the primes up to n
+func sieve(n uint64) []uint64 {
+	composite := make([]bool, n+1)
+	var primes []uint64
+	for p := uint64(2); p <= n; p++ {
+		if composite[p] {
+			continue
+		}
+		primes = append(primes, p)
+		for q := p * p; q <= n; q += p {
+			composite[q] = true
+		}
+	}
+	return primes
+}
+
+// ecmCurve tries to find a non-trivial factor of n with the curve of
+// Suyama's parameter sigma and the stage one bound
+func ecmCurve(n *big.Int, sigma int64, bound uint64, primes []uint64) (*big.Int, bool) {
+	one := big.NewInt(1)
+	factor := func(g *big.Int) (*big.Int, bool) {
+		return g, g.Cmp(one) != 0 && g.Cmp(n) != 0
+	}
+	mod := func(x *big.Int) *big.Int {
+		return x.Mod(x, n)
+	}
+	// u = sigma^2 - 5, v = 4 sigma, the point is u^3:v^3 and a24 = (v -
+	// u)^3 (3u + v) / (16 u^3 v)
+	s := big.NewInt(sigma)
+	u := mod(new(big.Int).Sub(new(big.Int).Mul(s, s), big.NewInt(5)))
+	v := mod(new(big.Int).Mul(s, big.NewInt(4)))
+	x := mod(new(big.Int).Exp(u, big.NewInt(3), nil))
+	z := mod(new(big.Int).Exp(v, big.NewInt(3), nil))
+	a := mod(new(big.Int).Exp(new(big.Int).Sub(v, u), big.NewInt(3), nil))
+	a = mod(a.Mul(a, new(big.Int).Add(new(big.Int).Mul(u, big.NewInt(3)), v)))
+	d := mod(new(big.Int).Mul(new(big.Int).Mul(x, v), big.NewInt(16)))
+	if g := gcd(d, n); g.Cmp(one) != 0 {
+		return factor(g)
+	}
+	c := &curve{n: n, a24: mod(a.Mul(a, new(big.Int).ModInverse(d, n)))}
+	// the first stage multiplies by the prime powers up to the bound
+	p := point{x, z}
+	for _, q := range primes {
+		if q > bound {
+			break
+		}
+		k := q
+		for k*q <= bound {
+			k *= q
+		}
+		p = c.multiply(k, p)
+	}
+	if g := gcd(p.z, n); g.Cmp(one) != 0 {
+		return factor(g)
+	}
+	// the second stage looks for a prime q up to ecmRatio times the bound
+	// with q p = 0 modulo a factor, q = m ecmStep ± j where j is coprime to
+	// ecmStep
+	double := c.double(p)
+	var steps []point
+	for j, previous, next := uint64(1), p, p; j < ecmStep/2; j += 2 {
+		if j > 1 {
+			previous, next = next, c.add(next, double, previous)
+		}
+		if gcd(new(big.Int).SetUint64(j), big.NewInt(ecmStep)).Cmp(one) == 0 {
+			steps = append(steps, next)
+		}
+	}
+	m := bound/ecmStep + 1
+	giant := c.multiply(ecmStep, p)
+	r, previous := c.multiply(m*ecmStep, p), c.multiply((m-1)*ecmStep, p)
+	product, t := big.NewInt(1), new(big.Int)
+	for ; m*ecmStep < ecmRatio*bound; m++ {
+		for _, b := range steps {
+			// the cross product vanishes if r = ±b modulo a factor
+			t.Sub(t.Mul(r.x, b.z), new(big.Int).Mul(b.x, r.z))
+			product.Mod(product.Mul(product, t), n)
+		}
+		r, previous = c.add(r, giant, previous), r
+	}
+	return factor(gcd(product, n))
+}
+
+// ecm finds a non-trivial factor of the composite n, which is not a perfect
+// power, with Lenstra's elliptic curve method
+func ecm(n *big.Int) (*big.Int, bool) {
+	primes := sieve(ecmBounds[len(ecmBounds)-1].bound)
+	sigma := int64(6)
+	for _, b := range ecmBounds {
+		for i := 0; i < b.curves; i++ {
+			if d, ok := ecmCurve(n, sigma, b.bound, primes); ok {
+				return d, true
+			}
+			sigma++
+		}
+	}
+	return nil, false
+}
+
+// factorize computes the prime factors of n > 1 with their multiplicities
+func factorize(n *big.Int) ([]*big.Int, error) {
+	var primes []*big.Int
+	m := new(big.Int).Set(n)
+	// the trial division by the small numbers
+	for p := int64(2); p < maxTrial; p++ {
+		d, r := big.NewInt(p), new(big.Int)
+		for {
+			q, _ := new(big.Int).QuoRem(m, d, r)
+			if r.Sign() != 0 {
+				break
+			}
+			primes = append(primes, d)
+			m = q
+		}
+		if m.Cmp(big.NewInt(p*p)) < 0 {
+			break
+		}
+	}
+	var split func(m *big.Int) error
+	split = func(m *big.Int) error {
+		if m.Cmp(big.NewInt(1)) == 0 {
+			return nil
+		} else if isPrime(m) {
+			primes = append(primes, m)
+			return nil
+		}
+		// the rho method doesn't split the perfect powers
+		for q := int64(m.BitLen()); q > 1; q-- {
+			if r, ok := integerRoot(m, q); ok {
+				for i := int64(0); i < q; i++ {
+					if err := split(r); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		d, ok := rho(m)
+		if !ok {
+			d, ok = ecm(m)
+		}
+		if !ok {
+			return newArithmeticError(ErrorTypeConvergence, "%s could not be factored", m)
+		}
+		if err := split(d); err != nil {
+			return err
+		}
+		return split(new(big.Int).Quo(m, d))
+	}
+	if err := split(m); err != nil {
+		return nil, err
+	}
+	sort.Slice(primes, func(i, j int) bool {
+		return primes[i].Cmp(primes[j]) < 0
+	})
+	return primes, nil
+}
+
+// totient computes Euler's totient function of n > 0
+func totient(n *big.Int) (*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, newArithmeticError(ErrorTypeDomain, "totient of a non-positive integer")
+	} else if n.Cmp(big.NewInt(1)) == 0 {
+		return big.NewInt(1), nil
+	}
+	primes, err := factorize(n)
+	if err != nil {
+		return nil, err
+	}
+	// φ(n) = n the product of (1 - 1/p) over the distinct primes p
+	t := new(big.Int).Set(n)
+	for i, p := range primes {
+		if i > 0 && p.Cmp(primes[i-1]) == 0 {
+			continue
+		}
+		t.Quo(t, p)
+		t.Mul(t, new(big.Int).Sub(p, big.NewInt(1)))
+	}
+	return t, nil
+}
+
+// chineseRemainder computes the smallest non-negative x with x = r_i modulo
+// m_i, the moduli don't have to be coprime
+func chineseRemainder(residues, moduli []*big.Int) (*big.Int, error) {
+	x, modulus := new(big.Int), big.NewInt(1)
+	for i, r := range residues {
+		if moduli[i].Sign() == 0 {
+			return nil, newArithmeticError(ErrorTypeDivisionByZero, "modulus is zero")
+		}
+		m := new(big.Int).Abs(moduli[i])
+		// x + modulus t = r modulo m where g = gcd(modulus, m) divides r - x
+		g := gcd(modulus, m)
+		d := new(big.Int).Sub(r, x)
+		if new(big.Int).Mod(d, g).Sign() != 0 {
+			return nil, newArithmeticError(ErrorTypeDomain, "the congruences have no solution")
+		}
+		n := new(big.Int).Quo(m, g)
+		t, err := inverse(new(big.Int).Quo(modulus, g), n)
+		if err != nil {
+			return nil, err
+		}
+		t.Mul(t, d.Quo(d, g))
+		t.Mod(t, n)
+		x.Add(x, t.Mul(t, modulus))
+		modulus.Mul(modulus, n)
+		x.Mod(x, modulus)
+	}
+	return x, nil
+}
+
+// integerValue computes the number theoretic function of the operation of
+// integer arguments
+func integerValue(operation Operation, arguments ...*big.Int) (*big.Int, error) {
+	boolean := func(b bool) *big.Int {
+		if b {
+			return big.NewInt(1)
+		}
+		return new(big.Int)
+	}
+	switch operation {
+	case OperationGCD:
+		return gcd(arguments[0], arguments[1]), nil
+	case OperationLCM:
+		return lcm(arguments[0], arguments[1]), nil
+	case OperationIsPrime:
+		return boolean(isPrime(arguments[0])), nil
+	case OperationNextPrime:
+		return nextPrime(arguments[0]), nil
+	case OperationTotient:
+		return totient(arguments[0])
+	case OperationPowMod:
+		return powerMod(arguments[0], arguments[1], arguments[2])
+	case OperationInvMod:
+		return inverse(arguments[0], arguments[1])
+	case OperationJacobi:
+		return jacobi(arguments[0], arguments[1])
+	}
+	return nil, newArithmeticError(ErrorTypeValue, "%s is not a function of integers", functionNames[operation])
+}
+
+// integers converts the 1x1 matrices into the integer operands of the
+// operation
+func integers(operation Operation, matrices ...*complex.Matrix) ([]*big.Int, error) {
+	operands := make([]*big.Int, len(matrices))
+	for i, a := range matrices {
+		if !isScalar(a) {
+			return nil, newArithmeticError(ErrorTypeDimension, "%s requires 1x1 matrices", functionNames[operation])
+		}
+		n, ok := integerOf(&a.Values[0][0])
+		if !ok {
+			return nil, newArithmeticError(ErrorTypeNonInteger, "%s requires integer operands", functionNames[operation])
+		}
+		operands[i] = n
+	}
+	return operands, nil
+}
+
+// setInteger stores the integer n in the 1x1 matrix a
+func setInteger(a *complex.Matrix, n *big.Int) {
+	a.Values = [][]complex.Rational{{*realRational(new(big.Rat).SetInt(n))}}
+}
+
+// integerElementwise returns the function which computes the number theoretic
+// function of the operation of a elementwise and stores the result in a
+func integerElementwise(operation Operation) func(a *complex.Matrix) error {
+	return func(a *complex.Matrix) error {
+		return special(a, func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+			n, ok := integerOf(x)
+			if !ok {
+				return nil, newArithmeticError(ErrorTypeNonInteger, "%s requires integer operands", functionNames[operation])
+			}
+			r, err := integerValue(operation, n)
+			if err != nil {
+				return nil, err
+			}
+			return realRational(new(big.Rat).SetInt(r)), nil
+		})
+	}
+}
+
+// integerFunction returns the function which computes the number theoretic
+// function of the operation of the 1x1 matrices and stores the result in the
+// first
+func integerFunction(operation Operation) func(a ...*complex.Matrix) error {
+	return func(a ...*complex.Matrix) error {
+		operands, err := integers(operation, a...)
+		if err != nil {
+			return err
+		}
+		r, err := integerValue(operation, operands...)
+		if err != nil {
+			return err
+		}
+		setInteger(a[0], r)
+		return nil
+	}
+}
+
+// factorFunction computes the prime factorization of the integer a and stores
+// the primes and their multiplicities in the rows of a, -1 is a factor of the
+// negative integers and the factorization of 1 has no rows
+func factorFunction(a *complex.Matrix) error {
+	operands, err := integers(OperationFactor, a)
+	if err != nil {
+		return err
+	}
+	n := operands[0]
+	if n.Sign() == 0 {
+		return newArithmeticError(ErrorTypeDomain, "factor of zero")
+	}
+	var rows [][]complex.Rational
+	row := func(p *big.Int, e int64) {
+		rows = append(rows, []complex.Rational{
+			*realRational(new(big.Rat).SetInt(p)),
+			*realRational(big.NewRat(e, 1)),
+		})
+	}
+	if n.Sign() < 0 {
+		row(big.NewInt(-1), 1)
+	}
+	m := new(big.Int).Abs(n)
+	if m.Cmp(big.NewInt(1)) == 0 {
+		a.Values = rows
+		return nil
+	}
+	primes, err := factorize(m)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(primes); {
+		j := i
+		for j < len(primes) && primes[j].Cmp(primes[i]) == 0 {
+			j++
+		}
+		row(primes[i], int64(j-i))
+		i = j
+	}
+	a.Values = rows
+	return nil
+}
+
+// crtFunction computes the smallest non-negative solution of the congruences
+// with the residues a and the moduli b, which are vectors with the same number
+// of elements, and stores it in a
+func crtFunction(a, b *complex.Matrix) error {
+	ar, ac := dimensions(a)
+	br, bc := dimensions(b)
+	if (ar != 1 && ac != 1) || (br != 1 && bc != 1) || ar*ac != br*bc {
+		return newArithmeticError(ErrorTypeDimension, "crt requires vectors with the same number of elements")
+	}
+	vector := func(m *complex.Matrix) ([]*big.Int, error) {
+		var elements []*big.Int
+		for _, row := range m.Values {
+			for j := range row {
+				n, ok := integerOf(&row[j])
+				if !ok {
+					return nil, newArithmeticError(ErrorTypeNonInteger, "crt requires integer operands")
+				}
+				elements = append(elements, n)
+			}
+		}
+		return elements, nil
+	}
+	residues, err := vector(a)
+	if err != nil {
+		return err
+	}
+	moduli, err := vector(b)
+	if err != nil {
+		return err
+	}
+	x, err := chineseRemainder(residues, moduli)
+	if err != nil {
+		return err
+	}
+	setInteger(a, x)
+	return nil
+}
+
+// integerMatrices are the number theoretic functions of the operations with
+// more than one argument, which store the result in the first
+var integerMatrices = map[Operation]func(a ...*complex.Matrix) error{
+	OperationGCD:    integerFunction(OperationGCD),
+	OperationLCM:    integerFunction(OperationLCM),
+	OperationPowMod: integerFunction(OperationPowMod),
+	OperationInvMod: integerFunction(OperationInvMod),
+	OperationJacobi: integerFunction(OperationJacobi),
+	OperationCRT: func(a ...*complex.Matrix) error {
+		return crtFunction(a[0], a[1])
+	},
+}
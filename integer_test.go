@@ -0,0 +1,83 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+// exactly is the exact string form of a real matrix
+func exactly(value Value) string {
+	var rows []string
+	for _, row := range value.Matrix.Values {
+		var elements []string
+		for _, element := range row {
+			elements = append(elements, element.A.RatString())
+		}
+		rows = append(rows, strings.Join(elements, " "))
+	}
+	return "[" + strings.Join(rows, ";") + "]"
+}
+
+func TestIntegerFunctions(t *testing.T) {
+	tests := []test{
+		{"gcd(12, 18)", "[6]"},
+		{"gcd(-12, 18)", "[6]"},
+		{"gcd(0, 0)", "[0]"},
+		{"gcd(2^100, 6^50)", "[1125899906842624]"},
+		{"lcm(4, 6)", "[12]"},
+		{"lcm(0, 5)", "[0]"},
+		{"isprime(2^61 - 1)", "[1]"},
+		{"isprime(2^64 + 1)", "[0]"},
+		{"isprime(1)", "[0]"},
+		{"isprime(561)", "[0]"},
+		{"nextprime(100)", "[101]"},
+		{"nextprime(2^64)", "[18446744073709551629]"},
+		{"totient(1)", "[1]"},
+		{"totient(36)", "[12]"},
+		{"totient(2^61 - 1)", "[2305843009213693950]"},
+		{"powmod(2, 100, 1000000007)", "[976371285]"},
+		{"powmod(3, -1, 7)", "[5]"},
+		{"powmod(-2, 3, 5)", "[2]"},
+		{"invmod(3, 7)", "[5]"},
+		{"crt([2 3 2], [3 5 7])", "[23]"},
+		{"crt([1 3], [4 6])", "[9]"},
+		{"jacobi(2, 15)", "[1]"},
+		{"jacobi(1001, 9907)", "[-1]"},
+		{"jacobi(3, 9)", "[0]"},
+		{"factor(360)", "[2 3;3 2;5 1]"},
+		{"factor(-12)", "[-1 1;2 2;3 1]"},
+		{"factor(-1)", "[-1 1]"},
+		// 1 is the empty product of primes
+		{"factor(1)", "[]"},
+		{"factor(2^128 + 1)", "[59649589127497217 1;5704689200685129054721 1]"},
+	}
+	for _, test := range tests {
+		value, err := evaluate(NewEnvironment(), test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+		} else if result := exactly(value); result != test.result {
+			t.Errorf("%s = %s, want %s", test.expression, result, test.result)
+		}
+	}
+
+	runErrors(t, []errorTest{
+		{"crt([1 2], [4 6])", ErrorTypeDomain, 0, 17},
+		{"crt([1 2 3], [4 5])", ErrorTypeDimension, 0, 19},
+		{"jacobi(2, 14)", ErrorTypeDomain, 0, 13},
+		{"jacobi(5, -3)", ErrorTypeDomain, 0, 13},
+		{"invmod(2, 4)", ErrorTypeDomain, 0, 12},
+		{"powmod(2, 3, 0)", ErrorTypeDivisionByZero, 0, 15},
+		{"factor(0)", ErrorTypeDomain, 0, 9},
+		{"totient(0)", ErrorTypeDomain, 0, 10},
+		{"gcd(1.5, 3)", ErrorTypeNonInteger, 0, 11},
+		{"isprime(2.5)", ErrorTypeNonInteger, 0, 12},
+		{"factor(1/2)", ErrorTypeNonInteger, 0, 11},
+		{"jacobi(1i, 3)", ErrorTypeNonInteger, 0, 13},
+		{"crt([1 2.5], [4 5])", ErrorTypeNonInteger, 0, 19},
+		{"gcd(factor(1), 2)", ErrorTypeDimension, 0, 17},
+	})
+}
@@ -0,0 +1,742 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// maxIntegralDepth limits the number of nested substitutions and
+// integrations by parts
+const maxIntegralDepth = 8
+
+// maxExponent is the largest exponent which is computed exactly
+const maxExponent = 4096
+
+// rational computes the value of a real constant expression exactly
+func rational(n *Node) (*big.Rat, bool) {
+	r, ok := number(n)
+	if !ok || r.B.Sign() != 0 {
+		return nil, false
+	}
+	return r.A, true
+}
+
+// power10 computes 10^e exactly
+func power10(e int64) *big.Rat {
+	a := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(e)), nil)
+	if e < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), a)
+	}
+	return new(big.Rat).SetInt(a)
+}
+
+// abs computes the absolute value of an integer
+func abs(a int64) int64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// exponentiate computes a^b exactly if b is a small integer
+func exponentiate(a, b *big.Rat) (*big.Rat, bool) {
+	if !b.IsInt() || !b.Num().IsInt64() || abs(b.Num().Int64()) > maxExponent {
+		return nil, false
+	}
+	e := b.Num().Int64()
+	if e < 0 {
+		if a.Sign() == 0 {
+			return nil, false
+		}
+		a = new(big.Rat).Inv(a)
+	}
+	num := new(big.Int).Exp(a.Num(), big.NewInt(abs(e)), nil)
+	den := new(big.Int).Exp(a.Denom(), big.NewInt(abs(e)), nil)
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// newRational converts a real rational into an expression
+func newRational(r *big.Rat) *Node {
+	return newNumber(complex.NewRational(new(big.Rat).Set(r), big.NewRat(0, 1)))
+}
+
+// depends tests if the expression depends on the variable name
+func (n *Node) depends(name string) bool {
+	for _, variable := range n.Variables() {
+		if variable == name {
+			return true
+		}
+	}
+	return false
+}
+
+// factor is a base raised to a rational exponent
+type factor struct {
+	base     *Node
+	exponent *big.Rat
+}
+
+// product is a rational coefficient times a product of factors
+type product struct {
+	coefficient *big.Rat
+	factors     []factor
+}
+
+// newProduct factors an expression
+func newProduct(n *Node) *product {
+	p := &product{
+		coefficient: big.NewRat(1, 1),
+	}
+	p.multiply(n, big.NewRat(1, 1))
+	return p
+}
+
+// multiply multiplies the product by n^e
+func (p *product) multiply(n *Node, e *big.Rat) {
+	if r, ok := rational(n); ok {
+		if r, ok := exponentiate(r, e); ok {
+			p.coefficient.Mul(p.coefficient, r)
+			return
+		}
+	}
+	switch n.Operation {
+	case OperationMultiply:
+		p.multiply(n.Left, e)
+		p.multiply(n.Right, e)
+		return
+	case OperationDivide:
+		p.multiply(n.Left, e)
+		p.multiply(n.Right, new(big.Rat).Neg(e))
+		return
+	case OperationNegate:
+		if e.IsInt() {
+			if e.Num().Bit(0) == 1 {
+				p.coefficient.Neg(p.coefficient)
+			}
+			p.multiply(n.Left, e)
+			return
+		}
+	case OperationExponentiation, OperationSquareRoot:
+		r, ok := big.NewRat(1, 2), true
+		if n.Operation == OperationExponentiation {
+			r, ok = rational(n.Right)
+		}
+		if ok {
+			switch n.Left.Operation {
+			case OperationMultiply, OperationDivide, OperationNegate:
+				if !r.IsInt() {
+					break
+				}
+				fallthrough
+			default:
+				p.multiply(n.Left, new(big.Rat).Mul(e, r))
+				return
+			}
+		}
+	}
+	p.insert(n, e)
+}
+
+// insert multiplies the product by the factor base^e, the matrices aren't
+// merged because their products are not elementwise
+func (p *product) insert(base *Node, e *big.Rat) {
+	s := base.String()
+	for i := range p.factors {
+		if matrix(base) {
+			break
+		}
+		if p.factors[i].base.String() == s {
+			p.factors[i].exponent.Add(p.factors[i].exponent, e)
+			return
+		}
+	}
+	p.factors = append(p.factors, factor{
+		base:     base,
+		exponent: new(big.Rat).Set(e),
+	})
+}
+
+// divide computes the quotient of two products
+func (p *product) divide(q *product) *product {
+	a := &product{
+		coefficient: new(big.Rat).Set(p.coefficient),
+	}
+	if q.coefficient.Sign() == 0 {
+		return nil
+	}
+	a.coefficient.Quo(a.coefficient, q.coefficient)
+	for _, f := range p.factors {
+		a.insert(f.base, f.exponent)
+	}
+	for _, f := range q.factors {
+		a.insert(f.base, new(big.Rat).Neg(f.exponent))
+	}
+	return a
+}
+
+// split separates the factors which depend on the variable name from the
+// factors which are constant
+func (p *product) split(name string) (constant *product, dependent []factor) {
+	constant = &product{
+		coefficient: p.coefficient,
+	}
+	for _, f := range p.factors {
+		if f.exponent.Sign() == 0 {
+			continue
+		} else if f.base.depends(name) {
+			dependent = append(dependent, f)
+			continue
+		}
+		constant.factors = append(constant.factors, f)
+	}
+	return constant, dependent
+}
+
+// Node converts the product into an expression, the coefficient comes first
+// and the factors with a negative exponent are in the denominator
+func (p *product) Node() *Node {
+	if p.coefficient.Sign() == 0 {
+		return newRational(p.coefficient)
+	}
+	var numerator, denominator *Node
+	multiply := func(a, b *Node) *Node {
+		if a == nil {
+			return b
+		} else if b == nil {
+			return a
+		}
+		return &Node{
+			Operation: OperationMultiply,
+			Left:      a,
+			Right:     b,
+		}
+	}
+	coefficient := new(big.Rat).Abs(p.coefficient)
+	imaginary, positive := false, false
+	for _, f := range p.factors {
+		if isUnit(f.base) && f.exponent.Cmp(big.NewRat(1, 1)) == 0 {
+			imaginary = true
+		} else if f.exponent.Sign() > 0 {
+			positive = true
+		}
+	}
+	if imaginary {
+		numerator = &Node{
+			Operation: OperationImaginary,
+			Value:     coefficient.Num().String(),
+		}
+	} else if !coefficient.Num().IsInt64() || coefficient.Num().Int64() != 1 || !positive {
+		numerator = newRational(new(big.Rat).SetInt(coefficient.Num()))
+	}
+	if !coefficient.IsInt() {
+		denominator = newRational(new(big.Rat).SetInt(coefficient.Denom()))
+	}
+	for _, f := range p.factors {
+		e := new(big.Rat).Abs(f.exponent)
+		if e.Sign() == 0 || (imaginary && isUnit(f.base) && f.exponent.Cmp(big.NewRat(1, 1)) == 0) {
+			continue
+		}
+		a := f.base
+		if e.Cmp(big.NewRat(1, 2)) == 0 {
+			a = &Node{
+				Operation: OperationSquareRoot,
+				Left:      a,
+			}
+		} else if e.Cmp(big.NewRat(1, 1)) != 0 {
+			a = &Node{
+				Operation: OperationExponentiation,
+				Left:      a,
+				Right:     newRational(e),
+			}
+		}
+		if f.exponent.Sign() > 0 {
+			numerator = multiply(numerator, a)
+		} else {
+			denominator = multiply(denominator, a)
+		}
+	}
+	a := numerator
+	if denominator != nil {
+		a = &Node{
+			Operation: OperationDivide,
+			Left:      numerator,
+			Right:     denominator,
+		}
+	}
+	if p.coefficient.Sign() < 0 {
+		a = &Node{
+			Operation: OperationNegate,
+			Left:      a,
+		}
+	}
+	return a
+}
+
+// Integrate computes the indefinite integral of the equation with respect to
+// the variable name. Integrals without a closed form that can be found are
+// left unevaluated.
+func (n *Node) Integrate(name string) *Node {
+	return integrate(n.Simplify(), name, 0).Simplify()
+}
+
+// integrate computes the indefinite integral of a simplified expression
+func integrate(n *Node, name string, depth int) *Node {
+	variable := &Node{
+		Operation: OperationVariable,
+		Value:     name,
+	}
+	unevaluated := &Node{
+		Operation: OperationIntegral,
+		Left:      n,
+		Right:     variable,
+	}
+	if depth > maxIntegralDepth {
+		return unevaluated
+	}
+	if !n.depends(name) {
+		return &Node{
+			Operation: OperationMultiply,
+			Left:      n,
+			Right:     variable,
+		}
+	}
+	switch n.Operation {
+	case OperationAdd, OperationSubtract:
+		a := &Node{
+			Operation: n.Operation,
+			Left:      integrate(n.Left, name, depth),
+			Right:     integrate(n.Right, name, depth),
+		}
+		return a
+	case OperationNegate:
+		a := &Node{
+			Operation: OperationNegate,
+			Left:      integrate(n.Left, name, depth),
+		}
+		return a
+	case OperationMatrix:
+		a := &Node{
+			Operation: OperationMatrix,
+			Rows:      make([][]*Node, len(n.Rows)),
+		}
+		for i, row := range n.Rows {
+			for _, element := range row {
+				a.Rows[i] = append(a.Rows[i], integrate(element, name, depth))
+			}
+		}
+		return a
+	}
+
+	constant, dependent := newProduct(n).split(name)
+	var a *Node
+	if len(dependent) == 1 {
+		a = elementary(dependent[0], name, depth)
+	}
+	if a == nil {
+		a = substitute(dependent, name, depth)
+	}
+	if a == nil && len(dependent) == 2 {
+		a = parts(dependent, name, depth)
+	}
+	if a == nil {
+		return unevaluated
+	}
+	return &Node{
+		Operation: OperationMultiply,
+		Left:      constant.Node(),
+		Right:     a,
+	}
+}
+
+// linear returns the derivative of the expression with respect to the variable
+// name if it is a non-zero constant
+func linear(n *Node, name string) (*Node, bool) {
+	a := n.DerivativeWith(name)
+	if a == nil {
+		return nil, false
+	}
+	a = a.Simplify()
+	if a.depends(name) {
+		return nil, false
+	} else if r, ok := rational(a); ok && r.Sign() == 0 {
+		return nil, false
+	}
+	return a, true
+}
+
+// elementary integrates a factor which is an elementary function of an
+// argument that is linear in the variable name
+func elementary(f factor, name string, depth int) *Node {
+	one := big.NewRat(1, 1)
+	divide := func(a, b *Node) *Node {
+		return &Node{
+			Operation: OperationDivide,
+			Left:      a,
+			Right:     b,
+		}
+	}
+	if a, ok := linear(f.base, name); ok {
+		if f.exponent.Cmp(big.NewRat(-1, 1)) == 0 {
+			log := &Node{
+				Operation: OperationNaturalLogarithm,
+				Left:      f.base,
+			}
+			return divide(log, a)
+		}
+		e := new(big.Rat).Add(f.exponent, one)
+		power := &product{
+			coefficient: new(big.Rat).Inv(e),
+			factors: []factor{{
+				base:     f.base,
+				exponent: e,
+			}},
+		}
+		return divide(power.Node(), a)
+	}
+	if f.exponent.Cmp(one) != 0 {
+		return nil
+	}
+	n := f.base
+	switch n.Operation {
+	case OperationAdd, OperationSubtract, OperationNegate:
+		if a := integrate(n, name, depth+1); a.Operation != OperationIntegral {
+			return a
+		}
+		return nil
+	case OperationExponentiation:
+		if n.Left.depends(name) {
+			return nil
+		}
+		a, ok := linear(n.Right, name)
+		if !ok {
+			return nil
+		}
+		log := &Node{
+			Operation: OperationNaturalLogarithm,
+			Left:      n.Left,
+		}
+		return divide(n, &Node{
+			Operation: OperationMultiply,
+			Left:      log,
+			Right:     a,
+		})
+	case OperationLogarithm:
+		if n.Right.depends(name) {
+			return nil
+		}
+		// log(f, b) = log(f) / log(b)
+		a := elementary(factor{
+			base: &Node{
+				Operation: OperationNaturalLogarithm,
+				Left:      n.Left,
+			},
+			exponent: one,
+		}, name, depth)
+		if a == nil {
+			return nil
+		}
+		return divide(a, &Node{
+			Operation: OperationNaturalLogarithm,
+			Left:      n.Right,
+		})
+	}
+	a, ok := linear(n.Left, name)
+	if !ok {
+		return nil
+	}
+	u := n.Left
+	switch n.Operation {
+	case OperationNaturalExponentiation:
+		return divide(n, a)
+	case OperationNaturalLogarithm:
+		b := &Node{
+			Operation: OperationSubtract,
+			Left: &Node{
+				Operation: OperationMultiply,
+				Left:      u,
+				Right:     n,
+			},
+			Right: u,
+		}
+		return divide(b, a)
+	case OperationSine:
+		b := &Node{
+			Operation: OperationNegate,
+			Left: &Node{
+				Operation: OperationCosine,
+				Left:      u,
+			},
+		}
+		return divide(b, a)
+	case OperationCosine:
+		b := &Node{
+			Operation: OperationSine,
+			Left:      u,
+		}
+		return divide(b, a)
+	case OperationTangent:
+		b := &Node{
+			Operation: OperationNegate,
+			Left: &Node{
+				Operation: OperationNaturalLogarithm,
+				Left: &Node{
+					Operation: OperationCosine,
+					Left:      u,
+				},
+			},
+		}
+		return divide(b, a)
+	}
+	unary := func(operation Operation, left *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+		}
+	}
+	binary := func(operation Operation, left, right *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+			Right:     right,
+		}
+	}
+	square := binary(OperationExponentiation, u, ratio(2, 1))
+	// the inverse functions are integrated by parts, u f(u) - the integral of
+	// u f'(u)
+	parts := func(b *Node) *Node {
+		return divide(binary(OperationSubtract, binary(OperationMultiply, u, n), b), a)
+	}
+	switch n.Operation {
+	case OperationHyperbolicSine:
+		return divide(unary(OperationHyperbolicCosine, u), a)
+	case OperationHyperbolicCosine:
+		return divide(unary(OperationHyperbolicSine, u), a)
+	case OperationHyperbolicTangent:
+		return divide(unary(OperationNaturalLogarithm, unary(OperationHyperbolicCosine, u)), a)
+	case OperationSecant:
+		return divide(unary(OperationNaturalLogarithm,
+			binary(OperationAdd, n, unary(OperationTangent, u))), a)
+	case OperationCosecant:
+		return divide(unary(OperationNegate, unary(OperationNaturalLogarithm,
+			binary(OperationAdd, n, unary(OperationCotangent, u)))), a)
+	case OperationCotangent:
+		return divide(unary(OperationNaturalLogarithm, unary(OperationSine, u)), a)
+	case OperationArcsine:
+		return parts(unary(OperationNegate,
+			unary(OperationSquareRoot, binary(OperationSubtract, ratio(1, 1), square))))
+	case OperationArccosine:
+		return parts(unary(OperationSquareRoot, binary(OperationSubtract, ratio(1, 1), square)))
+	case OperationArctangent:
+		return parts(divide(unary(OperationNaturalLogarithm,
+			binary(OperationAdd, square, ratio(1, 1))), ratio(2, 1)))
+	case OperationHyperbolicArcsine:
+		return parts(unary(OperationSquareRoot, binary(OperationAdd, square, ratio(1, 1))))
+	case OperationHyperbolicArccosine:
+		return parts(binary(OperationMultiply,
+			unary(OperationSquareRoot, binary(OperationSubtract, u, ratio(1, 1))),
+			unary(OperationSquareRoot, binary(OperationAdd, u, ratio(1, 1)))))
+	case OperationHyperbolicArctangent:
+		return parts(unary(OperationNegate, divide(unary(OperationNaturalLogarithm,
+			binary(OperationSubtract, ratio(1, 1), square)), ratio(2, 1))))
+	case OperationErf, OperationErfc:
+		// the integral of u erf'(u) is -e^(-u^2) / sqrt(pi)
+		b := divide(unary(OperationNaturalExponentiation, unary(OperationNegate, square)),
+			unary(OperationSquareRoot, &Node{
+				Operation: OperationPI,
+			}))
+		if n.Operation == OperationErfc {
+			return parts(b)
+		}
+		return parts(unary(OperationNegate, b))
+	case OperationDigamma:
+		return divide(unary(OperationLogGamma, u), a)
+	case OperationPolygamma, OperationZetaDerivative:
+		// the integral lowers the order
+		if n.Right.depends(name) {
+			return nil
+		}
+		return divide(binary(n.Operation, u, binary(OperationSubtract, n.Right, ratio(1, 1))), a)
+	case OperationAiryAiPrime:
+		return divide(unary(OperationAiryAi, u), a)
+	case OperationAiryBiPrime:
+		return divide(unary(OperationAiryBi, u), a)
+	}
+	return nil
+}
+
+// substitute integrates a product of factors of the form F(u) * u' by
+// integrating F with respect to u
+func substitute(dependent []factor, name string, depth int) *Node {
+	var candidates []*Node
+	for _, f := range dependent {
+		candidates = append(candidates, f.base)
+		switch f.base.Operation {
+		case OperationNaturalExponentiation, OperationNaturalLogarithm,
+			OperationSine, OperationCosine, OperationTangent,
+			OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationFactorial, OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma, OperationAiryAiPrime, OperationAiryBiPrime:
+			candidates = append(candidates, f.base.Left)
+		case OperationPolygamma, OperationZetaDerivative:
+			if !f.base.Right.depends(name) {
+				candidates = append(candidates, f.base.Left)
+			}
+		case OperationExponentiation:
+			if !f.base.Left.depends(name) {
+				candidates = append(candidates, f.base.Right)
+			}
+		}
+	}
+	variables := make(map[string]bool)
+	for _, f := range dependent {
+		for _, variable := range f.base.Variables() {
+			variables[variable] = true
+		}
+	}
+	u := "u"
+	for variables[u] || u == name {
+		u += "u"
+	}
+	y := &Node{
+		Operation: OperationVariable,
+		Value:     u,
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := linear(candidate, name); ok && len(dependent) == 1 {
+			continue
+		}
+		s := candidate.String()
+		// F(y) where y replaces the candidate
+		var replace func(n *Node) *Node
+		replace = func(n *Node) *Node {
+			if n == nil {
+				return nil
+			} else if n.String() == s {
+				return y
+			}
+			a := *n
+			a.Left, a.Right = replace(n.Left), replace(n.Right)
+			return &a
+		}
+		outer := &product{
+			coefficient: big.NewRat(1, 1),
+		}
+		for _, f := range dependent {
+			outer.insert(replace(f.base), f.exponent)
+		}
+		constant, rest := outer.split(name)
+		if len(rest) == 0 || len(constant.factors) == 0 {
+			continue
+		}
+		inner := &product{
+			coefficient: big.NewRat(1, 1),
+			factors:     rest,
+		}
+		d := candidate.DerivativeWith(name)
+		if d == nil {
+			continue
+		}
+		derivative := newProduct(d.Simplify())
+		ratio := inner.divide(derivative)
+		if ratio == nil {
+			continue
+		}
+		if _, dependent := ratio.split(name); len(dependent) > 0 {
+			continue
+		}
+		if constant.Node().depends(name) {
+			continue
+		}
+		a := integrate(constant.Node().Simplify(), u, depth+1)
+		if contains(a, OperationIntegral) {
+			continue
+		}
+		return &Node{
+			Operation: OperationMultiply,
+			Left:      ratio.Node(),
+			Right:     a.Substitute(map[string]*Node{u: candidate}),
+		}
+	}
+	return nil
+}
+
+// parts integrates a product of two factors by parts, a polynomial factor is
+// differentiated unless the other factor is a logarithm
+func parts(dependent []factor, name string, depth int) *Node {
+	f, g := dependent[0], dependent[1]
+	if g.base.Operation == OperationNaturalLogarithm {
+		f, g = g, f
+	}
+	if f.base.Operation != OperationNaturalLogarithm {
+		if _, ok := linear(f.base, name); !ok || !f.exponent.IsInt() || f.exponent.Sign() < 0 {
+			f, g = g, f
+		}
+		if _, ok := linear(f.base, name); !ok || !f.exponent.IsInt() || f.exponent.Sign() < 0 {
+			return nil
+		}
+	}
+	node := func(f factor) *Node {
+		p := &product{
+			coefficient: big.NewRat(1, 1),
+			factors:     []factor{f},
+		}
+		return p.Node()
+	}
+	u, v := node(f), integrate(node(g), name, depth+1)
+	if contains(v, OperationIntegral) {
+		return nil
+	}
+	v = v.Simplify()
+	du := u.DerivativeWith(name)
+	if du == nil {
+		return nil
+	}
+	b := integrate((&Node{
+		Operation: OperationMultiply,
+		Left:      du,
+		Right:     v,
+	}).Simplify(), name, depth+1)
+	if contains(b, OperationIntegral) {
+		return nil
+	}
+	return &Node{
+		Operation: OperationSubtract,
+		Left: &Node{
+			Operation: OperationMultiply,
+			Left:      u,
+			Right:     v,
+		},
+		Right: b,
+	}
+}
+
+// contains tests if the expression contains the operation
+func contains(n *Node, operation Operation) bool {
+	if n == nil {
+		return false
+	} else if n.Operation == operation {
+		return true
+	}
+	for _, argument := range n.Arguments {
+		if contains(argument, operation) {
+			return true
+		}
+	}
+	for _, row := range n.Rows {
+		for _, element := range row {
+			if contains(element, operation) {
+				return true
+			}
+		}
+	}
+	return contains(n.Left, operation) || contains(n.Right, operation)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// negligible tests if each of the values of the matrix is less than 2^-bits in
+// magnitude
+func negligible(m *complex.Matrix, bits int) bool {
+	eps := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	for _, row := range m.Values {
+		for _, value := range row {
+			if new(big.Rat).Abs(value.A).Cmp(eps) > 0 || new(big.Rat).Abs(value.B).Cmp(eps) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestIntegrate(t *testing.T) {
+	tests := []test{
+		{"x^2", "x^3 / 3"},
+		{"3*x^2 + 2*x + 1", "x^3 + x^2 + x"},
+		{"exp(2*x)", "e^(2 * x) / 2"},
+		{"1/x", "log(x)"},
+		{"sin(x)", "-cos(x)"},
+		{"cos(3*x)", "sin(3 * x) / 3"},
+		{"tan(x)", "-log(cos(x))"},
+		{"log(x)", "x * log(x) - x"},
+		{"x*exp(x)", "x * e^x - e^x"},
+		{"x*cos(x)", "x * sin(x) + cos(x)"},
+		{"2*x*exp(x^2)", "e^(x^2)"},
+		{"sin(x)*cos(x)", "-(cos(2 * x) / 4)"},
+		{"exp(x^2)", "integrate(e^(x^2), x)"},
+	}
+	for _, test := range tests {
+		value, err := evaluate(NewEnvironment(), fmt.Sprintf("integrate(%s, x)", test.expression))
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if result := format(value); result != test.result {
+			t.Errorf("integrate(%s, x) = %s, want %s", test.expression, result, test.result)
+		}
+		if contains(value.Expression, OperationIntegral) {
+			continue
+		}
+		// the derivative of the integral is the integrand
+		check := fmt.Sprintf("eval(derivative(integrate(%s, x), x) - (%s), x=7/10)", test.expression, test.expression)
+		value, err = evaluate(NewEnvironment(), check)
+		if err != nil {
+			t.Errorf("%s: %v", check, err)
+		} else if value.Matrix == nil || !negligible(value.Matrix, 900) {
+			t.Errorf("%s = %s, want 0", check, format(value))
+		}
+	}
+	run(t, []test{
+		{"integrate(x*y, y)", "x * y^2 / 2"},
+	})
+	runErrors(t, []errorTest{
+		{"integrate(y, pi)", ErrorTypeValue, 13, 15},
+	})
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// latexFunctions are the LaTeX names of the built in functions
+var latexFunctions = map[Operation]string{
+	OperationNaturalLogarithm:     `\ln`,
+	OperationCosine:               `\cos`,
+	OperationSine:                 `\sin`,
+	OperationTangent:              `\tan`,
+	OperationArcsine:              `\arcsin`,
+	OperationArccosine:            `\arccos`,
+	OperationArctangent:           `\arctan`,
+	OperationHyperbolicSine:       `\sinh`,
+	OperationHyperbolicCosine:     `\cosh`,
+	OperationHyperbolicTangent:    `\tanh`,
+	OperationHyperbolicArcsine:    `\operatorname{arsinh}`,
+	OperationHyperbolicArccosine:  `\operatorname{arcosh}`,
+	OperationHyperbolicArctangent: `\operatorname{artanh}`,
+	OperationSecant:               `\sec`,
+	OperationCosecant:             `\csc`,
+	OperationCotangent:            `\cot`,
+	OperationGamma:                `\Gamma`,
+	OperationLogGamma:             `\ln\Gamma`,
+	OperationBeta:                 `\mathrm{B}`,
+	OperationErf:                  `\operatorname{erf}`,
+	OperationErfc:                 `\operatorname{erfc}`,
+	OperationZeta:                 `\zeta`,
+	OperationZetaDerivative:       `\zeta`,
+	OperationDigamma:              `\psi`,
+	OperationPolygamma:            `\psi`,
+	OperationLambertW:             `W`,
+	OperationBesselJ:              `J`,
+	OperationBesselY:              `Y`,
+	OperationBesselI:              `I`,
+	OperationBesselK:              `K`,
+	OperationAiryAi:               `\operatorname{Ai}`,
+	OperationAiryBi:               `\operatorname{Bi}`,
+	OperationAiryAiPrime:          `\operatorname{Ai}'`,
+	OperationAiryBiPrime:          `\operatorname{Bi}'`,
+	OperationEllipticK:            `K`,
+	OperationEllipticE:            `E`,
+	OperationGCD:                  `\gcd`,
+	OperationLCM:                  `\operatorname{lcm}`,
+	OperationIsPrime:              `\operatorname{isprime}`,
+	OperationNextPrime:            `\operatorname{nextprime}`,
+	OperationFactor:               `\operatorname{factor}`,
+	OperationTotient:              `\varphi`,
+	OperationPowMod:               `\operatorname{powmod}`,
+	OperationInvMod:               `\operatorname{invmod}`,
+	OperationCRT:                  `\operatorname{crt}`,
+}
+
+// latexName typesets a name, names longer than a letter are upright
+func latexName(name string) string {
+	if len(name) == 1 {
+		return name
+	}
+	return `\mathrm{` + name + `}`
+}
+
+// latexDecimal typesets a decimal number which may have an exponent
+func latexDecimal(s string) string {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exponent, err := strconv.Atoi(s[i+1:]); err == nil {
+			return s[:i] + ` \times 10^{` + strconv.Itoa(exponent) + `}`
+		}
+	}
+	return s
+}
+
+// LaTeX returns the LaTeX form of the equation
+func (n *Node) LaTeX() string {
+	var process func(n *Node) string
+	parentheses := func(s string) string {
+		return `\left(` + s + `\right)`
+	}
+	// operand parenthesizes the operand if it binds looser than the minimum
+	operand := func(n *Node, minimum int) string {
+		s := process(n)
+		if n.precedence() < minimum {
+			return parentheses(s)
+		}
+		return s
+	}
+	// atom parenthesizes the operand unless it is a value
+	atom := func(n *Node) string {
+		s := process(n)
+		if n.precedence() < precedenceValue || n.Operation == OperationDivide ||
+			n.Operation == OperationImaginary || n.Operation == OperationNotation {
+			return parentheses(s)
+		}
+		return s
+	}
+	process = func(n *Node) string {
+		if n == nil {
+			return ""
+		}
+		switch n.Operation {
+		case OperationAdd:
+			if n.Right.Operation == OperationNegate {
+				// a + -b is typeset as a - b
+				return operand(n.Left, precedenceSum) + " - " + operand(n.Right.Left, precedenceProduct)
+			}
+			return operand(n.Left, precedenceSum) + " + " + operand(n.Right, precedenceProduct)
+		case OperationSubtract:
+			right := operand(n.Right, precedenceProduct)
+			if n.Right.Operation == OperationNegate {
+				right = parentheses(right)
+			}
+			return operand(n.Left, precedenceSum) + " - " + right
+		case OperationMultiply:
+			right := operand(n.Right, precedenceProduct+1)
+			if n.Right.Operation == OperationNegate {
+				right = parentheses(right)
+			}
+			return operand(n.Left, precedenceProduct) + ` \cdot ` + right
+		case OperationDivide:
+			return `\frac{` + process(n.Left) + `}{` + process(n.Right) + `}`
+		case OperationModulus:
+			return operand(n.Left, precedenceProduct) + ` \bmod ` + operand(n.Right, precedenceProduct+1)
+		case OperationExponentiation:
+			return atom(n.Left) + `^{` + process(n.Right) + `}`
+		case OperationNegate:
+			if n.Left.precedence() == precedenceNegation {
+				return "-" + parentheses(process(n.Left))
+			}
+			return "-" + operand(n.Left, precedenceProduct)
+		case OperationVariable:
+			return latexName(n.Value)
+		case OperationImaginary:
+			if n.Value == "1" {
+				return "i"
+			}
+			return n.Value + "i"
+		case OperationNumber:
+			return n.Value
+		case OperationNotation:
+			s := process(n.Left) + ` \times 10^{` + process(n.Right) + `}`
+			if n.Left.Operation == OperationImaginary {
+				s = n.Left.Value + ` \times 10^{` + process(n.Right) + `} i`
+			}
+			return s
+		case OperationNaturalExponentiation:
+			return `e^{` + process(n.Left) + `}`
+		case OperationNatural:
+			return "e"
+		case OperationPI:
+			return `\pi`
+		case OperationInfinity:
+			return `\infty`
+		case OperationNaturalLogarithm, OperationCosine, OperationSine, OperationTangent,
+			OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			return latexFunctions[n.Operation] + parentheses(process(n.Left))
+		case OperationBeta, OperationGCD, OperationLCM, OperationInvMod, OperationCRT:
+			return latexFunctions[n.Operation] + parentheses(process(n.Left)+", "+process(n.Right))
+		case OperationPowMod:
+			arguments := make([]string, len(n.Arguments))
+			for i, argument := range n.Arguments {
+				arguments[i] = process(argument)
+			}
+			return latexFunctions[n.Operation] + parentheses(strings.Join(arguments, ", "))
+		case OperationJacobi:
+			// the Jacobi symbol is a fraction in parentheses
+			return parentheses(`\frac{` + process(n.Left) + `}{` + process(n.Right) + `}`)
+		case OperationZetaDerivative, OperationPolygamma:
+			// the order is a parenthesized superscript
+			return latexFunctions[n.Operation] + `^{(` + process(n.Right) + `)}` + parentheses(process(n.Left))
+		case OperationLambertW:
+			// the branch is a subscript unless it is the principal branch
+			if n.Right.Operation == OperationNumber && n.Right.Value == "0" {
+				return latexFunctions[n.Operation] + parentheses(process(n.Left))
+			}
+			return latexFunctions[n.Operation] + `_{` + process(n.Right) + `}` + parentheses(process(n.Left))
+		case OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			// the order is a subscript
+			return latexFunctions[n.Operation] + `_{` + process(n.Right) + `}` + parentheses(process(n.Left))
+		case OperationFactorial:
+			return atom(n.Left) + "!"
+		case OperationLogarithm:
+			return `\log_{` + process(n.Right) + `}` + parentheses(process(n.Left))
+		case OperationArctangent2:
+			return `\operatorname{atan2}` + parentheses(process(n.Left)+", "+process(n.Right))
+		case OperationSquareRoot:
+			return `\sqrt{` + process(n.Left) + `}`
+		case OperationCall:
+			arguments := make([]string, len(n.Arguments))
+			for i, argument := range n.Arguments {
+				arguments[i] = process(argument)
+			}
+			return latexName(n.Value) + parentheses(strings.Join(arguments, ", "))
+		case OperationMatrix:
+			rows := make([]string, len(n.Rows))
+			for i, row := range n.Rows {
+				elements := make([]string, len(row))
+				for j, element := range row {
+					elements[j] = process(element)
+				}
+				rows[i] = strings.Join(elements, " & ")
+			}
+			return `\begin{bmatrix}` + strings.Join(rows, ` \\ `) + `\end{bmatrix}`
+		case OperationIntegral:
+			return `\int ` + operand(n.Left, precedenceProduct) + `\,d` + process(n.Right)
+		}
+		return ""
+	}
+	return process(n)
+}
+
+// complexText formats a complex number with the real and imaginary parts
+// formatted by text, the imaginary unit is i
+func complexText(x *complex.Float, text func(x *big.Float) string) (real, sign, imaginary string) {
+	if x.B.Sign() == 0 {
+		return text(x.A), "", ""
+	}
+	b := new(big.Float).Abs(x.B)
+	imaginary = text(b)
+	if b.Cmp(big.NewFloat(1)) == 0 {
+		imaginary = ""
+	}
+	sign = "+"
+	if x.B.Sign() < 0 {
+		sign = "-"
+	}
+	if x.A.Sign() != 0 {
+		real = text(x.A)
+	} else if sign == "+" {
+		sign = ""
+	}
+	return real, sign, imaginary
+}
+
+// MatrixLaTeX returns the LaTeX form of a matrix, a 1x1 matrix is a number
+func MatrixLaTeX(m *complex.Matrix) string {
+	element := func(r *complex.Rational) string {
+		x := complex.NewFloat(newFloat(m.Prec), newFloat(m.Prec))
+		x.SetRat(r)
+		real, sign, imaginary := complexText(x, func(x *big.Float) string {
+			return latexDecimal(x.Text('g', 10))
+		})
+		if sign == "" && imaginary == "" && real != "" {
+			return real
+		}
+		if real != "" {
+			sign = " " + sign + " "
+		}
+		return real + sign + imaginary + "i"
+	}
+	if isScalar(m) {
+		return element(&m.Values[0][0])
+	}
+	rows := make([]string, len(m.Values))
+	for i, row := range m.Values {
+		elements := make([]string, len(row))
+		for j := range row {
+			elements[j] = element(&row[j])
+		}
+		rows[i] = strings.Join(elements, " & ")
+	}
+	return `\begin{bmatrix}` + strings.Join(rows, ` \\ `) + `\end{bmatrix}`
+}
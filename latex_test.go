@@ -0,0 +1,39 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"testing"
+)
+
+func TestLaTeX(t *testing.T) {
+	tests := []test{
+		{"x^2/(x + 1)", `\frac{x^{2}}{x + 1}`},
+		{"sqrt(x) + sin(x)", `\sqrt{x} + \sin\left(x\right)`},
+		{"log(x) + log(x, 2)", `\ln\left(x\right) + \log_{2}\left(x\right)`},
+		{"-(a - b)*c", `-\left(a - b\right) \cdot c`},
+		{"e^(x*y)", `e^{x \cdot y}`},
+		{"[x 1; 2 y]", `\begin{bmatrix}x & 1 \\ 2 & y\end{bmatrix}`},
+		{"gamma(x) + besselj(2, x)", `\Gamma\left(x\right) + J_{2}\left(x\right)`},
+		{"asin(x)^2", `\arcsin\left(x\right)^{2}`},
+		{"pi*x^(1/3)", `\pi \cdot x^{\frac{1}{3}}`},
+		{"gcd(a, b) + totient(n)", `\gcd\left(a, b\right) + \varphi\left(n\right)`},
+		{"jacobi(a, n)", `\left(\frac{a}{n}\right)`},
+	}
+	for _, test := range tests {
+		if result := parse(t, test.expression).LaTeX(); result != test.result {
+			t.Errorf("%s = %s, want %s", test.expression, result, test.result)
+		}
+	}
+
+	value, err := evaluate(NewEnvironment(), "[1 2; 3/2 4]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `\begin{bmatrix}1 & 2 \\ 1.5 & 4\end{bmatrix}`
+	if result := MatrixLaTeX(value.Matrix); result != want {
+		t.Errorf("[1 2; 3/2 4] = %s, want %s", result, want)
+	}
+}
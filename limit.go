@@ -0,0 +1,629 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+// maxHospital is the maximum number of times l'Hopital's rule is applied
+const maxHospital = 16
+
+// bound is the limit of an expression, either a finite value or an infinity
+// with the sign of infinite
+type bound struct {
+	value    *Node
+	infinite int
+}
+
+// finite is a finite limit
+func finite(value *Node) bound {
+	return bound{
+		value: value.Simplify(),
+	}
+}
+
+// zero tests if the limit is zero
+func (b bound) zero() bool {
+	return b.infinite == 0 && b.value.Equals(0)
+}
+
+// Node converts the limit into an expression
+func (b bound) Node() *Node {
+	switch b.infinite {
+	case 1:
+		return &Node{
+			Operation: OperationInfinity,
+		}
+	case -1:
+		return &Node{
+			Operation: OperationNegate,
+			Left: &Node{
+				Operation: OperationInfinity,
+			},
+		}
+	}
+	return b.value
+}
+
+// limiter computes the limits of expressions as the variable name approaches
+// the target from a side, the side is 1 for the right, -1 for the left and 0
+// for both sides
+type limiter struct {
+	name   string
+	target bound
+	side   int
+	env    *Environment
+	prec   uint
+}
+
+// Limit computes the limit of the expression as the variable name approaches
+// the target, which is inf, -inf or an expression which doesn't depend on the
+// variable. The side is 1 for the limit from the right, -1 for the limit from
+// the left and 0 for the limit from both sides. The limit is computed from the
+// leading term of the series of the expression, with l'Hopital's rule for the
+// indeterminate forms which don't have a series. A bounded expression which
+// oscillates, like sin(x) as x approaches inf, times a vanishing expression has
+// the limit zero. The signs of the constants
+// are computed at precision prec with the variables in env.
+func (n *Node) Limit(env *Environment, name string, target *Node, side int, prec uint) (*Node, error) {
+	if err := checkPrec(n, prec); err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	reservePI(prec)
+	return n.limit(env, name, target, side, prec)
+}
+
+// limit computes the limit of the expression
+func (n *Node) limit(env *Environment, name string, target *Node, side int, prec uint) (*Node, error) {
+	l := &limiter{
+		name: name,
+		side: side,
+		env:  env,
+		prec: prec,
+	}
+	switch {
+	case target.Operation == OperationInfinity:
+		l.target.infinite = 1
+	case target.Operation == OperationNegate && target.Left.Operation == OperationInfinity:
+		l.target.infinite = -1
+	case target.depends(name):
+		return nil, newNodeError(ErrorTypeValue, target, "the target can not depend on %s", name)
+	default:
+		l.target = finite(target)
+	}
+	b, err := l.process(n.Simplify(), 0)
+	if err != nil {
+		return nil, err
+	}
+	return b.Node(), nil
+}
+
+// sign computes the sign of a real constant
+func (l *limiter) sign(n *Node) (int, error) {
+	if r, ok := number(n); ok && r.B.Sign() == 0 {
+		return r.A.Sign(), nil
+	}
+	m, err := n.evaluate(l.env, l.prec, 0)
+	if err != nil || !isScalar(m) || m.Values[0][0].B.Sign() != 0 {
+		return 0, newNodeError(ErrorTypeAmbiguous, n, "the sign of %s is unknown", n.String())
+	}
+	return m.Values[0][0].A.Sign(), nil
+}
+
+// expand computes the series of the expression in the distance from the
+// target, a target at infinity is moved to zero
+func (l *limiter) expand(n *Node) (*series, int, error) {
+	x := &Node{
+		Operation: OperationVariable,
+		Value:     l.name,
+	}
+	if l.target.infinite != 0 {
+		// x = 1/t or x = -1/t as t approaches zero from the right
+		t := &Node{
+			Operation: OperationDivide,
+			Left:      ratio(int64(l.target.infinite), 1),
+			Right:     x,
+		}
+		s, err := n.Substitute(map[string]*Node{l.name: t}).expansion(l.name, ratio(0, 1), 1)
+		return s, 1, err
+	}
+	s, err := n.expansion(l.name, l.target.value, 1)
+	return s, l.side, err
+}
+
+// leading computes the limit from the leading term c t^v of the series
+func (l *limiter) leading(n *Node, s *series, side int) (bound, error) {
+	if s.valuation > 0 {
+		return finite(ratio(0, 1)), nil
+	} else if s.valuation == 0 {
+		return finite(s.coefficients[0]), nil
+	}
+	sign, err := l.sign(s.coefficients[0].Simplify())
+	if err != nil {
+		return bound{}, err
+	}
+	if s.valuation%2 != 0 {
+		switch side {
+		case 0:
+			return bound{}, newNodeError(ErrorTypeDomain, n,
+				"the limits of %s from the left and the right differ", n.String())
+		case -1:
+			sign = -sign
+		}
+	}
+	return bound{
+		infinite: sign,
+	}, nil
+}
+
+// pole computes the sign of the infinite limit of 1/n where the limit of n is
+// zero
+func (l *limiter) pole(n *Node) (int, error) {
+	s, side, err := l.expand(n)
+	if err != nil {
+		return 0, err
+	}
+	inverse, err := s.inverse()
+	if err != nil {
+		return 0, newNodeError(ErrorTypeDomain, n, "%s is zero near the target", n.String())
+	}
+	b, err := l.leading(n, inverse, side)
+	if err != nil {
+		return 0, err
+	} else if b.infinite == 0 {
+		return 0, newNodeError(ErrorTypeDomain, n, "the limit of 1/(%s) is finite", n.String())
+	}
+	return b.infinite, nil
+}
+
+// indeterminate is the error of an indeterminate form
+func indeterminate(n *Node) error {
+	return newNodeError(ErrorTypeDomain, n, "the limit of %s is indeterminate", n.String())
+}
+
+// hospital computes the limit of p/q with l'Hopital's rule
+func (l *limiter) hospital(n, p, q *Node, depth int) (bound, error) {
+	if depth >= maxHospital {
+		return bound{}, newNodeError(ErrorTypeConvergence, n,
+			"l'Hopital's rule was applied %d times to %s", maxHospital, n.String())
+	}
+	dp, dq := p.DerivativeWith(l.name), q.DerivativeWith(l.name)
+	if dp == nil || dq == nil {
+		return bound{}, indeterminate(n)
+	}
+	return l.process((&Node{
+		Operation: OperationDivide,
+		Left:      dp,
+		Right:     dq,
+	}).Simplify(), depth+1)
+}
+
+// reciprocal is 1/n
+func reciprocal(n *Node) *Node {
+	return &Node{
+		Operation: OperationDivide,
+		Left:      ratio(1, 1),
+		Right:     n,
+	}
+}
+
+// bounded tests if the expression is bounded near the target, the sine and the
+// cosine of a real argument are bounded even if they oscillate without a limit
+func (l *limiter) bounded(n *Node, depth int) bool {
+	switch n.Operation {
+	case OperationSine, OperationCosine:
+		if _, err := l.process(n.Left, depth); err == nil {
+			return true
+		} else if l.side != 0 || l.target.infinite != 0 {
+			return false
+		}
+		// the argument may approach different infinities from the two sides
+		for _, side := range []int{-1, 1} {
+			one := *l
+			one.side = side
+			if _, err := one.process(n.Left, depth); err != nil {
+				return false
+			}
+		}
+		return true
+	case OperationAdd, OperationSubtract, OperationMultiply:
+		return l.bounded(n.Left, depth) && l.bounded(n.Right, depth)
+	case OperationNegate:
+		return l.bounded(n.Left, depth)
+	case OperationExponentiation:
+		if r, ok := rational(n.Right); ok && r.IsInt() && r.Sign() >= 0 {
+			return l.bounded(n.Left, depth)
+		}
+	}
+	b, err := l.process(n, depth)
+	return err == nil && b.infinite == 0
+}
+
+// process computes the limit of the expression, depth is the number of times
+// l'Hopital's rule has been applied
+func (l *limiter) process(n *Node, depth int) (bound, error) {
+	if !n.depends(l.name) {
+		return finite(n), nil
+	}
+	if s, side, err := l.expand(n); err == nil {
+		return l.leading(n, s, side)
+	}
+	// the expression doesn't have a series at the target
+	limits := func(nodes ...*Node) ([]bound, error) {
+		bounds := make([]bound, len(nodes))
+		for i, node := range nodes {
+			b, err := l.process(node, depth)
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = b
+		}
+		return bounds, nil
+	}
+	// times computes the product of two limits which aren't zero times infinity
+	times := func(a, b bound) (bound, error) {
+		if a.infinite == 0 && b.infinite == 0 {
+			return finite(&Node{
+				Operation: OperationMultiply,
+				Left:      a.value,
+				Right:     b.value,
+			}), nil
+		}
+		sign := 1
+		for _, x := range []bound{a, b} {
+			if x.infinite != 0 {
+				sign *= x.infinite
+				continue
+			}
+			s, err := l.sign(x.value)
+			if err != nil {
+				return bound{}, err
+			}
+			sign *= s
+		}
+		return bound{
+			infinite: sign,
+		}, nil
+	}
+	switch n.Operation {
+	case OperationVariable:
+		return l.target, nil
+	case OperationAdd, OperationSubtract:
+		bounds, err := limits(n.Left, n.Right)
+		if err != nil {
+			return bound{}, err
+		}
+		a, b := bounds[0], bounds[1]
+		if n.Operation == OperationSubtract {
+			b.infinite = -b.infinite
+			if b.infinite == 0 {
+				b = finite(negate(b.value))
+			}
+		}
+		switch {
+		case a.infinite == 0 && b.infinite == 0:
+			return finite(&Node{
+				Operation: OperationAdd,
+				Left:      a.value,
+				Right:     b.value,
+			}), nil
+		case a.infinite == 0:
+			return b, nil
+		case b.infinite == 0 || a.infinite == b.infinite:
+			return a, nil
+		}
+		return bound{}, indeterminate(n)
+	case OperationNegate:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		} else if b.infinite != 0 {
+			b.infinite = -b.infinite
+			return b, nil
+		}
+		return finite(negate(b.value)), nil
+	case OperationMultiply:
+		a, aerr := l.process(n.Left, depth)
+		b, berr := l.process(n.Right, depth)
+		switch {
+		case aerr != nil && berr == nil && b.zero() && l.bounded(n.Left, depth),
+			berr != nil && aerr == nil && a.zero() && l.bounded(n.Right, depth):
+			// a bounded expression times a vanishing expression vanishes
+			return finite(ratio(0, 1)), nil
+		case aerr != nil:
+			return bound{}, aerr
+		case berr != nil:
+			return bound{}, berr
+		}
+		switch {
+		case a.zero() && b.infinite != 0:
+			// 0 * inf = inf / (1/0)
+			return l.hospital(n, n.Right, reciprocal(n.Left), depth)
+		case b.zero() && a.infinite != 0:
+			return l.hospital(n, n.Left, reciprocal(n.Right), depth)
+		}
+		return times(a, b)
+	case OperationDivide:
+		a, aerr := l.process(n.Left, depth)
+		b, berr := l.process(n.Right, depth)
+		switch {
+		case aerr != nil && berr == nil && b.infinite != 0 && l.bounded(n.Left, depth):
+			// a bounded expression over an unbounded expression vanishes
+			return finite(ratio(0, 1)), nil
+		case aerr != nil:
+			return bound{}, aerr
+		case berr != nil:
+			return bound{}, berr
+		}
+		switch {
+		case (a.zero() && b.zero()) || (a.infinite != 0 && b.infinite != 0):
+			return l.hospital(n, n.Left, n.Right, depth)
+		case b.zero():
+			sign, err := l.pole(n.Right)
+			if err != nil {
+				return bound{}, err
+			}
+			return times(a, bound{
+				infinite: sign,
+			})
+		case b.infinite != 0:
+			return finite(ratio(0, 1)), nil
+		case a.infinite != 0:
+			sign, err := l.sign(b.value)
+			if err != nil {
+				return bound{}, err
+			}
+			a.infinite *= sign
+			return a, nil
+		}
+		return finite(&Node{
+			Operation: OperationDivide,
+			Left:      a.value,
+			Right:     b.value,
+		}), nil
+	case OperationExponentiation, OperationSquareRoot:
+		exponent := ratio(1, 2)
+		if n.Operation == OperationExponentiation {
+			exponent = n.Right
+		}
+		if exponent.depends(l.name) {
+			// f^g = e^(g log f)
+			return l.process(&Node{
+				Operation: OperationNaturalExponentiation,
+				Left: &Node{
+					Operation: OperationMultiply,
+					Left:      exponent,
+					Right: &Node{
+						Operation: OperationNaturalLogarithm,
+						Left:      n.Left,
+					},
+				},
+			}, depth)
+		}
+		base, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		}
+		exponent = exponent.Simplify()
+		if exponent.Equals(0) {
+			return finite(ratio(1, 1)), nil
+		}
+		sign, err := l.sign(exponent)
+		if err != nil {
+			return bound{}, err
+		}
+		switch {
+		case base.infinite == 0 && !base.zero():
+			return finite(&Node{
+				Operation: OperationExponentiation,
+				Left:      base.value,
+				Right:     exponent,
+			}), nil
+		case sign < 0:
+			// f^-r = 1/f^r
+			return l.process(reciprocal(&Node{
+				Operation: OperationExponentiation,
+				Left:      n.Left,
+				Right:     negate(exponent),
+			}), depth)
+		case base.zero():
+			return finite(ratio(0, 1)), nil
+		case base.infinite > 0:
+			return base, nil
+		}
+		// the sign of (-inf)^r
+		if r, ok := rational(exponent); ok && r.IsInt() {
+			if r.Num().Bit(0) == 0 {
+				base.infinite = 1
+			}
+			return base, nil
+		}
+		return bound{}, newNodeError(ErrorTypeDomain, n, "the limit of %s is not real", n.String())
+	case OperationNaturalExponentiation:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		}
+		switch b.infinite {
+		case 1:
+			return b, nil
+		case -1:
+			return finite(ratio(0, 1)), nil
+		}
+		return finite(&Node{
+			Operation: OperationNaturalExponentiation,
+			Left:      b.value,
+		}), nil
+	case OperationNaturalLogarithm:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		}
+		switch {
+		case b.infinite == 1:
+			return b, nil
+		case b.infinite == -1:
+			return bound{}, newNodeError(ErrorTypeDomain, n, "the limit of %s is not real", n.String())
+		case b.zero():
+			return bound{
+				infinite: -1,
+			}, nil
+		}
+		return finite(&Node{
+			Operation: OperationNaturalLogarithm,
+			Left:      b.value,
+		}), nil
+	case OperationSine, OperationCosine, OperationTangent:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		} else if b.infinite != 0 {
+			return bound{}, newNodeError(ErrorTypeDomain, n, "%s oscillates without a limit", n.String())
+		}
+		return finite(&Node{
+			Operation: n.Operation,
+			Left:      b.value,
+		}), nil
+	case OperationSecant, OperationCosecant, OperationCotangent:
+		return l.process(trigonometric(n), depth)
+	case OperationLogarithm:
+		return l.process(&Node{
+			Operation: OperationDivide,
+			Left: &Node{
+				Operation: OperationNaturalLogarithm,
+				Left:      n.Left,
+			},
+			Right: &Node{
+				Operation: OperationNaturalLogarithm,
+				Left:      n.Right,
+			},
+		}, depth)
+	case OperationArcsine, OperationArccosine, OperationArctangent,
+		OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+		OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		} else if b.infinite == 0 {
+			return finite(&Node{
+				Operation: n.Operation,
+				Left:      b.value,
+			}), nil
+		}
+		// the limits at the infinities
+		switch n.Operation {
+		case OperationArctangent:
+			return finite(&Node{
+				Operation: OperationDivide,
+				Left: &Node{
+					Operation: OperationMultiply,
+					Left:      ratio(int64(b.infinite), 1),
+					Right: &Node{
+						Operation: OperationPI,
+					},
+				},
+				Right: ratio(2, 1),
+			}), nil
+		case OperationHyperbolicTangent:
+			return finite(ratio(int64(b.infinite), 1)), nil
+		case OperationHyperbolicSine, OperationHyperbolicArcsine:
+			return b, nil
+		case OperationHyperbolicCosine:
+			return bound{
+				infinite: 1,
+			}, nil
+		case OperationHyperbolicArccosine:
+			if b.infinite > 0 {
+				return b, nil
+			}
+		}
+		return bound{}, newNodeError(ErrorTypeDomain, n, "the limit of %s is not real", n.String())
+	case OperationArctangent2:
+		bounds, err := limits(n.Left, n.Right)
+		if err != nil {
+			return bound{}, err
+		}
+		y, x := bounds[0], bounds[1]
+		switch {
+		case y.infinite == 0 && x.infinite == 0:
+			return finite(&Node{
+				Operation: OperationArctangent2,
+				Left:      y.value,
+				Right:     x.value,
+			}), nil
+		case y.infinite == 0 && x.infinite > 0:
+			return finite(ratio(0, 1)), nil
+		case x.infinite == 0 && y.infinite != 0:
+			return finite(&Node{
+				Operation: OperationArctangent2,
+				Left:      ratio(int64(y.infinite), 1),
+				Right:     ratio(0, 1),
+			}), nil
+		}
+		return bound{}, indeterminate(n)
+	case OperationFactorial:
+		return l.process(&Node{
+			Operation: OperationGamma,
+			Left:      addition(n.Left, ratio(1, 1)),
+		}, depth)
+	case OperationGamma, OperationLogGamma, OperationDigamma, OperationPolygamma,
+		OperationErf, OperationErfc, OperationZeta, OperationZetaDerivative:
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		} else if b.infinite == 0 {
+			return finite(&Node{
+				Operation: n.Operation,
+				Left:      b.value,
+				Right:     n.Right,
+			}), nil
+		}
+		// the limits at the infinities, the derivatives of the digamma and
+		// zeta functions vanish
+		derivative := n.Right != nil && !n.Right.Equals(0)
+		switch {
+		case n.Operation == OperationErf:
+			return finite(ratio(int64(b.infinite), 1)), nil
+		case n.Operation == OperationErfc:
+			return finite(ratio(int64(1-b.infinite), 1)), nil
+		case b.infinite < 0:
+			return bound{}, newNodeError(ErrorTypeDomain, n, "%s oscillates without a limit", n.String())
+		case derivative:
+			return finite(ratio(0, 1)), nil
+		case n.Operation == OperationZeta || n.Operation == OperationZetaDerivative:
+			return finite(ratio(1, 1)), nil
+		}
+		return b, nil
+	case OperationLambertW, OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK,
+		OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+		OperationEllipticK, OperationEllipticE:
+		// the functions are continuous at the finite arguments, the branch and
+		// the order are parameters
+		b, err := l.process(n.Left, depth)
+		if err != nil {
+			return bound{}, err
+		} else if b.infinite == 0 {
+			return finite(&Node{
+				Operation: n.Operation,
+				Left:      b.value,
+				Right:     n.Right,
+			}), nil
+		}
+	case OperationBeta:
+		bounds, err := limits(n.Left, n.Right)
+		if err != nil {
+			return bound{}, err
+		} else if bounds[0].infinite == 0 && bounds[1].infinite == 0 {
+			return finite(&Node{
+				Operation: OperationBeta,
+				Left:      bounds[0].value,
+				Right:     bounds[1].value,
+			}), nil
+		}
+	}
+	return bound{}, newNodeError(ErrorTypeValue, n, "the limit of %s can not be computed", n.String())
+}
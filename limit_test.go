@@ -0,0 +1,54 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"testing"
+)
+
+func TestLimit(t *testing.T) {
+	run(t, []test{
+		{"limit(x^2, x, 3)", "9"},
+		{"limit(sin(x)/x, x, 0)", "1"},
+		{"limit((1 - cos(x))/x^2, x, 0)", "1 / 2"},
+		{"limit((x^2 - 1)/(x - 1), x, 1)", "2"},
+		{"limit(x*log(x), x, 0, +)", "0"},
+		{"limit(1/x, x, 0, +)", "inf"},
+		{"limit(1/x, x, 0, -)", "-inf"},
+		{"limit(1/x^2, x, 0)", "inf"},
+		{"limit((1 + 1/x)^x, x, inf)", "e"},
+		{"eval(limit((1 + 1/y)^y, y, inf))", "2.718281828"},
+		{"derivative(limit((1 + 1/y)^y, y, inf)*x)", "e"},
+		{"limit(exp(-x)*x^3, x, inf)", "0"},
+		{"limit(atan(x), x, inf)", "pi / 2"},
+		{"limit(x, x, -inf)", "-inf"},
+		{"limit(sin(x)/x, x, y)", "sin(y) / y"},
+		// bounded expressions which oscillate times vanishing expressions
+		{"limit(sin(x)/x, x, inf)", "0"},
+		{"limit(cos(x)/x^2, x, -inf)", "0"},
+		{"limit((sin(x) + cos(2*x))^2/x, x, inf)", "0"},
+		{"limit(exp(-x)*cos(x), x, inf)", "0"},
+		{"limit(x*cos(1/x), x, 0)", "0"},
+		{"limit(x^2*sin(1/x), x, 0, +)", "0"},
+	})
+	runErrors(t, []errorTest{
+		{"limit(1/x, x, 0)", ErrorTypeDomain, 0, 0},
+		{"limit(sin(1/x), x, 0)", ErrorTypeDomain, 0, 0},
+		{"limit(x*sin(x), x, inf)", ErrorTypeDomain, 0, 0},
+		{"limit(sin(x)^-2/x, x, inf)", ErrorTypeDomain, 0, 0},
+		{"limit(y, pi, 0)", ErrorTypeValue, 9, 11},
+	})
+
+	n, target := parse(t, "sin(x)/x"), parse(t, "0")
+	a, err := n.Limit(nil, "x", target, 1, DefaultPrec)
+	if err != nil {
+		t.Fatal(err)
+	} else if a.String() != "1" {
+		t.Errorf("the limit of %s from the right = %s, want 1", n, a)
+	}
+	if _, err := n.Limit(nil, "x", target, 0, 0); err == nil {
+		t.Error("Limit at zero precision didn't fail")
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// mathmlFunctions are the MathML names of the built in functions
+var mathmlFunctions = map[Operation]string{
+	OperationNaturalLogarithm:     "ln",
+	OperationCosine:               "cos",
+	OperationSine:                 "sin",
+	OperationTangent:              "tan",
+	OperationArcsine:              "arcsin",
+	OperationArccosine:            "arccos",
+	OperationArctangent:           "arctan",
+	OperationHyperbolicSine:       "sinh",
+	OperationHyperbolicCosine:     "cosh",
+	OperationHyperbolicTangent:    "tanh",
+	OperationHyperbolicArcsine:    "arsinh",
+	OperationHyperbolicArccosine:  "arcosh",
+	OperationHyperbolicArctangent: "artanh",
+	OperationSecant:               "sec",
+	OperationCosecant:             "csc",
+	OperationCotangent:            "cot",
+	OperationGamma:                "&#x393;",
+	OperationLogGamma:             "ln&#x393;",
+	OperationBeta:                 "B",
+	OperationErf:                  "erf",
+	OperationErfc:                 "erfc",
+	OperationZeta:                 "&#x3B6;",
+	OperationZetaDerivative:       "&#x3B6;",
+	OperationDigamma:              "&#x3C8;",
+	OperationPolygamma:            "&#x3C8;",
+	OperationLambertW:             "W",
+	OperationBesselJ:              "J",
+	OperationBesselY:              "Y",
+	OperationBesselI:              "I",
+	OperationBesselK:              "K",
+	OperationAiryAi:               "Ai",
+	OperationAiryBi:               "Bi",
+	OperationAiryAiPrime:          "Ai&#x2032;",
+	OperationAiryBiPrime:          "Bi&#x2032;",
+	OperationEllipticK:            "K",
+	OperationEllipticE:            "E",
+	OperationGCD:                  "gcd",
+	OperationLCM:                  "lcm",
+	OperationIsPrime:              "isprime",
+	OperationNextPrime:            "nextprime",
+	OperationFactor:               "factor",
+	OperationTotient:              "&#x3C6;",
+	OperationPowMod:               "powmod",
+	OperationInvMod:               "invmod",
+	OperationCRT:                  "crt",
+}
+
+// mathml wraps the presentation markup in a math element
+func mathml(s string) string {
+	return `<math xmlns="http://www.w3.org/1998/Math/MathML">` + s + `</math>`
+}
+
+// mrow groups elements
+func mrow(elements ...string) string {
+	return "<mrow>" + strings.Join(elements, "") + "</mrow>"
+}
+
+// mo is an operator
+func mo(operator string) string {
+	return "<mo>" + operator + "</mo>"
+}
+
+// mn is a number, a negative number has a separate minus sign
+func mn(number string) string {
+	if strings.HasPrefix(number, "-") {
+		return mrow(mo("-"), "<mn>"+number[1:]+"</mn>")
+	}
+	return "<mn>" + strings.TrimPrefix(number, "+") + "</mn>"
+}
+
+// mi is an identifier
+func mi(identifier string) string {
+	return "<mi>" + identifier + "</mi>"
+}
+
+// fenced surrounds the elements with parentheses
+func fenced(elements ...string) string {
+	return mrow(append(append([]string{mo("(")}, elements...), mo(")"))...)
+}
+
+// mathmlDecimal is the markup of a decimal number which may have an exponent
+func mathmlDecimal(s string) string {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exponent, err := strconv.Atoi(s[i+1:]); err == nil {
+			return mrow(mn(s[:i]), mo("&#xD7;"), "<msup>"+mn("10")+mn(strconv.Itoa(exponent))+"</msup>")
+		}
+	}
+	return mn(s)
+}
+
+// MathML returns the MathML form of the equation
+func (n *Node) MathML() string {
+	var process func(n *Node) string
+	// operand parenthesizes the operand if it binds looser than the minimum
+	operand := func(n *Node, minimum int) string {
+		s := process(n)
+		if n.precedence() < minimum {
+			return fenced(s)
+		}
+		return s
+	}
+	// atom parenthesizes the operand unless it is a value
+	atom := func(n *Node) string {
+		s := process(n)
+		if n.precedence() < precedenceValue || n.Operation == OperationDivide ||
+			n.Operation == OperationImaginary || n.Operation == OperationNotation {
+			return fenced(s)
+		}
+		return s
+	}
+	process = func(n *Node) string {
+		if n == nil {
+			return ""
+		}
+		switch n.Operation {
+		case OperationAdd:
+			if n.Right.Operation == OperationNegate {
+				// a + -b is written as a - b
+				return mrow(operand(n.Left, precedenceSum), mo("-"), operand(n.Right.Left, precedenceProduct))
+			}
+			return mrow(operand(n.Left, precedenceSum), mo("+"), operand(n.Right, precedenceProduct))
+		case OperationSubtract:
+			right := operand(n.Right, precedenceProduct)
+			if n.Right.Operation == OperationNegate {
+				right = fenced(right)
+			}
+			return mrow(operand(n.Left, precedenceSum), mo("-"), right)
+		case OperationMultiply:
+			right := operand(n.Right, precedenceProduct+1)
+			if n.Right.Operation == OperationNegate {
+				right = fenced(right)
+			}
+			return mrow(operand(n.Left, precedenceProduct), mo("&#x22C5;"), right)
+		case OperationDivide:
+			return "<mfrac>" + mrow(process(n.Left)) + mrow(process(n.Right)) + "</mfrac>"
+		case OperationModulus:
+			return mrow(operand(n.Left, precedenceProduct), mo("mod"), operand(n.Right, precedenceProduct+1))
+		case OperationExponentiation:
+			return "<msup>" + mrow(atom(n.Left)) + mrow(process(n.Right)) + "</msup>"
+		case OperationNegate:
+			if n.Left.precedence() == precedenceNegation {
+				return mrow(mo("-"), fenced(process(n.Left)))
+			}
+			return mrow(mo("-"), operand(n.Left, precedenceProduct))
+		case OperationVariable:
+			return mi(n.Value)
+		case OperationImaginary:
+			if n.Value == "1" {
+				return mi("i")
+			}
+			return mrow(mn(n.Value), mo("&#x2062;"), mi("i"))
+		case OperationNumber:
+			return mn(n.Value)
+		case OperationNotation:
+			s := mrow(mn(n.Left.Value), mo("&#xD7;"), "<msup>"+mn("10")+mn(n.Right.Value)+"</msup>")
+			if n.Left.Operation == OperationImaginary {
+				s = mrow(s, mo("&#x2062;"), mi("i"))
+			}
+			return s
+		case OperationNaturalExponentiation:
+			return "<msup>" + mi("e") + mrow(process(n.Left)) + "</msup>"
+		case OperationNatural:
+			return mi("e")
+		case OperationPI:
+			return mi("&#x3C0;")
+		case OperationInfinity:
+			return mi("&#x221E;")
+		case OperationNaturalLogarithm, OperationCosine, OperationSine, OperationTangent,
+			OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			return mrow(mi(mathmlFunctions[n.Operation]), mo("&#x2061;"), fenced(process(n.Left)))
+		case OperationBeta, OperationGCD, OperationLCM, OperationInvMod, OperationCRT:
+			return mrow(mi(mathmlFunctions[n.Operation]), mo("&#x2061;"), fenced(process(n.Left), mo(","), process(n.Right)))
+		case OperationPowMod:
+			var arguments []string
+			for i, argument := range n.Arguments {
+				if i > 0 {
+					arguments = append(arguments, mo(","))
+				}
+				arguments = append(arguments, process(argument))
+			}
+			return mrow(mi(mathmlFunctions[n.Operation]), mo("&#x2061;"), fenced(arguments...))
+		case OperationJacobi:
+			// the Jacobi symbol is a fraction in parentheses
+			return fenced("<mfrac>" + mrow(process(n.Left)) + mrow(process(n.Right)) + "</mfrac>")
+		case OperationZetaDerivative, OperationPolygamma:
+			// the order is a parenthesized superscript
+			function := "<msup>" + mi(mathmlFunctions[n.Operation]) + fenced(process(n.Right)) + "</msup>"
+			return mrow(function, mo("&#x2061;"), fenced(process(n.Left)))
+		case OperationLambertW:
+			// the branch is a subscript unless it is the principal branch
+			if n.Right.Operation == OperationNumber && n.Right.Value == "0" {
+				return mrow(mi(mathmlFunctions[n.Operation]), mo("&#x2061;"), fenced(process(n.Left)))
+			}
+			function := "<msub>" + mi(mathmlFunctions[n.Operation]) + mrow(process(n.Right)) + "</msub>"
+			return mrow(function, mo("&#x2061;"), fenced(process(n.Left)))
+		case OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			// the order is a subscript
+			function := "<msub>" + mi(mathmlFunctions[n.Operation]) + mrow(process(n.Right)) + "</msub>"
+			return mrow(function, mo("&#x2061;"), fenced(process(n.Left)))
+		case OperationFactorial:
+			return mrow(atom(n.Left), mo("!"))
+		case OperationLogarithm:
+			return mrow("<msub>"+mi("log")+mrow(process(n.Right))+"</msub>", mo("&#x2061;"), fenced(process(n.Left)))
+		case OperationArctangent2:
+			return mrow(mi("atan2"), mo("&#x2061;"), fenced(process(n.Left), mo(","), process(n.Right)))
+		case OperationSquareRoot:
+			return "<msqrt>" + process(n.Left) + "</msqrt>"
+		case OperationCall:
+			var arguments []string
+			for i, argument := range n.Arguments {
+				if i > 0 {
+					arguments = append(arguments, mo(","))
+				}
+				arguments = append(arguments, process(argument))
+			}
+			return mrow(mi(n.Value), mo("&#x2061;"), fenced(arguments...))
+		case OperationMatrix:
+			s := "<mtable>"
+			for _, row := range n.Rows {
+				s += "<mtr>"
+				for _, element := range row {
+					s += "<mtd>" + process(element) + "</mtd>"
+				}
+				s += "</mtr>"
+			}
+			return mrow(mo("["), s+"</mtable>", mo("]"))
+		case OperationIntegral:
+			return mrow(mo("&#x222B;"), operand(n.Left, precedenceProduct), mo("&#x2146;"), process(n.Right))
+		}
+		return ""
+	}
+	return mathml(process(n))
+}
+
+// MatrixMathML returns the MathML form of a matrix, a 1x1 matrix is a number
+func MatrixMathML(m *complex.Matrix) string {
+	element := func(r *complex.Rational) string {
+		x := complex.NewFloat(newFloat(m.Prec), newFloat(m.Prec))
+		x.SetRat(r)
+		real, sign, imaginary := complexText(x, func(x *big.Float) string {
+			return mathmlDecimal(x.Text('g', 10))
+		})
+		if sign == "" && imaginary == "" && real != "" {
+			return real
+		}
+		var elements []string
+		if real != "" {
+			elements = append(elements, real)
+		}
+		if sign != "" {
+			elements = append(elements, mo(sign))
+		}
+		if imaginary != "" {
+			elements = append(elements, imaginary, mo("&#x2062;"))
+		}
+		return mrow(append(elements, mi("i"))...)
+	}
+	if isScalar(m) {
+		return mathml(element(&m.Values[0][0]))
+	}
+	s := "<mtable>"
+	for _, row := range m.Values {
+		s += "<mtr>"
+		for j := range row {
+			s += "<mtd>" + element(&row[j]) + "</mtd>"
+		}
+		s += "</mtr>"
+	}
+	return mathml(mrow(mo("["), s+"</mtable>", mo("]")))
+}
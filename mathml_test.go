@@ -0,0 +1,65 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// wellFormed tests if the string is well formed XML
+func wellFormed(s string) bool {
+	decoder := xml.NewDecoder(strings.NewReader(s))
+	decoder.Entity = xml.HTMLEntity
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+func TestMathML(t *testing.T) {
+	const math = `<math xmlns="http://www.w3.org/1998/Math/MathML">`
+	tests := []test{
+		{"x^2/(x + 1)", math + `<mfrac><mrow><msup><mrow><mi>x</mi></mrow><mrow><mn>2</mn></mrow></msup></mrow><mrow><mrow><mi>x</mi><mo>+</mo><mn>1</mn></mrow></mrow></mfrac></math>`},
+		{"sqrt(x) + sin(x)", math + `<mrow><msqrt><mi>x</mi></msqrt><mo>+</mo><mrow><mi>sin</mi><mo>&#x2061;</mo><mrow><mo>(</mo><mi>x</mi><mo>)</mo></mrow></mrow></mrow></math>`},
+		{"-(a - b)*c", math + `<mrow><mrow><mo>-</mo><mrow><mo>(</mo><mrow><mi>a</mi><mo>-</mo><mi>b</mi></mrow><mo>)</mo></mrow></mrow><mo>&#x22C5;</mo><mi>c</mi></mrow></math>`},
+		{"[x 1; 2 y]", math + `<mrow><mo>[</mo><mtable><mtr><mtd><mi>x</mi></mtd><mtd><mn>1</mn></mtd></mtr><mtr><mtd><mn>2</mn></mtd><mtd><mi>y</mi></mtd></mtr></mtable><mo>]</mo></mrow></math>`},
+		{"x! + 2i", math + `<mrow><mrow><mi>x</mi><mo>!</mo></mrow><mo>+</mo><mrow><mn>2</mn><mo>&#x2062;</mo><mi>i</mi></mrow></mrow></math>`},
+		{"gamma(x)", math + `<mrow><mi>&#x393;</mi><mo>&#x2061;</mo><mrow><mo>(</mo><mi>x</mi><mo>)</mo></mrow></mrow></math>`},
+	}
+	for _, test := range tests {
+		if result := parse(t, test.expression).MathML(); result != test.result {
+			t.Errorf("%s = %s, want %s", test.expression, result, test.result)
+		}
+	}
+
+	expressions := []string{
+		"log(x, 2) + e^(x*y) - pi",
+		"besselj(2, x)*airyai(x) + lambertw(x, -1)",
+		"powmod(a, b, n) + jacobi(a, n) + gcd(a, b)",
+		"atan2(y, x) + asinh(x)/cosh(x)",
+		"(x^(1/3))^2 % 5",
+		"[x^2 1/x; -y sqrt(y)]",
+	}
+	for _, expression := range expressions {
+		if result := parse(t, expression).MathML(); !wellFormed(result) {
+			t.Errorf("%s = %s isn't well formed", expression, result)
+		}
+	}
+
+	value, err := evaluate(NewEnvironment(), "[1 2; 3/2 4]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := math + `<mrow><mo>[</mo><mtable><mtr><mtd><mn>1</mn></mtd><mtd><mn>2</mn></mtd></mtr><mtr><mtd><mn>1.5</mn></mtd><mtd><mn>4</mn></mtd></mtr></mtable><mo>]</mo></mrow></math>`
+	if result := MatrixMathML(value.Matrix); result != want {
+		t.Errorf("[1 2; 3/2 4] = %s, want %s", result, want)
+	}
+}
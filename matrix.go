@@ -0,0 +1,956 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// isScalar tests if a matrix is 1x1
+func isScalar(m *complex.Matrix) bool {
+	return len(m.Values) == 1 && len(m.Values[0]) == 1
+}
+
+// isZero tests if a complex rational is zero
+func isZero(r *complex.Rational) bool {
+	return r.A.Sign() == 0 && r.B.Sign() == 0
+}
+
+// isInteger tests if a complex rational is a real integer
+func isInteger(r *complex.Rational) bool {
+	return r.A.IsInt() && r.B.Sign() == 0
+}
+
+// dimensions returns the number of rows and columns of a matrix
+func dimensions(m *complex.Matrix) (rows, columns int) {
+	rows = len(m.Values)
+	if rows > 0 {
+		columns = len(m.Values[0])
+	}
+	return rows, columns
+}
+
+// newScalar creates a 1x1 matrix
+func newScalar(prec uint, a *complex.Rational) *complex.Matrix {
+	m := complex.NewMatrix(prec)
+	m.Values = [][]complex.Rational{[]complex.Rational{*a}}
+	return &m
+}
+
+// parseNumber parses a decimal number with an optional exponent in E notation
+func parseNumber(decimal, exponent string, prec uint) *complex.Rational {
+	a := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
+	a.A.SetString(decimal)
+	if exponent != "" {
+		b := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
+		b.A.SetString(exponent)
+		ten := complex.NewRational(big.NewRat(10, 1), big.NewRat(0, 1))
+		x := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
+		x.SetRat(ten)
+		y := complex.NewFloat(big.NewFloat(0).SetPrec(prec), big.NewFloat(0).SetPrec(prec))
+		y.SetRat(b)
+		x.Pow(x, y).Rat(b)
+		a.Mul(a, b)
+	}
+	return a
+}
+
+// newArithmeticError creates an error which is located by the caller
+func newArithmeticError(errorType ErrorType, format string, a ...interface{}) error {
+	return &Error{
+		ErrorType: errorType,
+		Message:   fmt.Sprintf(format, a...),
+	}
+}
+
+// add computes a + b and stores the result in a
+func add(a, b *complex.Matrix) error {
+	if err := conform(a, b); err != nil {
+		return err
+	}
+	a.Add(a, b)
+	return nil
+}
+
+// subtract computes a - b and stores the result in a
+func subtract(a, b *complex.Matrix) error {
+	if err := conform(a, b); err != nil {
+		return err
+	}
+	a.Sub(a, b)
+	return nil
+}
+
+// conform checks that two matrices can be added or subtracted
+func conform(a, b *complex.Matrix) error {
+	if isScalar(a) || isScalar(b) {
+		return nil
+	}
+	ar, ac := dimensions(a)
+	br, bc := dimensions(b)
+	if ar != br || ac != bc {
+		return newArithmeticError(ErrorTypeDimension, "%dx%d and %dx%d matrices", ar, ac, br, bc)
+	}
+	return nil
+}
+
+// multiply computes a * b and stores the result in a
+func multiply(a, b *complex.Matrix) error {
+	if !isScalar(a) && !isScalar(b) {
+		ar, ac := dimensions(a)
+		br, bc := dimensions(b)
+		if ac != br {
+			return newArithmeticError(ErrorTypeDimension, "%dx%d and %dx%d matrices", ar, ac, br, bc)
+		}
+	}
+	a.Mul(a, b)
+	return nil
+}
+
+// divide computes a / b and stores the result in a
+func divide(a, b *complex.Matrix) error {
+	if !isScalar(a) || !isScalar(b) {
+		return newArithmeticError(ErrorTypeDimension, "division requires 1x1 matrices")
+	}
+	if isZero(&b.Values[0][0]) {
+		return newArithmeticError(ErrorTypeDivisionByZero, "divisor is zero")
+	}
+	a.Div(a, b)
+	return nil
+}
+
+// modulus computes a % b and stores the result in a
+func modulus(a, b *complex.Matrix) error {
+	if !isScalar(a) || !isScalar(b) {
+		return newArithmeticError(ErrorTypeDimension, "modulus requires 1x1 matrices")
+	}
+	x, y := &a.Values[0][0], &b.Values[0][0]
+	if !isInteger(x) || !isInteger(y) {
+		return newArithmeticError(ErrorTypeNonInteger, "modulus requires integer operands")
+	}
+	if isZero(y) {
+		return newArithmeticError(ErrorTypeDivisionByZero, "modulus is zero")
+	}
+	x.A.Num().Mod(x.A.Num(), y.A.Num())
+	return nil
+}
+
+// power computes the principal value of a ^ b elementwise and stores the
+// result in a, integer powers and rational powers with rational roots are
+// exact. Either the exponent or the base must be a 1x1 matrix.
+func power(a, b *complex.Matrix) error {
+	prec := precisionOf(a)
+	if !isScalar(b) {
+		if !isScalar(a) {
+			return newArithmeticError(ErrorTypeDimension, "exponent must be a 1x1 matrix")
+		}
+		// a number raised to a matrix is computed elementwise
+		x := a.Values[0][0]
+		values := make([][]complex.Rational, len(b.Values))
+		for i, row := range b.Values {
+			values[i] = make([]complex.Rational, len(row))
+			for j := range row {
+				r, err := powerOf(&x, &row[j], prec)
+				if err != nil {
+					return err
+				}
+				values[i][j] = *r
+			}
+		}
+		a.Values = values
+		return nil
+	}
+	y := &b.Values[0][0]
+	for _, row := range a.Values {
+		for j := range row {
+			r, err := powerOf(&row[j], y, prec)
+			if err != nil {
+				return err
+			}
+			row[j] = *r
+		}
+	}
+	return nil
+}
+
+// powerOf computes the principal value of x ^ y
+func powerOf(x, y *complex.Rational, prec uint) (*complex.Rational, error) {
+	switch {
+	case isZero(x) && isZero(y):
+		return complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1)), nil
+	case isZero(x) && y.A.Sign() > 0:
+		return complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1)), nil
+	case isZero(x):
+		return nil, newArithmeticError(ErrorTypeDivisionByZero, "zero raised to a non-positive power")
+	case isInteger(y) && exact(x, y.A.Num(), prec):
+		return integerPower(x, y.A.Num()), nil
+	case y.B.Sign() == 0:
+		// rational powers with rational roots are exact
+		if r, ok := rationalPower(x, y.A, prec); ok {
+			return r, nil
+		}
+	}
+	return toRational(cpow(toFloat(x, prec+guard), toFloat(y, prec+guard), prec)), nil
+}
+
+// exact tests if x^n can be computed exactly within a multiple of the precision
+func exact(x *complex.Rational, n *big.Int, prec uint) bool {
+	if !n.IsInt64() {
+		return false
+	}
+	bits := x.A.Num().BitLen() + x.A.Denom().BitLen() + x.B.Num().BitLen() + x.B.Denom().BitLen()
+	return int64(bits)*abs(n.Int64()) <= 16*int64(prec)
+}
+
+// integerPower computes x^n exactly by squaring
+func integerPower(x *complex.Rational, n *big.Int) *complex.Rational {
+	base := complex.NewRational(new(big.Rat).Set(x.A), new(big.Rat).Set(x.B))
+	if n.Sign() < 0 {
+		// 1/x = conj(x)/|x|^2
+		d := new(big.Rat).Mul(x.A, x.A)
+		d.Add(d, new(big.Rat).Mul(x.B, x.B))
+		base.A.Quo(base.A, d)
+		base.B.Quo(base.B.Neg(base.B), d)
+	}
+	a := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
+	e := new(big.Int).Abs(n)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		a.Mul(a, a)
+		if e.Bit(i) == 1 {
+			a.Mul(a, base)
+		}
+	}
+	return a
+}
+
+// maxRootDegree is the largest denominator of a rational exponent or a
+// logarithm which is computed exactly
+const maxRootDegree = 64
+
+// integerRoot computes the q-th root of the non-negative integer n if it is an
+// integer
+func integerRoot(n *big.Int, q int64) (*big.Int, bool) {
+	if n.Sign() < 0 {
+		return nil, false
+	} else if n.Cmp(big.NewInt(1)) <= 0 || q == 1 {
+		return new(big.Int).Set(n), true
+	}
+	// Newton's method from above, x = ((q - 1) x + n / x^(q - 1)) / q
+	k := big.NewInt(q)
+	x := new(big.Int).Lsh(big.NewInt(1), uint((int64(n.BitLen())+q-1)/q))
+	for {
+		y := new(big.Int).Exp(x, big.NewInt(q-1), nil)
+		y.Quo(n, y)
+		y.Add(y, new(big.Int).Mul(x, big.NewInt(q-1)))
+		y.Quo(y, k)
+		if y.Cmp(x) >= 0 {
+			break
+		}
+		x = y
+	}
+	if new(big.Int).Exp(x, k, nil).Cmp(n) != 0 {
+		return nil, false
+	}
+	return x, true
+}
+
+// rationalRoot computes the q-th root of the non-negative rational x if it is
+// rational
+func rationalRoot(x *big.Rat, q int64) (*big.Rat, bool) {
+	num, ok := integerRoot(x.Num(), q)
+	if !ok {
+		return nil, false
+	}
+	den, ok := integerRoot(x.Denom(), q)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetFrac(num, den), true
+}
+
+// root computes the principal q-th root of x if it is rational, the root of a
+// negative or complex number is only rational for square roots
+func root(x *complex.Rational, q int64) (*complex.Rational, bool) {
+	if x.B.Sign() == 0 && x.A.Sign() >= 0 {
+		a, ok := rationalRoot(x.A, q)
+		if !ok {
+			return nil, false
+		}
+		return complex.NewRational(a, new(big.Rat)), true
+	} else if q != 2 {
+		return nil, false
+	}
+	// sqrt(x) = sqrt((|x| + a)/2) + i sign(b) sqrt((|x| - a)/2)
+	l := new(big.Rat).Mul(x.A, x.A)
+	l, ok := rationalRoot(l.Add(l, new(big.Rat).Mul(x.B, x.B)), 2)
+	if !ok {
+		return nil, false
+	}
+	a := new(big.Rat).Add(l, x.A)
+	a, ok = rationalRoot(a.Quo(a, big.NewRat(2, 1)), 2)
+	if !ok {
+		return nil, false
+	}
+	b := new(big.Rat).Sub(l, x.A)
+	b, ok = rationalRoot(b.Quo(b, big.NewRat(2, 1)), 2)
+	if !ok {
+		return nil, false
+	}
+	if x.B.Sign() < 0 {
+		b.Neg(b)
+	}
+	return complex.NewRational(a, b), true
+}
+
+// rationalPower computes the principal value of x^e exactly if the exponent
+// has a small denominator and the root of x is rational
+func rationalPower(x *complex.Rational, e *big.Rat, prec uint) (*complex.Rational, bool) {
+	if !e.Denom().IsInt64() || e.Denom().Int64() > maxRootDegree || isZero(x) {
+		return nil, false
+	}
+	// x^(p/q) = (x^(1/q))^p on the principal branch
+	r, ok := root(x, e.Denom().Int64())
+	if !ok || !exact(r, e.Num(), prec) {
+		return nil, false
+	}
+	return integerPower(r, e.Num()), true
+}
+
+// rationalLogarithm computes the logarithm of x to the base b exactly if it
+// is a rational with a small denominator, x and b must be positive and b must
+// not be one
+func rationalLogarithm(x, b *big.Rat) (*big.Rat, bool) {
+	if x.Sign() <= 0 || b.Sign() <= 0 || b.Cmp(big.NewRat(1, 1)) == 0 {
+		return nil, false
+	} else if x.Cmp(big.NewRat(1, 1)) == 0 {
+		return new(big.Rat), true
+	}
+	log2 := func(x *big.Rat) float64 {
+		m := new(big.Float).SetRat(x)
+		e := m.MantExp(m)
+		f, _ := m.Float64()
+		return float64(e) + math.Log2(f)
+	}
+	r := log2(x) / log2(b)
+	// log_b x = p/q if x^q = b^p
+	for q := int64(1); q <= maxRootDegree; q++ {
+		p := math.Round(r * float64(q))
+		if math.Abs(r*float64(q)-p) > 1e-6 || math.Abs(p) > maxExponent {
+			continue
+		}
+		a, ok := exponentiate(x, big.NewRat(q, 1))
+		if !ok {
+			return nil, false
+		}
+		c, ok := exponentiate(b, big.NewRat(int64(p), 1))
+		if ok && a.Cmp(c) == 0 {
+			return big.NewRat(int64(p), q), true
+		}
+	}
+	return nil, false
+}
+
+// precisionOf returns the precision of a matrix
+func precisionOf(a *complex.Matrix) uint {
+	if a.Prec == 0 {
+		return DefaultPrec
+	}
+	return a.Prec
+}
+
+// elementwise applies a function of a complex float to the elements of a and
+// stores the result in a
+func elementwise(a *complex.Matrix, function func(x *complex.Float, prec uint) *complex.Float) *complex.Matrix {
+	prec := precisionOf(a)
+	for _, row := range a.Values {
+		for j := range row {
+			row[j] = *toRational(function(toFloat(&row[j], prec+guard), prec))
+		}
+	}
+	return a
+}
+
+// exponential computes e^a elementwise and stores the result in a
+func exponential(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, cexp)
+}
+
+// squareRoot computes the principal square root of a elementwise and stores
+// the result in a, rational roots are exact
+func squareRoot(a *complex.Matrix) *complex.Matrix {
+	prec := precisionOf(a)
+	for _, row := range a.Values {
+		for j := range row {
+			if r, ok := root(&row[j], 2); ok {
+				row[j] = *r
+				continue
+			}
+			row[j] = *toRational(csqrt(toFloat(&row[j], prec+guard), prec))
+		}
+	}
+	return a
+}
+
+// cosine computes the cosine of a elementwise and stores the result in a
+func cosine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, ccos)
+}
+
+// sine computes the sine of a elementwise and stores the result in a
+func sine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, csin)
+}
+
+// tangent computes the tangent of a elementwise and stores the result in a
+func tangent(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, ctan)
+}
+
+// logarithm computes the principal natural logarithm of a elementwise and
+// stores the result in a
+func logarithm(a *complex.Matrix) error {
+	for _, row := range a.Values {
+		for i := range row {
+			if isZero(&row[i]) {
+				return newArithmeticError(ErrorTypeDomain, "logarithm of zero")
+			}
+		}
+	}
+	elementwise(a, clog)
+	return nil
+}
+
+// logarithmBase computes the principal logarithm of a to the base b
+// elementwise and stores the result in a, the rational logarithms of
+// positive rationals are exact
+func logarithmBase(a, b *complex.Matrix) error {
+	if !isScalar(b) {
+		return newArithmeticError(ErrorTypeDimension, "base must be a 1x1 matrix")
+	}
+	base, prec := &b.Values[0][0], precisionOf(a)
+	if isZero(base) {
+		return newArithmeticError(ErrorTypeDomain, "logarithm to the base zero")
+	} else if base.B.Sign() == 0 && base.A.Cmp(big.NewRat(1, 1)) == 0 {
+		return newArithmeticError(ErrorTypeDomain, "logarithm to the base one")
+	}
+	for _, row := range a.Values {
+		for i := range row {
+			if isZero(&row[i]) {
+				return newArithmeticError(ErrorTypeDomain, "logarithm of zero")
+			}
+		}
+	}
+	d := clog(toFloat(base, prec+guard), prec+guard)
+	for _, row := range a.Values {
+		for j := range row {
+			x := &row[j]
+			if x.B.Sign() == 0 && base.B.Sign() == 0 {
+				if r, ok := rationalLogarithm(x.A, base.A); ok {
+					row[j] = *complex.NewRational(r, new(big.Rat))
+					continue
+				}
+			}
+			row[j] = *toRational(cquo(clog(toFloat(x, prec+guard), prec+guard), d, prec))
+		}
+	}
+	return nil
+}
+
+// singular checks that none of the elements of a are points where a function
+// is undefined
+func singular(a *complex.Matrix, message string, points ...*complex.Rational) error {
+	for _, row := range a.Values {
+		for i := range row {
+			for _, point := range points {
+				if row[i].A.Cmp(point.A) == 0 && row[i].B.Cmp(point.B) == 0 {
+					return newArithmeticError(ErrorTypeDomain, message)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hyperbolicSine computes the hyperbolic sine of a elementwise and stores the
+// result in a
+func hyperbolicSine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, csinh)
+}
+
+// hyperbolicCosine computes the hyperbolic cosine of a elementwise and stores
+// the result in a
+func hyperbolicCosine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, ccosh)
+}
+
+// hyperbolicTangent computes the hyperbolic tangent of a elementwise and
+// stores the result in a
+func hyperbolicTangent(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, ctanh)
+}
+
+// secant computes the secant of a elementwise and stores the result in a
+func secant(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, csec)
+}
+
+// cosecant computes the cosecant of a elementwise and stores the result in a
+func cosecant(a *complex.Matrix) error {
+	zero := complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1))
+	if err := singular(a, "cosecant of zero", zero); err != nil {
+		return err
+	}
+	elementwise(a, ccsc)
+	return nil
+}
+
+// cotangent computes the cotangent of a elementwise and stores the result in a
+func cotangent(a *complex.Matrix) error {
+	zero := complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1))
+	if err := singular(a, "cotangent of zero", zero); err != nil {
+		return err
+	}
+	elementwise(a, ccot)
+	return nil
+}
+
+// arcsine computes the principal inverse sine of a elementwise and stores the
+// result in a
+func arcsine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, casin)
+}
+
+// arccosine computes the principal inverse cosine of a elementwise and stores
+// the result in a
+func arccosine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, cacos)
+}
+
+// arctangent computes the principal inverse tangent of a elementwise and
+// stores the result in a
+func arctangent(a *complex.Matrix) error {
+	i := complex.NewRational(big.NewRat(0, 1), big.NewRat(1, 1))
+	j := complex.NewRational(big.NewRat(0, 1), big.NewRat(-1, 1))
+	if err := singular(a, "inverse tangent of i or -i", i, j); err != nil {
+		return err
+	}
+	elementwise(a, catan)
+	return nil
+}
+
+// arctangent2 computes the angle of the points with the y coordinates in a
+// and the x coordinates in b elementwise and stores the result in a
+func arctangent2(a, b *complex.Matrix) error {
+	ar, ac := dimensions(a)
+	br, bc := dimensions(b)
+	if ar != br || ac != bc {
+		return newArithmeticError(ErrorTypeDimension, "%dx%d and %dx%d matrices", ar, ac, br, bc)
+	}
+	prec := precisionOf(a)
+	for i, row := range a.Values {
+		for j := range row {
+			y, x := &row[j], &b.Values[i][j]
+			if y.B.Sign() != 0 || x.B.Sign() != 0 {
+				return newArithmeticError(ErrorTypeDomain, "atan2 requires real operands")
+			}
+			angle := atan2Float(newFloat(prec+guard).SetRat(y.A), newFloat(prec+guard).SetRat(x.A), prec)
+			angle.Rat(y.A)
+		}
+	}
+	return nil
+}
+
+// hyperbolicArcsine computes the principal inverse hyperbolic sine of a
+// elementwise and stores the result in a
+func hyperbolicArcsine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, casinh)
+}
+
+// hyperbolicArccosine computes the principal inverse hyperbolic cosine of a
+// elementwise and stores the result in a
+func hyperbolicArccosine(a *complex.Matrix) *complex.Matrix {
+	return elementwise(a, cacosh)
+}
+
+// hyperbolicArctangent computes the principal inverse hyperbolic tangent of a
+// elementwise and stores the result in a
+func hyperbolicArctangent(a *complex.Matrix) error {
+	one := complex.NewRational(big.NewRat(1, 1), big.NewRat(0, 1))
+	minus := complex.NewRational(big.NewRat(-1, 1), big.NewRat(0, 1))
+	if err := singular(a, "inverse hyperbolic tangent of 1 or -1", one, minus); err != nil {
+		return err
+	}
+	elementwise(a, catanh)
+	return nil
+}
+
+// accurate converts x to a float with enough bits that the distance of its
+// real part to the nearest integer, where the special functions have their
+// poles and zeros, has precision prec
+func accurate(x *complex.Rational, prec uint) *complex.Float {
+	wp := prec + guard
+	if !x.A.IsInt() {
+		a := new(big.Float).SetRat(x.A)
+		d := new(big.Rat).Sub(x.A, new(big.Rat).SetInt(nearest(a)))
+		wp += positive(-exponent(new(big.Float).SetRat(d))) + positive(exponent(a))
+	}
+	return toFloat(x, wp)
+}
+
+// special applies a special function to the elements of a and stores the
+// result in a
+func special(a *complex.Matrix, function func(x *complex.Rational, prec uint) (*complex.Rational, error)) error {
+	prec := precisionOf(a)
+	for _, row := range a.Values {
+		for j := range row {
+			x, err := function(&row[j], prec)
+			if err != nil {
+				return err
+			}
+			row[j] = *x
+		}
+	}
+	return nil
+}
+
+// bounded tests if the parts of x have magnitudes of at most limit
+func bounded(x *complex.Rational, limit int64) bool {
+	r := big.NewRat(limit, 1)
+	return new(big.Rat).Abs(x.A).Cmp(r) <= 0 && new(big.Rat).Abs(x.B).Cmp(r) <= 0
+}
+
+// gammaOf computes the gamma function of x
+func gammaOf(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	if nonPositive(x) {
+		return nil, newArithmeticError(ErrorTypeDomain, "gamma of a non-positive integer")
+	} else if r, ok := exactGamma(x); ok {
+		return r, nil
+	} else if !bounded(x, maxGamma) {
+		return nil, newArithmeticError(ErrorTypeDomain, "gamma argument is out of range")
+	}
+	return toRational(cgamma(accurate(x, prec), prec)), nil
+}
+
+// factorialOf computes the factorial of x, which is Γ(x + 1)
+func factorialOf(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	if x.B.Sign() == 0 && x.A.IsInt() && x.A.Sign() < 0 {
+		return nil, newArithmeticError(ErrorTypeDomain, "factorial of a negative integer")
+	}
+	return gammaOf(complex.NewRational(new(big.Rat).Add(x.A, big.NewRat(1, 1)), x.B), prec)
+}
+
+// logGammaOf computes the principal logarithm of the gamma function of x
+func logGammaOf(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	if nonPositive(x) {
+		return nil, newArithmeticError(ErrorTypeDomain, "lgamma of a non-positive integer")
+	} else if r, ok := exactValue(OperationLogGamma, x); ok {
+		return r, nil
+	} else if x.A.Cmp(big.NewRat(-maxShift, 1)) < 0 {
+		return nil, newArithmeticError(ErrorTypeDomain, "lgamma argument is out of range")
+	}
+	return toRational(clgamma(accurate(x, prec), prec)), nil
+}
+
+// polygammaOf returns the polygamma function of order m
+func polygammaOf(m int) func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	return func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if nonPositive(x) {
+			return nil, newArithmeticError(ErrorTypeDomain, "polygamma of a non-positive integer")
+		} else if m > 0 && x.A.Cmp(big.NewRat(-maxShift, 1)) < 0 {
+			return nil, newArithmeticError(ErrorTypeDomain, "polygamma argument is out of range")
+		}
+		return toRational(cpolygamma(m, accurate(x, prec), prec)), nil
+	}
+}
+
+// errorFunctionOf returns the error function or the complementary error
+// function
+func errorFunctionOf(operation Operation) func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	return func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if r, ok := exactValue(operation, x); ok {
+			return r, nil
+		}
+		// e^(-x^2) overflows if the imaginary part of x is much larger than
+		// the real part
+		a, b := new(big.Rat).Mul(x.A, x.A), new(big.Rat).Mul(x.B, x.B)
+		if b.Sub(b, a).Cmp(big.NewRat(1<<30, 1)) > 0 {
+			return nil, newArithmeticError(ErrorTypeDomain, "%s argument is out of range", functionNames[operation])
+		}
+		z := toFloat(x, prec+guard)
+		if operation == OperationErfc {
+			return toRational(cerfc(z, prec)), nil
+		}
+		return toRational(cerf(z, prec)), nil
+	}
+}
+
+// zetaOf returns the derivative of order m of the Riemann zeta function
+func zetaOf(m int) func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	return func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if x.B.Sign() == 0 && x.A.Cmp(big.NewRat(1, 1)) == 0 {
+			return nil, newArithmeticError(ErrorTypeDomain, "zeta of one")
+		} else if m == 0 {
+			if r, ok := exactZeta(x); ok {
+				return r, nil
+			}
+		}
+		if !bounded(x, maxShift) {
+			return nil, newArithmeticError(ErrorTypeDomain, "zeta argument is out of range")
+		}
+		return toRational(czeta(m, accurate(x, prec), prec)), nil
+	}
+}
+
+// orderOf converts b into the order of a derivative of a special function
+func orderOf(b *complex.Matrix) (int, error) {
+	if !isScalar(b) {
+		return 0, newArithmeticError(ErrorTypeDimension, "order must be a 1x1 matrix")
+	}
+	x := &b.Values[0][0]
+	if !isInteger(x) || x.A.Sign() < 0 || x.A.Num().Cmp(big.NewInt(maxExponent)) > 0 {
+		return 0, newArithmeticError(ErrorTypeDomain, "order must be an integer from 0 to %d", maxExponent)
+	}
+	return int(x.A.Num().Int64()), nil
+}
+
+// factorialFunction computes the factorial of a elementwise and stores the
+// result in a
+func factorialFunction(a *complex.Matrix) error {
+	return special(a, factorialOf)
+}
+
+// gammaFunction computes the gamma function of a elementwise and stores the
+// result in a
+func gammaFunction(a *complex.Matrix) error {
+	return special(a, gammaOf)
+}
+
+// logGammaFunction computes the principal logarithm of the gamma function of
+// a elementwise and stores the result in a
+func logGammaFunction(a *complex.Matrix) error {
+	return special(a, logGammaOf)
+}
+
+// betaFunction computes the beta function of a and b elementwise and stores
+// the result in a
+func betaFunction(a, b *complex.Matrix) error {
+	ar, ac := dimensions(a)
+	br, bc := dimensions(b)
+	if ar != br || ac != bc {
+		return newArithmeticError(ErrorTypeDimension, "%dx%d and %dx%d matrices", ar, ac, br, bc)
+	}
+	prec := precisionOf(a)
+	for i, row := range a.Values {
+		for j := range row {
+			x, y := &row[j], &b.Values[i][j]
+			if r, ok := exactBeta(x, y); ok {
+				row[j] = *r
+				continue
+			} else if nonPositive(x) || nonPositive(y) {
+				return newArithmeticError(ErrorTypeDomain, "beta of a non-positive integer")
+			} else if !bounded(x, maxGamma/2) || !bounded(y, maxGamma/2) {
+				return newArithmeticError(ErrorTypeDomain, "beta argument is out of range")
+			}
+			// B(x, y) = Γ(x) Γ(y) / Γ(x + y)
+			s := complex.NewRational(new(big.Rat).Add(x.A, y.A), new(big.Rat).Add(x.B, y.B))
+			wp := prec + guard
+			d := cmul(cgamma(accurate(x, wp), wp), cgamma(accurate(y, wp), wp), wp)
+			row[j] = *toRational(cquo(d, cgamma(accurate(s, wp), wp), prec))
+		}
+	}
+	return nil
+}
+
+// errorFunction computes the error function of a elementwise and stores the
+// result in a
+func errorFunction(a *complex.Matrix) error {
+	return special(a, errorFunctionOf(OperationErf))
+}
+
+// complementaryErrorFunction computes the complementary error function of a
+// elementwise and stores the result in a
+func complementaryErrorFunction(a *complex.Matrix) error {
+	return special(a, errorFunctionOf(OperationErfc))
+}
+
+// zetaFunction computes the Riemann zeta function of a elementwise and stores
+// the result in a
+func zetaFunction(a *complex.Matrix) error {
+	return special(a, zetaOf(0))
+}
+
+// zetaDerivative computes the derivative of the Riemann zeta function of a
+// with the order b elementwise and stores the result in a
+func zetaDerivative(a, b *complex.Matrix) error {
+	m, err := orderOf(b)
+	if err != nil {
+		return err
+	}
+	return special(a, zetaOf(m))
+}
+
+// digammaFunction computes the digamma function of a elementwise and stores
+// the result in a
+func digammaFunction(a *complex.Matrix) error {
+	return special(a, polygammaOf(0))
+}
+
+// polygammaFunction computes the polygamma function of a with the order b
+// elementwise and stores the result in a
+func polygammaFunction(a, b *complex.Matrix) error {
+	m, err := orderOf(b)
+	if err != nil {
+		return err
+	}
+	return special(a, polygammaOf(m))
+}
+
+// lambertOf returns the branch k of the Lambert W function
+func lambertOf(k int) func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	return func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if isZero(x) {
+			if k != 0 {
+				return nil, newArithmeticError(ErrorTypeDomain, "lambertw of zero")
+			}
+			return realRational(new(big.Rat)), nil
+		}
+		return toRational(clambertw(toFloat(x, prec+guard), k, prec)), nil
+	}
+}
+
+// branchOf converts b into the branch of the Lambert W function
+func branchOf(b *complex.Matrix) (int, error) {
+	if !isScalar(b) {
+		return 0, newArithmeticError(ErrorTypeDimension, "branch must be a 1x1 matrix")
+	}
+	x := &b.Values[0][0]
+	if !isInteger(x) || x.A.Num().CmpAbs(big.NewInt(maxExponent)) > 0 {
+		return 0, newArithmeticError(ErrorTypeDomain, "branch must be an integer from %d to %d", -maxExponent, maxExponent)
+	}
+	return int(x.A.Num().Int64()), nil
+}
+
+// lambertFunction computes the branch b of the Lambert W function of a
+// elementwise and stores the result in a
+func lambertFunction(a, b *complex.Matrix) error {
+	k, err := branchOf(b)
+	if err != nil {
+		return err
+	}
+	return special(a, lambertOf(k))
+}
+
+// besselOf returns the Bessel function of the operation of order nu
+func besselOf(operation Operation, nu *complex.Rational) func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+	return func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if r, ok := exactBessel(operation, nu, x); ok {
+			return r, nil
+		} else if isZero(x) {
+			return nil, newArithmeticError(ErrorTypeDomain, "%s of zero", functionNames[operation])
+		} else if !bounded(x, maxGamma) {
+			return nil, newArithmeticError(ErrorTypeDomain, "%s argument is out of range", functionNames[operation])
+		}
+		order, z := accurate(nu, prec), toFloat(x, prec+guard)
+		if !bounded(x, maxBessel) && !besselLarge(order, z, prec) {
+			return nil, newArithmeticError(ErrorTypeDomain, "%s argument is out of range", functionNames[operation])
+		}
+		return toRational(cbessel(operation, order, z, prec)), nil
+	}
+}
+
+// besselFunction returns the function which computes the Bessel function of
+// the operation of a with the order b elementwise and stores the result in a
+func besselFunction(operation Operation) func(a, b *complex.Matrix) error {
+	return func(a, b *complex.Matrix) error {
+		if !isScalar(b) {
+			return newArithmeticError(ErrorTypeDimension, "order must be a 1x1 matrix")
+		}
+		nu := &b.Values[0][0]
+		if !bounded(nu, maxBessel) {
+			return newArithmeticError(ErrorTypeDomain, "order must have parts from %d to %d", -maxBessel, maxBessel)
+		}
+		return special(a, besselOf(operation, nu))
+	}
+}
+
+// airyFunction returns the function which computes the Airy function of the
+// operation of a elementwise and stores the result in a
+func airyFunction(operation Operation) func(a *complex.Matrix) error {
+	return func(a *complex.Matrix) error {
+		return special(a, func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+			if !bounded(x, maxAiry) {
+				return nil, newArithmeticError(ErrorTypeDomain, "%s argument is out of range", functionNames[operation])
+			}
+			return toRational(cairy(operation, toFloat(x, prec+guard), prec)), nil
+		})
+	}
+}
+
+// ellipticFunction computes the complete elliptic integral of the first kind
+// of the parameter a elementwise and stores the result in a
+func ellipticFunction(a *complex.Matrix) error {
+	return special(a, func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if n, ok := integerOf(x); ok && n.Cmp(big.NewInt(1)) == 0 {
+			return nil, newArithmeticError(ErrorTypeDomain, "ellipk of one")
+		}
+		return toRational(cellipticK(toFloat(x, prec+guard), prec)), nil
+	})
+}
+
+// ellipticSecondFunction computes the complete elliptic integral of the second
+// kind of the parameter a elementwise and stores the result in a
+func ellipticSecondFunction(a *complex.Matrix) error {
+	return special(a, func(x *complex.Rational, prec uint) (*complex.Rational, error) {
+		if r, ok := exactValue(OperationEllipticE, x); ok {
+			return r, nil
+		}
+		return toRational(cellipticE(toFloat(x, prec+guard), prec)), nil
+	})
+}
+
+// total adapts a function which is defined for every element
+func total(f func(a *complex.Matrix) *complex.Matrix) func(a *complex.Matrix) error {
+	return func(a *complex.Matrix) error {
+		f(a)
+		return nil
+	}
+}
+
+// functionMatrices are the functions of the operations in functionNames with
+// one argument, which store the result in the argument
+var functionMatrices = map[Operation]func(a *complex.Matrix) error{
+	OperationArcsine:              total(arcsine),
+	OperationArccosine:            total(arccosine),
+	OperationArctangent:           arctangent,
+	OperationHyperbolicSine:       total(hyperbolicSine),
+	OperationHyperbolicCosine:     total(hyperbolicCosine),
+	OperationHyperbolicTangent:    total(hyperbolicTangent),
+	OperationHyperbolicArcsine:    total(hyperbolicArcsine),
+	OperationHyperbolicArccosine:  total(hyperbolicArccosine),
+	OperationHyperbolicArctangent: hyperbolicArctangent,
+	OperationSecant:               total(secant),
+	OperationCosecant:             cosecant,
+	OperationCotangent:            cotangent,
+	OperationGamma:                gammaFunction,
+	OperationLogGamma:             logGammaFunction,
+	OperationErf:                  errorFunction,
+	OperationErfc:                 complementaryErrorFunction,
+	OperationZeta:                 zetaFunction,
+	OperationDigamma:              digammaFunction,
+	OperationAiryAi:               airyFunction(OperationAiryAi),
+	OperationAiryBi:               airyFunction(OperationAiryBi),
+	OperationAiryAiPrime:          airyFunction(OperationAiryAiPrime),
+	OperationAiryBiPrime:          airyFunction(OperationAiryBiPrime),
+	OperationEllipticK:            ellipticFunction,
+	OperationEllipticE:            ellipticSecondFunction,
+	OperationIsPrime:              integerElementwise(OperationIsPrime),
+	OperationNextPrime:            integerElementwise(OperationNextPrime),
+	OperationTotient:              integerElementwise(OperationTotient),
+	OperationFactor:               factorFunction,
+}
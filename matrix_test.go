@@ -0,0 +1,82 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestLogarithms(t *testing.T) {
+	run(t, []test{
+		{"log(8, 2)", "3"},
+		{"log(1/9, 3)", "-2"},
+		{"log(5, 2)", "2.321928095"},
+		{"log(-1)", "0 + 3.141592654i"},
+		{"log2(1024)", "10"},
+		{"log10(1000)", "3"},
+		{"log10(1/1000)", "-3"},
+		{"exp2(10)", "1024"},
+		{"exp2(1/2)", "1.414213562"},
+		{"derivative(log(x, 2))", "1 / (x * log(2))"},
+		{"derivative(log2(x))", "1 / (x * log(2))"},
+		{"derivative(exp2(x))", "2^x * log(2)"},
+	})
+	runZeros(t, []string{
+		"log(5, 2) - log(5)/log(2)",
+		"log10(7) - log(7)/log(10)",
+		"exp2(1/3) - 2^(1/3)",
+		"log(1i, 2) - 1i*pi/(2*log(2))",
+		"log2(exp2(3/7)) - 3/7",
+	}, 1000)
+	runErrors(t, []errorTest{
+		{"log(0)", ErrorTypeDomain, 0, 6},
+		{"log(8, 1)", ErrorTypeDomain, 0, 9},
+		{"log(8, 0)", ErrorTypeDomain, 0, 9},
+	})
+}
+
+func TestPowers(t *testing.T) {
+	// the rational powers which have rational values are exact
+	exact := []struct {
+		expression string
+		a, b       *big.Rat
+	}{
+		{"8^(1/3)", big.NewRat(2, 1), new(big.Rat)},
+		{"4^(3/2)", big.NewRat(8, 1), new(big.Rat)},
+		{"(27/8)^(2/3)", big.NewRat(9, 4), new(big.Rat)},
+		{"32^(-2/5)", big.NewRat(1, 4), new(big.Rat)},
+		{"(-1)^(1/2)", new(big.Rat), big.NewRat(1, 1)},
+		{"(-4)^(3/2)", new(big.Rat), big.NewRat(-8, 1)},
+		{"0^0", big.NewRat(1, 1), new(big.Rat)},
+	}
+	for _, test := range exact {
+		value, err := evaluate(NewEnvironment(), test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		x := value.Matrix.Values[0][0]
+		if x.A.Cmp(test.a) != 0 || x.B.Cmp(test.b) != 0 {
+			t.Errorf("%s = %s + %si, want %s + %si", test.expression,
+				x.A.RatString(), x.B.RatString(), test.a.RatString(), test.b.RatString())
+		}
+	}
+	run(t, []test{
+		{"(-8)^(1/3)", "1 + 1.732050808i"},
+		{"2^(1 + 1i)", "1.538477803 + 1.277922553i"},
+		{"1i^1i", "0.2078795764"},
+	})
+	runZeros(t, []string{
+		"2^(1/2) - sqrt(2)",
+		"(-8)^(1/3) - 2*exp(1i*pi/3)",
+		"1i^1i - exp(-pi/2)",
+		"2^(1 + 1i) - 2*exp(1i*log(2))",
+	}, 1000)
+	runErrors(t, []errorTest{
+		{"0^(-1)", ErrorTypeDivisionByZero, 0, 6},
+		{"[1 2; 3 4]^[1 2]", ErrorTypeDimension, 0, 0},
+	})
+}
@@ -0,0 +1,431 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// glyphs are the characters used to draw an expression
+type glyphs struct {
+	times, bar, integral            string
+	root, rootSide, rootBar         string
+	open, close                     [3]string
+	openBracket, closeBracket       [3]string
+	openSingle, closeSingle         string
+	openBracketOne, closeBracketOne string
+}
+
+var (
+	// unicodeGlyphs draw with box drawing characters
+	unicodeGlyphs = &glyphs{
+		times:           "⋅",
+		bar:             "─",
+		integral:        "∫",
+		root:            "√",
+		rootSide:        "│",
+		rootBar:         "_",
+		open:            [3]string{"⎛", "⎜", "⎝"},
+		close:           [3]string{"⎞", "⎟", "⎠"},
+		openBracket:     [3]string{"⎡", "⎢", "⎣"},
+		closeBracket:    [3]string{"⎤", "⎥", "⎦"},
+		openSingle:      "(",
+		closeSingle:     ")",
+		openBracketOne:  "[",
+		closeBracketOne: "]",
+	}
+	// asciiGlyphs draw with ASCII characters
+	asciiGlyphs = &glyphs{
+		times:           "*",
+		bar:             "-",
+		integral:        "integral",
+		root:            "\\|",
+		rootSide:        " |",
+		rootBar:         "_",
+		open:            [3]string{"/", "|", "\\"},
+		close:           [3]string{"\\", "|", "/"},
+		openBracket:     [3]string{"[", "[", "["},
+		closeBracket:    [3]string{"]", "]", "]"},
+		openSingle:      "(",
+		closeSingle:     ")",
+		openBracketOne:  "[",
+		closeBracketOne: "]",
+	}
+)
+
+// box is a rectangle of text, the baseline is the line which lines up with
+// the text around the box
+type box struct {
+	lines    []string
+	width    int
+	baseline int
+}
+
+// newBox creates a box from lines of text, the lines are padded to the
+// same width
+func newBox(baseline int, lines ...string) *box {
+	width := 0
+	for _, line := range lines {
+		if w := utf8.RuneCountInString(line); w > width {
+			width = w
+		}
+	}
+	padded := make([]string, len(lines))
+	for i, line := range lines {
+		padded[i] = line + strings.Repeat(" ", width-utf8.RuneCountInString(line))
+	}
+	return &box{
+		lines:    padded,
+		width:    width,
+		baseline: baseline,
+	}
+}
+
+// text is a box of a single line of text
+func text(s string) *box {
+	return newBox(0, s)
+}
+
+// height is the number of lines of the box
+func (b *box) height() int {
+	return len(b.lines)
+}
+
+// below is the number of lines below the baseline
+func (b *box) below() int {
+	return len(b.lines) - b.baseline - 1
+}
+
+// String returns the lines of the box without trailing spaces
+func (b *box) String() string {
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// beside places the boxes from left to right lining up their baselines
+func beside(boxes ...*box) *box {
+	above, below := 0, 0
+	for _, b := range boxes {
+		if b.baseline > above {
+			above = b.baseline
+		}
+		if b.below() > below {
+			below = b.below()
+		}
+	}
+	lines := make([]string, above+below+1)
+	for _, b := range boxes {
+		blank := strings.Repeat(" ", b.width)
+		top := above - b.baseline
+		for i := range lines {
+			if j := i - top; j >= 0 && j < b.height() {
+				lines[i] += b.lines[j]
+			} else {
+				lines[i] += blank
+			}
+		}
+	}
+	return newBox(above, lines...)
+}
+
+// center pads the line on both sides to the width
+func center(line string, width int) string {
+	left := (width - utf8.RuneCountInString(line)) / 2
+	return strings.Repeat(" ", left) + line
+}
+
+// stack places the boxes from top to bottom centered horizontally
+func stack(baseline int, boxes ...*box) *box {
+	width := 0
+	for _, b := range boxes {
+		if b.width > width {
+			width = b.width
+		}
+	}
+	var lines []string
+	for _, b := range boxes {
+		for _, line := range b.lines {
+			lines = append(lines, center(line, width))
+		}
+	}
+	return newBox(baseline, lines...)
+}
+
+// fraction places the numerator over the denominator
+func (g *glyphs) fraction(numerator, denominator *box) *box {
+	width := numerator.width
+	if denominator.width > width {
+		width = denominator.width
+	}
+	bar := text(strings.Repeat(g.bar, width+2))
+	return stack(numerator.height(), numerator, bar, denominator)
+}
+
+// superscript places the exponent to the upper right of the base
+func superscript(base, exponent *box) *box {
+	lines := make([]string, 0, exponent.height()+base.height())
+	for _, line := range exponent.lines {
+		lines = append(lines, strings.Repeat(" ", base.width)+line)
+	}
+	lines = append(lines, base.lines...)
+	return newBox(exponent.height()+base.baseline, lines...)
+}
+
+// side is a column of delimiter characters of the height
+func side(height int, single string, parts [3]string) *box {
+	if height == 1 {
+		return text(single)
+	}
+	lines := make([]string, height)
+	for i := range lines {
+		switch i {
+		case 0:
+			lines[i] = parts[0]
+		case height - 1:
+			lines[i] = parts[2]
+		default:
+			lines[i] = parts[1]
+		}
+	}
+	return newBox(0, lines...)
+}
+
+// parentheses surrounds the box with parentheses of the same height
+func (g *glyphs) parentheses(b *box) *box {
+	left, right := side(b.height(), g.openSingle, g.open), side(b.height(), g.closeSingle, g.close)
+	left.baseline, right.baseline = b.baseline, b.baseline
+	return beside(left, b, right)
+}
+
+// brackets surrounds the box with square brackets of the same height
+func (g *glyphs) brackets(b *box) *box {
+	left, right := side(b.height(), g.openBracketOne, g.openBracket), side(b.height(), g.closeBracketOne, g.closeBracket)
+	left.baseline, right.baseline = b.baseline, b.baseline
+	return beside(left, b, right)
+}
+
+// radical draws a radical sign over the box
+func (g *glyphs) radical(b *box) *box {
+	indent := utf8.RuneCountInString(g.root)
+	lines := []string{strings.Repeat(" ", indent) + strings.Repeat(g.rootBar, b.width)}
+	for i, line := range b.lines {
+		if i == b.height()-1 {
+			lines = append(lines, g.root+line)
+		} else {
+			lines = append(lines, g.rootSide+line)
+		}
+	}
+	return newBox(b.baseline+1, lines...)
+}
+
+// matrix lines up the elements in columns surrounded by brackets
+func (g *glyphs) matrix(rows [][]*box) *box {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for j, element := range row {
+			if element.width > widths[j] {
+				widths[j] = element.width
+			}
+		}
+	}
+	tall := false
+	for _, row := range rows {
+		for _, element := range row {
+			tall = tall || element.height() > 1
+		}
+	}
+	var lines []string
+	for i, row := range rows {
+		if tall && i > 0 {
+			// separate the rows of tall elements
+			lines = append(lines, "")
+		}
+		cells := make([]*box, 0, 2*len(row))
+		for j, element := range row {
+			if j > 0 {
+				cells = append(cells, text("  "))
+			}
+			left := (widths[j] - element.width) / 2
+			padded := make([]string, element.height())
+			for k, line := range element.lines {
+				padded[k] = strings.Repeat(" ", left) + line +
+					strings.Repeat(" ", widths[j]-element.width-left)
+			}
+			cells = append(cells, newBox(element.baseline, padded...))
+		}
+		lines = append(lines, beside(cells...).lines...)
+	}
+	return g.brackets(newBox((len(lines)-1)/2, lines...))
+}
+
+// layout lays out the expression in two dimensions
+func (n *Node) layout(g *glyphs) *box {
+	var process func(n *Node) *box
+	// operand parenthesizes the operand if it binds looser than the minimum
+	operand := func(n *Node, minimum int) *box {
+		b := process(n)
+		if n.precedence() < minimum {
+			return g.parentheses(b)
+		}
+		return b
+	}
+	binary := func(n *Node, operator string, precedence int) *box {
+		return beside(operand(n.Left, precedence), text(operator), operand(n.Right, precedence+1))
+	}
+	function := func(name string, arguments ...*Node) *box {
+		boxes := []*box{}
+		for i, argument := range arguments {
+			if i > 0 {
+				boxes = append(boxes, text(", "))
+			}
+			boxes = append(boxes, process(argument))
+		}
+		if len(boxes) == 0 {
+			boxes = append(boxes, text(""))
+		}
+		return beside(text(name), g.parentheses(beside(boxes...)))
+	}
+	process = func(n *Node) *box {
+		if n == nil {
+			return text("")
+		}
+		switch n.Operation {
+		case OperationAdd:
+			if n.Right.Operation == OperationNegate {
+				// a + -b is drawn as a - b
+				return beside(operand(n.Left, precedenceSum), text(" - "), operand(n.Right.Left, precedenceProduct))
+			}
+			return binary(n, " + ", precedenceSum)
+		case OperationSubtract:
+			return binary(n, " - ", precedenceSum)
+		case OperationMultiply:
+			return binary(n, " "+g.times+" ", precedenceProduct)
+		case OperationDivide:
+			return g.fraction(process(n.Left), process(n.Right))
+		case OperationModulus:
+			return binary(n, " % ", precedenceProduct)
+		case OperationExponentiation:
+			base := process(n.Left)
+			if n.Left.precedence() < precedenceValue || n.Left.Operation == OperationDivide ||
+				n.Left.Operation == OperationImaginary || n.Left.Operation == OperationNotation {
+				base = g.parentheses(base)
+			}
+			return superscript(base, process(n.Right))
+		case OperationNegate:
+			// a raised exponent binds tighter than the minus sign
+			if n.Left.precedence() == precedenceNegation {
+				return beside(text("-"), g.parentheses(process(n.Left)))
+			}
+			return beside(text("-"), operand(n.Left, precedencePower))
+		case OperationVariable, OperationNumber:
+			return text(n.Value)
+		case OperationImaginary:
+			return text(n.Value + "i")
+		case OperationNotation:
+			return text(n.String())
+		case OperationNaturalExponentiation:
+			return superscript(text("e"), process(n.Left))
+		case OperationNatural:
+			return text("e")
+		case OperationPI:
+			if g == unicodeGlyphs {
+				return text("π")
+			}
+			return text("pi")
+		case OperationInfinity:
+			if g == unicodeGlyphs {
+				return text("∞")
+			}
+			return text("inf")
+		case OperationNaturalLogarithm:
+			return function("log", n.Left)
+		case OperationSquareRoot:
+			return g.radical(process(n.Left))
+		case OperationCosine:
+			return function("cos", n.Left)
+		case OperationSine:
+			return function("sin", n.Left)
+		case OperationTangent:
+			return function("tan", n.Left)
+		case OperationArcsine, OperationArccosine, OperationArctangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationSecant, OperationCosecant, OperationCotangent,
+			OperationGamma, OperationLogGamma, OperationErf, OperationErfc,
+			OperationZeta, OperationDigamma,
+			OperationAiryAi, OperationAiryBi, OperationAiryAiPrime, OperationAiryBiPrime,
+			OperationEllipticK, OperationEllipticE,
+			OperationIsPrime, OperationNextPrime, OperationFactor, OperationTotient:
+			return function(functionNames[n.Operation], n.Left)
+		case OperationArctangent2:
+			return function("atan2", n.Left, n.Right)
+		case OperationBeta:
+			return function("beta", n.Left, n.Right)
+		case OperationGCD, OperationLCM, OperationInvMod, OperationCRT, OperationJacobi:
+			return function(functionNames[n.Operation], n.Left, n.Right)
+		case OperationPowMod:
+			return function("powmod", n.Arguments...)
+		case OperationZetaDerivative, OperationPolygamma,
+			OperationBesselJ, OperationBesselY, OperationBesselI, OperationBesselK:
+			// the order is the first argument
+			return function(functionNames[n.Operation], n.Right, n.Left)
+		case OperationLambertW:
+			// the principal branch is the default
+			if n.Right.Operation == OperationNumber && n.Right.Value == "0" {
+				return function("lambertw", n.Left)
+			}
+			return function("lambertw", n.Left, n.Right)
+		case OperationFactorial:
+			// y!! would read as the double factorial
+			return beside(operand(n.Left, precedenceValue), text("!"))
+		case OperationLogarithm:
+			// the base is a subscript
+			base := process(n.Right)
+			lines := []string{"log" + strings.Repeat(" ", base.width)}
+			for _, line := range base.lines {
+				lines = append(lines, "   "+line)
+			}
+			log := newBox(0, lines...)
+			argument := g.parentheses(process(n.Left))
+			return beside(log, argument)
+		case OperationCall:
+			return function(n.Value, n.Arguments...)
+		case OperationMatrix:
+			rows := make([][]*box, len(n.Rows))
+			for i, row := range n.Rows {
+				rows[i] = make([]*box, len(row))
+				for j, element := range row {
+					rows[i][j] = process(element)
+				}
+			}
+			return g.matrix(rows)
+		case OperationIntegral:
+			return beside(text(g.integral+" "), operand(n.Left, precedenceProduct), text(" d"), process(n.Right))
+		}
+		return text(n.String())
+	}
+	return process(n)
+}
+
+// Pretty draws the expression in two dimensions with Unicode characters, with
+// stacked fractions, raised exponents, radical signs and bracketed matrices
+func (n *Node) Pretty() string {
+	return n.layout(unicodeGlyphs).String()
+}
+
+// PrettyASCII draws the expression in two dimensions with ASCII characters
+func (n *Node) PrettyASCII() string {
+	return n.layout(asciiGlyphs).String()
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"testing"
+)
+
+func TestPretty(t *testing.T) {
+	tests := []struct {
+		expression, pretty, ascii string
+	}{
+		{"x^2/(x + 1)", "   2\n  x\n───────\n x + 1", "   2\n  x\n-------\n x + 1"},
+		{"sqrt(x + 1)", " _____\n√x + 1", "  _____\n\\|x + 1"},
+		{"[x 1; 2 y]", "⎡x  1⎤\n⎣2  y⎦", "[x  1]\n[2  y]"},
+		{"sin(x)^2 + 1", "      2\nsin(x)  + 1", "      2\nsin(x)  + 1"},
+		{"-(a - b)*c", "-(a - b) ⋅ c", "-(a - b) * c"},
+		{"(x!)!", "(x!)!", "(x!)!"},
+		{"e^(x^2)", "  2\n x\ne", "  2\n x\ne"},
+		{"[x^2 1/x; 1 y]", "⎡ 2   1 ⎤\n⎢x   ───⎥\n⎢     x ⎥\n⎢       ⎥\n⎣1    y ⎦",
+			"[ 2   1 ]\n[x   ---]\n[     x ]\n[       ]\n[1    y ]"},
+		{"sqrt(x^2 + 1)/2", "  ______\n │ 2\n √x  + 1\n─────────\n    2",
+			"   ______\n  | 2\n \\|x  + 1\n----------\n    2"},
+	}
+	for _, test := range tests {
+		n := parse(t, test.expression)
+		if result := n.Pretty(); result != test.pretty {
+			t.Errorf("%s =\n%s\nwant\n%s", test.expression, result, test.pretty)
+		}
+		if result := n.PrettyASCII(); result != test.ascii {
+			t.Errorf("%s =\n%s\nwant\n%s", test.expression, result, test.ascii)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// maxQuadratureLevel is the finest level of the tanh-sinh quadrature, the
+// step size of the last level is 2^-maxQuadratureLevel
+const maxQuadratureLevel = 10
+
+// Integral numerically integrates the equation with respect to the variable
+// name along the line from a to b at precision prec, looking up the other
+// variables and functions in env. The integral and an estimate of its error
+// are returned.
+func (n *Node) Integral(env *Environment, name string, a, b *complex.Rational, prec uint) (*complex.Matrix, *big.Float, error) {
+	if err := checkPrec(n, prec); err != nil {
+		return nil, nil, err
+	}
+	if env == nil {
+		env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	reservePI(prec)
+	return n.integral(env, name, a, b, prec)
+}
+
+// integral integrates with tanh-sinh quadrature, which substitutes
+// x = c + d tanh(pi/2 sinh t) so that the integrand decays double
+// exponentially and singularities at the end points are tolerated
+// https://en.wikipedia.org/wiki/Tanh-sinh_quadrature
+func (n *Node) integral(env *Environment, name string, a, b *complex.Rational, prec uint) (*complex.Matrix, *big.Float, error) {
+	wp := prec + guard
+	scope := env.child()
+	f := func(x *complex.Rational) (*complex.Float, error) {
+		scope.values[name] = Value{
+			ValueType: ValueTypeMatrix,
+			Matrix:    newScalar(wp, x),
+		}
+		y, err := n.evaluate(scope, wp, 0)
+		if err != nil {
+			return nil, err
+		} else if !isScalar(y) {
+			return nil, newNodeError(ErrorTypeDimension, n, "the integrand must be a 1x1 matrix")
+		}
+		return toFloat(&y.Values[0][0], wp), nil
+	}
+	half := big.NewRat(1, 2)
+	c := complex.NewRational(new(big.Rat).Add(a.A, b.A), new(big.Rat).Add(a.B, b.B))
+	c.A.Mul(c.A, half)
+	c.B.Mul(c.B, half)
+	d := complex.NewRational(new(big.Rat).Sub(b.A, a.A), new(big.Rat).Sub(b.B, a.B))
+	d.A.Mul(d.A, half)
+	d.B.Mul(d.B, half)
+	result := complex.NewMatrix(prec)
+	if isZero(d) {
+		result.Values = [][]complex.Rational{{*complex.NewRational(big.NewRat(0, 1), big.NewRat(0, 1))}}
+		return &result, newFloat(prec), nil
+	}
+	// point computes e + s d delta
+	point := func(e *complex.Rational, s int64, delta *big.Float) *complex.Rational {
+		r, _ := delta.Rat(nil)
+		r.Mul(r, big.NewRat(s, 1))
+		x := complex.NewRational(new(big.Rat).Mul(d.A, r), new(big.Rat).Mul(d.B, r))
+		x.A.Add(x.A, e.A)
+		x.B.Add(x.B, e.B)
+		return x
+	}
+
+	_, pi := constant(wp)
+	pi.SetMantExp(pi, -1)
+	eps := newFloat(wp).SetMantExp(big.NewFloat(1), -int(prec))
+	tiny := newFloat(wp).SetMantExp(big.NewFloat(1), -4*int(prec))
+	sum := newComplex(wp)
+	// the center of the interval
+	y, err := f(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum.A.Mul(y.A, pi)
+	sum.B.Mul(y.B, pi)
+
+	var estimates []*complex.Float
+	estimate := newFloat(prec)
+	for level := 0; level <= maxQuadratureLevel; level++ {
+		h := newFloat(wp).SetMantExp(big.NewFloat(1), -level)
+		step := int64(1)
+		if level > 0 {
+			step = 2
+		}
+		for k := int64(1); ; k += step {
+			t := newFloat(wp).Mul(h, newFloat(wp).SetInt64(k))
+			sinh, cosh := sinhCoshFloat(t, wp)
+			// e^2u where u = pi/2 sinh t
+			u := newFloat(wp).Mul(pi, sinh)
+			e := expFloat(u.SetMantExp(u, 1), wp)
+			one := newFloat(wp).Add(e, big.NewFloat(1))
+			// delta = 1 - tanh u, w = pi/2 cosh t sech^2 u
+			delta := newFloat(wp).Quo(big.NewFloat(2), one)
+			w := newFloat(wp).Mul(pi, cosh)
+			w.Mul(w, e)
+			w.SetMantExp(w, 2)
+			w.Quo(w, one)
+			w.Quo(w, one)
+			if delta.Cmp(tiny) < 0 {
+				break
+			}
+			y1, err := f(point(b, -1, delta))
+			if err != nil {
+				return nil, nil, err
+			}
+			y2, err := f(point(a, 1, delta))
+			if err != nil {
+				return nil, nil, err
+			}
+			term := newComplex(wp).Add(y1, y2)
+			term.A.Mul(term.A, w)
+			term.B.Mul(term.B, w)
+			sum.Add(sum, term)
+			if w.Cmp(eps) < 0 && magnitude(term, wp).Cmp(newFloat(wp).Mul(eps, magnitude(sum, wp))) <= 0 {
+				break
+			}
+		}
+
+		x := complex.NewFloat(newFloat(wp).Set(sum.A), newFloat(wp).Set(sum.B))
+		x.A.Mul(x.A, h)
+		x.B.Mul(x.B, h)
+		x = cmul(x, toFloat(d, wp), wp)
+		estimates = append(estimates, x)
+		if level < 3 {
+			continue
+		}
+		d1 := magnitude(newComplex(wp).Sub(x, estimates[level-1]), wp)
+		d2 := magnitude(newComplex(wp).Sub(estimates[level-1], estimates[level-2]), wp)
+		// the error decreases quadratically with each level
+		if d2.Sign() != 0 && d1.Cmp(d2) < 0 {
+			estimate.Quo(newFloat(wp).Mul(d1, d1), d2)
+		} else {
+			estimate.Set(d1)
+		}
+		if estimate.Cmp(newFloat(wp).Mul(eps, magnitude(x, wp))) <= 0 {
+			result.Values = [][]complex.Rational{{*toRational(complex.NewFloat(newFloat(prec).Set(x.A), newFloat(prec).Set(x.B)))}}
+			return &result, estimate, nil
+		}
+	}
+	return nil, estimate, newNodeError(ErrorTypeConvergence, n, "the estimated error of the integral is %s",
+		estimate.Text('g', 10))
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIntegral(t *testing.T) {
+	tests := []struct {
+		integrand, a, b, integral string
+	}{
+		{"exp(x)", "0", "1", "e - 1"},
+		{"4/(1 + x^2)", "0", "1", "pi"},
+		{"sqrt(1 - x^2)", "-1", "1", "pi/2"},
+		{"x^3", "2", "1", "-15/4"},
+		{"x", "1", "1", "0"},
+		// singularities at the end points
+		{"1/sqrt(x)", "0", "1", "2"},
+		{"log(x)", "0", "1", "-1"},
+		{"1/sqrt(1 - x^2)", "-1", "1", "pi"},
+		// complex integrands and bounds
+		{"exp(1i*x)", "0", "pi", "2i"},
+		{"x^2", "0", "1 + 1i", "(1 + 1i)^3/3"},
+	}
+	for _, test := range tests {
+		expression := fmt.Sprintf("integrate(%s, x, %s, %s) - (%s)", test.integrand, test.a, test.b, test.integral)
+		value, err := evaluate(NewEnvironment(), expression, Prec(256))
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+		} else if !negligible(value.Matrix, 240) {
+			t.Errorf("%s = %s, want 0", expression, format(value))
+		}
+	}
+	// a slowly converging integrand
+	run(t, []test{
+		{"integrate(log(x)^4/sqrt(x), x, 0, 1)", "768"},
+	})
+	// the error of an integrand which converges too slowly reports the
+	// estimate
+	if _, err := evaluate(NewEnvironment(), "withprec(128, integrate(1/x^(9/10), x, 0, 1))"); err == nil {
+		t.Errorf("withprec(128, integrate(1/x^(9/10), x, 0, 1)) converged")
+	} else if err.(*Error).ErrorType != ErrorTypeConvergence || !strings.Contains(err.Error(), "the estimated error of the integral is") {
+		t.Errorf("withprec(128, integrate(1/x^(9/10), x, 0, 1)): %v, want the estimated error", err)
+	}
+	runErrors(t, []errorTest{
+		{"withprec(128, integrate(1/x, x, 0, 1))", ErrorTypeConvergence, 0, 0},
+		{"integrate([x x], x, 0, 1)", ErrorTypeDimension, 0, 25},
+	})
+}
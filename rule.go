@@ -0,0 +1,463 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"errors"
+	"math/big"
+)
+
+// maxRewrites is the maximum number of passes of the rewrite rules over an
+// expression
+const maxRewrites = 64
+
+// Rule is a rewrite rule which replaces the expressions matching the pattern
+// with the replacement. The variables of the pattern match any expression and
+// are bound to the matched expressions in the replacement.
+type Rule struct {
+	Pattern, Replacement *Node
+}
+
+// DefaultRules are the rules used by Simplify, which can be extended with
+// rules created by NewRule
+var DefaultRules []*Rule
+
+// defaultRules are the default rules in calculator syntax
+var defaultRules = []string{
+	// trigonometric identities
+	"sin(a)^2 + cos(a)^2 -> 1",
+	"1 - sin(a)^2 -> cos(a)^2",
+	"1 - cos(a)^2 -> sin(a)^2",
+	"cos(a)^2 - sin(a)^2 -> cos(2*a)",
+	"2*sin(a)*cos(a) -> sin(2*a)",
+	"sin(a)/cos(a) -> tan(a)",
+	"cos(a)*tan(a) -> sin(a)",
+	"sin(-a) -> -sin(a)",
+	"cos(-a) -> cos(a)",
+	"tan(-a) -> -tan(a)",
+	"sin(0) -> 0",
+	"cos(0) -> 1",
+	"tan(0) -> 0",
+	"sin(pi) -> 0",
+	"cos(pi) -> -1",
+	"sin(pi/2) -> 1",
+	"cos(pi/2) -> 0",
+	// inverse and reciprocal trigonometric identities
+	"sin(asin(a)) -> a",
+	"cos(acos(a)) -> a",
+	"tan(atan(a)) -> a",
+	"asin(-a) -> -asin(a)",
+	"atan(-a) -> -atan(a)",
+	"asin(0) -> 0",
+	"acos(1) -> 0",
+	"atan(0) -> 0",
+	"asin(1) -> pi/2",
+	"acos(0) -> pi/2",
+	"atan(1) -> pi/4",
+	"cos(a)*sec(a) -> 1",
+	"sin(a)*csc(a) -> 1",
+	"tan(a)*cot(a) -> 1",
+	"sec(-a) -> sec(a)",
+	"csc(-a) -> -csc(a)",
+	"cot(-a) -> -cot(a)",
+	// hyperbolic identities
+	"cosh(a)^2 - sinh(a)^2 -> 1",
+	"sinh(a)/cosh(a) -> tanh(a)",
+	"sinh(-a) -> -sinh(a)",
+	"cosh(-a) -> cosh(a)",
+	"tanh(-a) -> -tanh(a)",
+	"sinh(0) -> 0",
+	"cosh(0) -> 1",
+	"tanh(0) -> 0",
+	"sinh(asinh(a)) -> a",
+	"cosh(acosh(a)) -> a",
+	"tanh(atanh(a)) -> a",
+	"asinh(-a) -> -asinh(a)",
+	"atanh(-a) -> -atanh(a)",
+	"asinh(0) -> 0",
+	"acosh(1) -> 0",
+	"atanh(0) -> 0",
+	// exponential and logarithm identities
+	"log(1) -> 0",
+	"log(1, a) -> 0",
+	"log(a, a) -> 1",
+	"e^log(a) -> a",
+	"e^a*e^b -> e^(a + b)",
+	"e^a/e^b -> e^(a - b)",
+	// power identities
+	"a^b*a^c -> a^(b + c)",
+	"a^b*a -> a^(b + 1)",
+	"a^b/a^c -> a^(b - c)",
+	"a^b/a -> a^(b - 1)",
+	"a/a^b -> a^(1 - b)",
+	// special function identities
+	"erf(-a) -> -erf(a)",
+	"erf(a) + erfc(a) -> 1",
+	"gamma(a + 1)/gamma(a) -> a",
+	"e^lgamma(a) -> gamma(a)",
+	"lambertw(a, b)*e^lambertw(a, b) -> a",
+	"lambertw(e) -> 1",
+	"ellipk(0) -> pi/2",
+	"ellipe(0) -> pi/2",
+}
+
+func init() {
+	DefaultRules = mustRules(defaultRules...)
+}
+
+// NewRule parses a rule written as pattern -> replacement
+func NewRule(rule string) (*Rule, error) {
+	c := &Calculator{Buffer: rule}
+	if err := c.Init(Env(NewEnvironment())); err != nil {
+		return nil, err
+	}
+	if err := c.Parse(); err != nil {
+		return nil, err
+	}
+	value, err := c.EvalE()
+	if err != nil {
+		return nil, err
+	} else if value.ValueType != ValueTypeRule {
+		return nil, errors.New("a rule is written as pattern -> replacement")
+	}
+	return value.Rule, nil
+}
+
+// mustRules parses rules and panics if there is an error
+func mustRules(rules ...string) []*Rule {
+	parsed := make([]*Rule, len(rules))
+	for i, rule := range rules {
+		r, err := NewRule(rule)
+		if err != nil {
+			panic(err)
+		}
+		parsed[i] = r
+	}
+	return parsed
+}
+
+// newRule creates a rule, the pattern is simplified so that it has the same
+// form as the expressions it matches
+func newRule(pattern, replacement *Node) (*Rule, error) {
+	if pattern.Operation == OperationVariable {
+		return nil, newNodeError(ErrorTypeValue, pattern, "the pattern matches every expression")
+	}
+	variables := make(map[string]bool)
+	for _, variable := range pattern.Variables() {
+		variables[variable] = true
+	}
+	for _, variable := range replacement.Variables() {
+		if !variables[variable] {
+			return nil, newNodeError(ErrorTypeUnknownIdentifier, replacement,
+				"%s is not a variable of the pattern", variable)
+		}
+	}
+	return &Rule{
+		Pattern:     pattern.simplify(),
+		Replacement: replacement,
+	}, nil
+}
+
+// String returns the string form of the rule
+func (r *Rule) String() string {
+	return r.Pattern.String() + " -> " + r.Replacement.String()
+}
+
+// term is a term of a sum or a factor of a product, inverse factors divide
+type term struct {
+	node    *Node
+	inverse bool
+}
+
+// negate changes the sign of an expression
+func negate(n *Node) *Node {
+	if n.Operation == OperationNegate {
+		return n.Left
+	}
+	return &Node{
+		Operation: OperationNegate,
+		Left:      n,
+	}
+}
+
+// terms flattens a sum into its terms
+func terms(n *Node) []term {
+	switch n.Operation {
+	case OperationAdd:
+		return append(terms(n.Left), terms(n.Right)...)
+	case OperationSubtract:
+		a := terms(n.Left)
+		for _, t := range terms(n.Right) {
+			a = append(a, term{node: negate(t.node)})
+		}
+		return a
+	}
+	return []term{{node: n}}
+}
+
+// factors flattens a product into its factors and their numeric coefficient
+func factors(n *Node) (coefficient *big.Rat, a []term) {
+	coefficient = big.NewRat(1, 1)
+	var process func(n *Node, inverse bool)
+	process = func(n *Node, inverse bool) {
+		switch n.Operation {
+		case OperationMultiply:
+			process(n.Left, inverse)
+			process(n.Right, inverse)
+			return
+		case OperationDivide:
+			process(n.Left, inverse)
+			process(n.Right, !inverse)
+			return
+		}
+		if r, ok := rational(n); ok && r.Sign() != 0 {
+			if inverse {
+				r.Inv(r)
+			}
+			coefficient.Mul(coefficient, r)
+			return
+		}
+		a = append(a, term{node: n, inverse: inverse})
+	}
+	process(n, false)
+	return coefficient, a
+}
+
+// bind copies the bindings
+func bind(bindings map[string]*Node) map[string]*Node {
+	a := make(map[string]*Node, len(bindings))
+	for name, n := range bindings {
+		a[name] = n
+	}
+	return a
+}
+
+// assign matches each of the pattern terms with a distinct unused term of the
+// expression
+func assign(patterns, ts []term, used []bool, bindings map[string]*Node) (map[string]*Node, bool) {
+	if len(patterns) == 0 {
+		return bindings, true
+	}
+	p := patterns[0]
+	for i, t := range ts {
+		if used[i] || t.inverse != p.inverse {
+			continue
+		}
+		if b, ok := match(p.node, t.node, bindings); ok {
+			used[i] = true
+			if b, ok := assign(patterns[1:], ts, used, b); ok {
+				return b, true
+			}
+			used[i] = false
+		}
+	}
+	return nil, false
+}
+
+// match matches the pattern with the expression, extending the bindings of
+// the pattern variables. A sum or a product within the pattern only matches a
+// sum or a product with the same number of terms or factors, the factors are
+// matched in any order so a product of matrices doesn't match. The rules are
+// identities of scalars so the pattern variables don't match matrices.
+func match(pattern, n *Node, bindings map[string]*Node) (map[string]*Node, bool) {
+	if pattern.Operation == OperationVariable {
+		if matrix(n) {
+			return nil, false
+		} else if a, ok := bindings[pattern.Value]; ok {
+			return bindings, a.Identical(n)
+		}
+		b := bind(bindings)
+		b[pattern.Value] = n
+		return b, true
+	}
+	if a, ok := number(pattern); ok {
+		b, ok := number(n)
+		return bindings, ok && a.A.Cmp(b.A) == 0 && a.B.Cmp(b.B) == 0
+	}
+	switch pattern.Operation {
+	case OperationAdd, OperationSubtract:
+		patterns, ts := terms(pattern), terms(n)
+		if len(patterns) != len(ts) {
+			return nil, false
+		}
+		return assign(patterns, ts, make([]bool, len(ts)), bindings)
+	case OperationMultiply, OperationDivide:
+		if matrix(n) {
+			return nil, false
+		}
+		pc, patterns := factors(pattern)
+		c, ts := factors(n)
+		if len(patterns) != len(ts) || pc.Cmp(c) != 0 {
+			return nil, false
+		}
+		return assign(patterns, ts, make([]bool, len(ts)), bindings)
+	}
+	if pattern.Operation != n.Operation || pattern.Value != n.Value ||
+		len(pattern.Arguments) != len(n.Arguments) || len(pattern.Rows) != len(n.Rows) ||
+		(pattern.Left == nil) != (n.Left == nil) || (pattern.Right == nil) != (n.Right == nil) {
+		return nil, false
+	}
+	var ok bool
+	if pattern.Left != nil {
+		if bindings, ok = match(pattern.Left, n.Left, bindings); !ok {
+			return nil, false
+		}
+	}
+	if pattern.Right != nil {
+		if bindings, ok = match(pattern.Right, n.Right, bindings); !ok {
+			return nil, false
+		}
+	}
+	for i, argument := range pattern.Arguments {
+		if bindings, ok = match(argument, n.Arguments[i], bindings); !ok {
+			return nil, false
+		}
+	}
+	for i, row := range pattern.Rows {
+		if len(row) != len(n.Rows[i]) {
+			return nil, false
+		}
+		for j, element := range row {
+			if bindings, ok = match(element, n.Rows[i][j], bindings); !ok {
+				return nil, false
+			}
+		}
+	}
+	return bindings, true
+}
+
+// apply applies the rule to the expression. The pattern of a sum matches
+// some of the terms of a sum and the pattern of a product matches some of the
+// factors of a product, the other terms or factors are kept. The factors of a
+// product of matrices don't commute so they aren't matched.
+func (r *Rule) apply(n *Node) (*Node, bool) {
+	bindings := make(map[string]*Node)
+	switch r.Pattern.Operation {
+	case OperationAdd, OperationSubtract:
+		patterns, ts := terms(r.Pattern), terms(n)
+		if len(ts) <= len(patterns) {
+			break
+		}
+		used := make([]bool, len(ts))
+		b, ok := assign(patterns, ts, used, bindings)
+		if !ok {
+			return nil, false
+		}
+		a := r.Replacement.Substitute(b)
+		for i, t := range ts {
+			if !used[i] {
+				a = addition(a, t.node)
+			}
+		}
+		return a, true
+	case OperationMultiply, OperationDivide:
+		if matrix(n) {
+			return nil, false
+		}
+		pc, patterns := factors(r.Pattern)
+		c, ts := factors(n)
+		if len(ts) <= len(patterns) && pc.Cmp(c) == 0 {
+			break
+		}
+		used := make([]bool, len(ts))
+		b, ok := assign(patterns, ts, used, bindings)
+		if !ok {
+			return nil, false
+		}
+		a := r.Replacement.Substitute(b)
+		if c.Quo(c, pc); c.Cmp(big.NewRat(1, 1)) != 0 {
+			a = &Node{
+				Operation: OperationMultiply,
+				Left:      newRational(c),
+				Right:     a,
+			}
+		}
+		for i, t := range ts {
+			if used[i] {
+				continue
+			}
+			operation := OperationMultiply
+			if t.inverse {
+				operation = OperationDivide
+			}
+			a = &Node{
+				Operation: operation,
+				Left:      a,
+				Right:     t.node,
+			}
+		}
+		return a, true
+	}
+	b, ok := match(r.Pattern, n, bindings)
+	if !ok {
+		return nil, false
+	}
+	return r.Replacement.Substitute(b), true
+}
+
+// rewrite applies the first matching rule to each sub-expression from the
+// bottom up, the replacements aren't rewritten again
+func (n *Node) rewrite(rules []*Rule) (*Node, bool) {
+	changed := false
+	rewritten := make(map[*Node]*Node)
+	var process func(n *Node) *Node
+	process = func(n *Node) *Node {
+		if a, ok := rewritten[n]; ok {
+			return a
+		}
+		a := n.apply(process)
+		for _, rule := range rules {
+			if b, ok := rule.apply(a); ok {
+				changed = true
+				a = b
+				break
+			}
+		}
+		rewritten[n] = a
+		return a
+	}
+	a := process(n)
+	return a, changed
+}
+
+// Rewrite applies the rules to the expression until none of them match. The
+// rules are applied at most a fixed number of times so that rules which undo
+// each other terminate.
+func (n *Node) Rewrite(rules []*Rule) *Node {
+	a := n
+	for i := 0; i < maxRewrites; i++ {
+		b, changed := a.rewrite(rules)
+		if !changed {
+			break
+		}
+		a = b
+	}
+	return a
+}
+
+// SimplifyWith simplifies an expression with the rules, simplifying after
+// each pass of the rules until the expression doesn't change
+func (n *Node) SimplifyWith(rules []*Rule) *Node {
+	a := n.simplify()
+	seen := map[string]bool{
+		a.String(): true,
+	}
+	for i := 0; i < maxRewrites; i++ {
+		b, changed := a.rewrite(rules)
+		if !changed {
+			break
+		}
+		b = b.simplify()
+		s := b.String()
+		if seen[s] {
+			// the rules undo each other
+			a = b
+			break
+		}
+		seen[s] = true
+		a = b
+	}
+	return a
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	run(t, []test{
+		{"simplify(sin(x)^2 + cos(x)^2)", "1"},
+		{"simplify(e^log(x))", "x"},
+		{"simplify(sin(-x))", "-sin(x)"},
+		{"simplify(e^x*e^y)", "e^(x + y)"},
+		{"simplify(tan(y)*cot(y))", "1"},
+		{"simplify(erf(x) + erfc(x))", "1"},
+	})
+}
+
+// agree checks that the simplified expressions have the same values as the
+// expressions at each of the points, where the powers and the logarithms are
+// on the principal branch
+func agree(t *testing.T, expressions, points []string) {
+	t.Helper()
+	for _, expression := range expressions {
+		for _, point := range points {
+			check := fmt.Sprintf("eval(simplify(%s), y=%s) - eval(%s, y=%s)", expression, point, expression, point)
+			value, err := evaluate(NewEnvironment(), check)
+			if err != nil {
+				t.Errorf("%s: %v", check, err)
+			} else if !negligible(value.Matrix, 900) {
+				t.Errorf("%s = %s, want 0", check, format(value))
+			}
+		}
+	}
+}
+
+// TestDefaultRulesValues checks that the default rules don't change the
+// values of expressions, including at negative and complex points
+func TestDefaultRulesValues(t *testing.T) {
+	agree(t, []string{
+		"sin(y)^2 + cos(y)^2",
+		"cos(y)^2 - sin(y)^2",
+		"2*sin(y)*cos(y)",
+		"sin(y)/cos(y)",
+		"cos(y)*tan(y)",
+		"sin(-y) + cos(-y) + tan(-y)",
+		"e^log(y)",
+		"e^y*e^(2*y)",
+		"e^y/e^(3*y)",
+		"log(e^y)",
+		"(e^y)^(1/2)",
+		"(e^y)^y",
+		"y^(1/2)*y^(3/2)",
+		"y^(1/3)*y",
+		"y^(1/2)/y^2",
+		"y/y^(1/3)",
+		"log(y, y)",
+		"log(1, y)",
+		"log(y^2, y)",
+		"log(y^(1/2), y)",
+	}, []string{"-1", "1/2", "3", "2 + 1i", "3*pi*1i", "-2 - 5i"})
+	run(t, []test{
+		// the logarithm of e^y isn't y if the imaginary part of y isn't in
+		// (-pi, pi]
+		{"simplify(log(e^y))", "log(e^y)"},
+		{"simplify((e^y)^2)", "(e^y)^2"},
+		// the logarithm of y^2 to the base y is 0 at y = -1
+		{"simplify(log(y^2, y))", "log(y^2, y)"},
+	})
+}
+
+func TestRules(t *testing.T) {
+	runSession(t, []test{
+		{"log(a*b) -> log(a) + log(b)", "log(a * b) -> log(a) + log(b)"},
+		{"simplify(log(x*y))", "log(x) + log(y)"},
+		// rules which undo each other terminate
+		{"sin(a) -> cos(a)", "sin(a) -> cos(a)"},
+		{"cos(a) -> sin(a)", "cos(a) -> sin(a)"},
+		{"simplify(sin(x))", "sin(x)"},
+		// rules which don't reach a fixpoint stop after maxRewrites passes
+		{"exp(a) -> exp(a + 1)", "e^a -> e^(a + 1)"},
+		{"simplify(exp(x))", "e^(x + 64)"},
+	})
+	runErrors(t, []errorTest{
+		{"a -> 1", ErrorTypeValue, 0, 6},
+		{"log(a) -> log(b)", ErrorTypeUnknownIdentifier, 0, 16},
+	})
+
+	rule, err := NewRule("sinh(a) + cosh(a) -> exp(a)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := parse(t, "sinh(2*x) + cosh(2*x) + 1").SimplifyWith(append([]*Rule{rule}, DefaultRules...)).String(); result != "e^(2 * x) + 1" {
+		t.Errorf("sinh(2*x) + cosh(2*x) + 1 = %s, want e^(2 * x) + 1", result)
+	}
+	if _, err := NewRule("1 + 2"); err == nil {
+		t.Error("1 + 2 is a rule")
+	}
+}
@@ -0,0 +1,756 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"errors"
+	"math/big"
+)
+
+// maxSeriesTerms is the maximum number of extra terms computed when there is
+// a pole or cancellation within the series
+const maxSeriesTerms = 64
+
+// errVanishing is returned when a series has no non zero terms before its
+// order, more terms are needed
+var errVanishing = errors.New("the series vanishes")
+
+// operate applies the binary operation to two coefficients
+func operate(operation Operation, a, b *Node) *Node {
+	return (&Node{
+		Operation: operation,
+		Left:      a,
+		Right:     b,
+	}).simplify()
+}
+
+// series is a truncated Laurent series in t = x - a, the coefficients are of
+// the powers of t from the valuation up to the order, which is the power of
+// the first unknown term
+type series struct {
+	valuation, order int
+	coefficients     []*Node
+}
+
+// newSeries creates a series with the coefficients of the powers from the
+// valuation up to the order computed by coefficient
+func newSeries(valuation, order int, coefficient func(k int) *Node) *series {
+	s := &series{
+		valuation: valuation,
+		order:     order,
+	}
+	for k := valuation; k < order; k++ {
+		s.coefficients = append(s.coefficients, coefficient(k))
+	}
+	return s.strip()
+}
+
+// constantSeries is the series of an expression which doesn't depend on the
+// variable
+func constantSeries(c *Node, order int) *series {
+	return newSeries(0, order, func(k int) *Node {
+		if k == 0 {
+			return c
+		}
+		return newRational(new(big.Rat))
+	})
+}
+
+// strip removes the leading zero coefficients, a series without any non zero
+// coefficients has a valuation equal to its order
+func (s *series) strip() *series {
+	for len(s.coefficients) > 0 && s.coefficients[0].Equals(0) {
+		s.coefficients = s.coefficients[1:]
+		s.valuation++
+	}
+	if len(s.coefficients) == 0 {
+		s.valuation = s.order
+	}
+	return s
+}
+
+// at returns the coefficient of t^k
+func (s *series) at(k int) *Node {
+	if k < s.valuation || k >= s.order {
+		return newRational(new(big.Rat))
+	}
+	return s.coefficients[k-s.valuation]
+}
+
+// minimum returns the smaller integer
+func minimum(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// add computes the sum of the series, the other series is scaled by sign
+func (s *series) add(t *series, sign int64) *series {
+	return newSeries(minimum(s.valuation, t.valuation), minimum(s.order, t.order), func(k int) *Node {
+		b := t.at(k)
+		if sign < 0 {
+			b = operate(OperationMultiply, newRational(big.NewRat(sign, 1)), b)
+		}
+		return operate(OperationAdd, s.at(k), b)
+	})
+}
+
+// scale multiplies the series by a constant
+func (s *series) scale(c *Node) *series {
+	return newSeries(s.valuation, s.order, func(k int) *Node {
+		return operate(OperationMultiply, c, s.at(k))
+	})
+}
+
+// multiply computes the product of the series
+func (s *series) multiply(t *series) *series {
+	valuation := s.valuation + t.valuation
+	order := minimum(s.valuation+t.order, t.valuation+s.order)
+	return newSeries(valuation, order, func(k int) *Node {
+		a := newRational(new(big.Rat))
+		for i := s.valuation; i <= k-t.valuation; i++ {
+			a = operate(OperationAdd, a, operate(OperationMultiply, s.at(i), t.at(k-i)))
+		}
+		return a
+	})
+}
+
+// inverse computes the reciprocal of the series
+func (s *series) inverse() (*series, error) {
+	if len(s.coefficients) == 0 {
+		return nil, errVanishing
+	}
+	a := s.coefficients
+	d := make([]*Node, len(a))
+	d[0] = operate(OperationDivide, newRational(big.NewRat(1, 1)), a[0])
+	for k := 1; k < len(a); k++ {
+		sum := newRational(new(big.Rat))
+		for j := 1; j <= k; j++ {
+			sum = operate(OperationAdd, sum, operate(OperationMultiply, a[j], d[k-j]))
+		}
+		d[k] = operate(OperationDivide, &Node{
+			Operation: OperationNegate,
+			Left:      sum,
+		}, a[0])
+	}
+	valuation := -s.valuation
+	return newSeries(valuation, valuation+len(a), func(k int) *Node {
+		return d[k-valuation]
+	}), nil
+}
+
+// power raises the series to an integer power
+func (s *series) power(m int64, order int) (*series, error) {
+	if m < 0 {
+		inverse, err := s.inverse()
+		if err != nil {
+			return nil, err
+		}
+		return inverse.power(-m, order)
+	}
+	a := constantSeries(newRational(big.NewRat(1, 1)), order)
+	for b := s; m > 0; m >>= 1 {
+		if m&1 == 1 {
+			a = a.multiply(b)
+		}
+		if m > 1 {
+			b = b.multiply(b)
+		}
+	}
+	return a, nil
+}
+
+// compose computes the sum of the coefficients times the powers of the
+// series, which must have a positive valuation
+func (s *series) compose(order int, coefficient func(k int) *Node) *series {
+	a := constantSeries(coefficient(0), minimum(order, s.order))
+	g := constantSeries(newRational(big.NewRat(1, 1)), order)
+	for k := 1; k < order; k++ {
+		if g = g.multiply(s); g.valuation >= a.order {
+			break
+		}
+		a = a.add(g.scale(coefficient(k)), 1)
+	}
+	return a
+}
+
+// split separates the constant term of a series with a non negative
+// valuation from the rest of the series
+func (s *series) split(n *Node) (*Node, *series, error) {
+	if s.valuation < 0 {
+		return nil, nil, newNodeError(ErrorTypeDomain, n, "%s has an essential singularity", n.String())
+	}
+	a := s.at(0)
+	return a, s.add(constantSeries(a, s.order), -1), nil
+}
+
+// unit divides the series by its leading term, which leaves a series with a
+// constant term of one
+func (s *series) unit() (*series, error) {
+	if len(s.coefficients) == 0 {
+		return nil, errVanishing
+	}
+	leading := s.coefficients[0]
+	return newSeries(0, s.order-s.valuation, func(k int) *Node {
+		return operate(OperationDivide, s.at(k+s.valuation), leading)
+	}), nil
+}
+
+// integral integrates the series term by term, the series must not have a
+// term in 1/t
+func (s *series) integral() *series {
+	return newSeries(s.valuation+1, s.order+1, func(k int) *Node {
+		return operate(OperationDivide, s.at(k-1), ratio(int64(k), 1))
+	})
+}
+
+// trigonometric writes the reciprocal trigonometric functions and the hyperbolic
+// functions in terms of sin, cos, tan and e^
+func trigonometric(n *Node) *Node {
+	one := ratio(1, 1)
+	unary := func(operation Operation, left *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+		}
+	}
+	binary := func(operation Operation, left, right *Node) *Node {
+		return &Node{
+			Operation: operation,
+			Left:      left,
+			Right:     right,
+		}
+	}
+	u := n.Left
+	exp, inverse := unary(OperationNaturalExponentiation, u), unary(OperationNaturalExponentiation, negate(u))
+	switch n.Operation {
+	case OperationSecant:
+		return binary(OperationDivide, one, unary(OperationCosine, u))
+	case OperationCosecant:
+		return binary(OperationDivide, one, unary(OperationSine, u))
+	case OperationCotangent:
+		return binary(OperationDivide, one, unary(OperationTangent, u))
+	case OperationHyperbolicSine:
+		return binary(OperationDivide, binary(OperationSubtract, exp, inverse), ratio(2, 1))
+	case OperationHyperbolicCosine:
+		return binary(OperationDivide, binary(OperationAdd, exp, inverse), ratio(2, 1))
+	}
+	return binary(OperationDivide, binary(OperationSubtract, exp, inverse), binary(OperationAdd, exp, inverse))
+}
+
+// ratio returns the rational number k/m
+func ratio(k, m int64) *Node {
+	return newRational(big.NewRat(k, m))
+}
+
+// factorial computes k!
+func factorial(k int) *big.Int {
+	return new(big.Int).MulRange(1, int64(k))
+}
+
+// series computes the truncated series of the expression in the variable name
+// around the point a, the terms are computed up to the working order
+func (n *Node) series(name string, a *Node, order int) (*series, error) {
+	var process func(n *Node) (*series, error)
+	process = func(n *Node) (*series, error) {
+		if !n.depends(name) {
+			return constantSeries(n.Simplify(), order), nil
+		}
+		switch n.Operation {
+		case OperationVariable:
+			// x = a + t
+			return newSeries(0, order, func(k int) *Node {
+				switch k {
+				case 0:
+					return a
+				case 1:
+					return ratio(1, 1)
+				}
+				return ratio(0, 1)
+			}), nil
+		case OperationAdd, OperationSubtract, OperationMultiply, OperationDivide:
+			left, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			right, err := process(n.Right)
+			if err != nil {
+				return nil, err
+			}
+			switch n.Operation {
+			case OperationAdd:
+				return left.add(right, 1), nil
+			case OperationSubtract:
+				return left.add(right, -1), nil
+			case OperationMultiply:
+				return left.multiply(right), nil
+			}
+			inverse, err := right.inverse()
+			if err != nil {
+				return nil, err
+			}
+			return left.multiply(inverse), nil
+		case OperationNegate:
+			left, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			return left.scale(ratio(-1, 1)), nil
+		case OperationExponentiation, OperationSquareRoot:
+			exponent := ratio(1, 2)
+			if n.Operation == OperationExponentiation {
+				exponent = n.Right
+			}
+			if exponent.depends(name) {
+				// f^g = e^(g log f)
+				return process(&Node{
+					Operation: OperationNaturalExponentiation,
+					Left: &Node{
+						Operation: OperationMultiply,
+						Left:      exponent,
+						Right: &Node{
+							Operation: OperationNaturalLogarithm,
+							Left:      n.Left,
+						},
+					},
+				})
+			}
+			base, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := rational(exponent)
+			if ok && r.IsInt() && r.Num().IsInt64() && abs(r.Num().Int64()) <= maxExponent {
+				return base.power(r.Num().Int64(), order)
+			}
+			// c t^v (1 + u)^r with the binomial series
+			valuation := new(big.Rat)
+			if base.valuation != 0 {
+				if ok {
+					valuation.Mul(r, big.NewRat(int64(base.valuation), 1))
+				}
+				if !ok || !valuation.IsInt() {
+					return nil, newNodeError(ErrorTypeDomain, n, "%s has a branch point", n.String())
+				}
+			}
+			u, err := base.unit()
+			if err != nil {
+				return nil, err
+			}
+			_, u, err = u.split(n)
+			if err != nil {
+				return nil, err
+			}
+			binomial := ratio(1, 1)
+			a := u.compose(order, func(k int) *Node {
+				if k > 0 {
+					binomial = operate(OperationMultiply, binomial, operate(OperationDivide,
+						operate(OperationSubtract, exponent, ratio(int64(k-1), 1)), ratio(int64(k), 1)))
+				}
+				return binomial
+			})
+			leading := (&Node{
+				Operation: OperationExponentiation,
+				Left:      base.coefficients[0],
+				Right:     exponent,
+			}).Simplify()
+			a = a.scale(leading)
+			shift := int(valuation.Num().Int64())
+			return newSeries(a.valuation+shift, a.order+shift, func(k int) *Node {
+				return a.at(k - shift)
+			}), nil
+		case OperationNaturalExponentiation:
+			left, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			c, g, err := left.split(n)
+			if err != nil {
+				return nil, err
+			}
+			a := g.compose(order, func(k int) *Node {
+				return newRational(new(big.Rat).SetFrac(big.NewInt(1), factorial(k)))
+			})
+			return a.scale((&Node{
+				Operation: OperationNaturalExponentiation,
+				Left:      c,
+			}).Simplify()), nil
+		case OperationNaturalLogarithm:
+			left, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			} else if len(left.coefficients) > 0 && left.valuation != 0 {
+				return nil, newNodeError(ErrorTypeDomain, n, "%s has a logarithmic singularity", n.String())
+			}
+			u, err := left.unit()
+			if err != nil {
+				return nil, err
+			}
+			_, u, err = u.split(n)
+			if err != nil {
+				return nil, err
+			}
+			a := u.compose(order, func(k int) *Node {
+				if k == 0 {
+					return ratio(0, 1)
+				} else if k%2 == 0 {
+					return ratio(-1, int64(k))
+				}
+				return ratio(1, int64(k))
+			})
+			return a.add(constantSeries((&Node{
+				Operation: OperationNaturalLogarithm,
+				Left:      left.coefficients[0],
+			}).Simplify(), order), 1), nil
+		case OperationSine, OperationCosine, OperationTangent:
+			left, err := process(n.Left)
+			if err != nil {
+				return nil, err
+			}
+			c, g, err := left.split(n)
+			if err != nil {
+				return nil, err
+			}
+			// the series of sin and cos of g
+			trigonometric := func(odd bool) *series {
+				return g.compose(order, func(k int) *Node {
+					if (k%2 == 1) != odd {
+						return ratio(0, 1)
+					}
+					sign := int64(1)
+					if k/2%2 == 1 {
+						sign = -1
+					}
+					return newRational(new(big.Rat).SetFrac(big.NewInt(sign), factorial(k)))
+				})
+			}
+			sin, cos := trigonometric(true), trigonometric(false)
+			sinc := (&Node{
+				Operation: OperationSine,
+				Left:      c,
+			}).Simplify()
+			cosc := (&Node{
+				Operation: OperationCosine,
+				Left:      c,
+			}).Simplify()
+			// sin(c + g) = sin(c) cos(g) + cos(c) sin(g)
+			// cos(c + g) = cos(c) cos(g) - sin(c) sin(g)
+			sine := cos.scale(sinc).add(sin.scale(cosc), 1)
+			cosine := cos.scale(cosc).add(sin.scale(sinc), -1)
+			switch n.Operation {
+			case OperationSine:
+				return sine, nil
+			case OperationCosine:
+				return cosine, nil
+			}
+			inverse, err := cosine.inverse()
+			if err != nil {
+				return nil, err
+			}
+			return sine.multiply(inverse), nil
+		case OperationLogarithm:
+			// log(f, g) = log(f) / log(g)
+			return process(&Node{
+				Operation: OperationDivide,
+				Left: &Node{
+					Operation: OperationNaturalLogarithm,
+					Left:      n.Left,
+				},
+				Right: &Node{
+					Operation: OperationNaturalLogarithm,
+					Left:      n.Right,
+				},
+			})
+		case OperationSecant, OperationCosecant, OperationCotangent,
+			OperationHyperbolicSine, OperationHyperbolicCosine, OperationHyperbolicTangent:
+			return process(trigonometric(n))
+		case OperationFactorial:
+			// u! = Γ(u + 1)
+			return process(&Node{
+				Operation: OperationGamma,
+				Left:      addition(n.Left, ratio(1, 1)),
+			})
+		case OperationBeta:
+			// B(a, b) = Γ(a) Γ(b) / Γ(a + b)
+			gamma := func(n *Node) *Node {
+				return &Node{
+					Operation: OperationGamma,
+					Left:      n,
+				}
+			}
+			return process(&Node{
+				Operation: OperationDivide,
+				Left: &Node{
+					Operation: OperationMultiply,
+					Left:      gamma(n.Left),
+					Right:     gamma(n.Right),
+				},
+				Right: gamma(addition(n.Left, n.Right)),
+			})
+		case OperationGamma, OperationLogGamma, OperationDigamma, OperationPolygamma,
+			OperationZeta, OperationZetaDerivative:
+			return n.special(order, process)
+		case OperationArcsine, OperationArccosine, OperationArctangent, OperationArctangent2,
+			OperationHyperbolicArcsine, OperationHyperbolicArccosine, OperationHyperbolicArctangent,
+			OperationErf, OperationErfc:
+			// f(u) = f(u(a)) + the integral of f'(u) u'
+			constant := &Node{
+				Operation: n.Operation,
+			}
+			for _, operand := range []**Node{&n.Left, &n.Right} {
+				if *operand == nil {
+					continue
+				}
+				a, err := process(*operand)
+				if err != nil {
+					return nil, err
+				}
+				c, _, err := a.split(n)
+				if err != nil {
+					return nil, err
+				}
+				if operand == &n.Left {
+					constant.Left = c
+				} else {
+					constant.Right = c
+				}
+			}
+			d := n.DerivativeWith(name)
+			if d == nil {
+				return nil, newNodeError(ErrorTypeValue, n, "the series of %s can not be computed", n.String())
+			}
+			derivative, err := process(d)
+			if err == errVanishing {
+				return nil, err
+			} else if err != nil || derivative.valuation < 0 {
+				return nil, newNodeError(ErrorTypeDomain, n, "%s has a branch point", n.String())
+			}
+			return derivative.integral().add(constantSeries(constant.Simplify(), order), 1), nil
+		}
+		return nil, newNodeError(ErrorTypeValue, n, "the series of %s can not be computed", n.String())
+	}
+	return process(n)
+}
+
+// special computes the series of the gamma, digamma, polygamma and zeta
+// functions from the Taylor series at the constant term of the argument, which
+// are written with the polygamma functions and the derivatives of zeta. The
+// poles of the gamma functions are moved away with their recurrences.
+func (n *Node) special(order int, process func(n *Node) (*series, error)) (*series, error) {
+	left, err := process(n.Left)
+	if err != nil {
+		return nil, err
+	}
+	c, g, err := left.split(n)
+	if err != nil {
+		return nil, err
+	}
+	m := 0
+	if n.Right != nil {
+		r, ok := rational(n.Right)
+		if !ok || !r.IsInt() || r.Sign() < 0 || r.Num().Cmp(big.NewInt(maxExponent)) > 0 {
+			return nil, newNodeError(ErrorTypeValue, n, "the series of %s can not be computed", n.String())
+		}
+		m = int(r.Num().Int64())
+	}
+	x, ok := number(c)
+	switch n.Operation {
+	case OperationZeta, OperationZetaDerivative:
+		if ok && x.B.Sign() == 0 && x.A.Cmp(big.NewRat(1, 1)) == 0 {
+			return nil, newNodeError(ErrorTypeDomain, n, "%s has a pole", n.String())
+		}
+	case OperationLogGamma:
+		if ok && nonPositive(x) {
+			return nil, newNodeError(ErrorTypeDomain, n, "%s has a branch point", n.String())
+		}
+	default:
+		if ok && nonPositive(x) {
+			k := new(big.Int).Neg(x.A.Num())
+			if k.Cmp(big.NewInt(maxExponent)) >= 0 {
+				return nil, newNodeError(ErrorTypeValue, n, "the series of %s can not be computed", n.String())
+			}
+			return process(recurrence(n, int(k.Int64())+1, m))
+		}
+	}
+	// the kth derivative of f at c divided by k!
+	derivative := func(k int) *Node {
+		var d *Node
+		switch n.Operation {
+		case OperationGamma, OperationLogGamma:
+			// the derivatives of log Γ are the polygamma functions
+			if k == 0 {
+				return ratio(0, 1)
+			}
+			d = &Node{
+				Operation: OperationPolygamma,
+				Left:      c,
+				Right:     ratio(int64(k-1), 1),
+			}
+		case OperationDigamma, OperationPolygamma:
+			d = &Node{
+				Operation: OperationPolygamma,
+				Left:      c,
+				Right:     ratio(int64(m+k), 1),
+			}
+		case OperationZeta, OperationZetaDerivative:
+			d = &Node{
+				Operation: OperationZetaDerivative,
+				Left:      c,
+				Right:     ratio(int64(m+k), 1),
+			}
+		}
+		return operate(OperationDivide, d, newRational(new(big.Rat).SetInt(factorial(k))))
+	}
+	s := g.compose(order, derivative)
+	switch n.Operation {
+	case OperationLogGamma:
+		return s.add(constantSeries((&Node{
+			Operation: OperationLogGamma,
+			Left:      c,
+		}).Simplify(), order), 1), nil
+	case OperationGamma:
+		// Γ(c + g) = Γ(c) e^(log Γ(c + g) - log Γ(c))
+		s = s.compose(order, func(k int) *Node {
+			return newRational(new(big.Rat).SetFrac(big.NewInt(1), factorial(k)))
+		})
+		return s.scale((&Node{
+			Operation: OperationGamma,
+			Left:      c,
+		}).Simplify()), nil
+	}
+	return s, nil
+}
+
+// recurrence moves the argument u of the gamma, digamma or polygamma
+// function of order m up by k with Γ(u) = Γ(u + k) / (u (u + 1) ... (u + k - 1))
+// and ψ^(m)(u) = ψ^(m)(u + k) - (-1)^m m! the sum of 1/(u + j)^(m + 1)
+func recurrence(n *Node, k, m int) *Node {
+	u := n.Left
+	shifted := &Node{
+		Operation: n.Operation,
+		Left:      addition(u, ratio(int64(k), 1)),
+		Right:     n.Right,
+	}
+	var a *Node
+	for j := 0; j < k; j++ {
+		b := u
+		if j > 0 {
+			b = addition(u, ratio(int64(j), 1))
+		}
+		if n.Operation == OperationGamma {
+			if a == nil {
+				a = b
+				continue
+			}
+			a = &Node{
+				Operation: OperationMultiply,
+				Left:      a,
+				Right:     b,
+			}
+			continue
+		}
+		a = addition(a, &Node{
+			Operation: OperationDivide,
+			Left:      ratio(1, 1),
+			Right: &Node{
+				Operation: OperationExponentiation,
+				Left:      b,
+				Right:     ratio(int64(m+1), 1),
+			},
+		})
+	}
+	if n.Operation == OperationGamma {
+		return &Node{
+			Operation: OperationDivide,
+			Left:      shifted,
+			Right:     a,
+		}
+	}
+	c := new(big.Rat).SetInt(factorial(m))
+	if m%2 == 1 {
+		c.Neg(c)
+	}
+	return &Node{
+		Operation: OperationSubtract,
+		Left:      shifted,
+		Right: &Node{
+			Operation: OperationMultiply,
+			Left:      newRational(c),
+			Right:     a,
+		},
+	}
+}
+
+// expansion computes the series of the expression with the terms of the
+// powers less than order known, computing extra terms when there is
+// cancellation or a pole within the expression
+func (n *Node) expansion(name string, a *Node, order int) (*series, error) {
+	extra := 0
+	for extra <= maxSeriesTerms {
+		s, err := n.series(name, a, order+extra)
+		if err == errVanishing {
+			extra = 2*extra + 1
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if s.order < order {
+			extra += order - s.order
+			continue
+		}
+		return s, nil
+	}
+	return nil, newNodeError(ErrorTypeConvergence, n, "the series of %s needs more than %d extra terms",
+		n.String(), maxSeriesTerms)
+}
+
+// Series computes the Taylor or Laurent series of the expression in the
+// variable name around the point a, with the terms of the powers of x - a
+// less than order
+func (n *Node) Series(name string, a *Node, order int) (*Node, error) {
+	s, err := n.expansion(name, a, order)
+	if err != nil {
+		return nil, err
+	}
+	// the terms are simplified in a placeholder for x - a, which keeps
+	// x - a together in the result
+	placeholder := &Node{
+		Operation: OperationVariable,
+		Value:     "#",
+	}
+	t := &Node{
+		Operation: OperationVariable,
+		Value:     name,
+	}
+	if !a.Equals(0) {
+		t = addition(t, negate(a.Simplify()))
+	}
+	var result *Node
+	for k := s.valuation; k < order; k++ {
+		c := s.at(k).Simplify()
+		if c.Equals(0) {
+			continue
+		}
+		term := c
+		if k != 0 {
+			term = (&Node{
+				Operation: OperationMultiply,
+				Left:      c,
+				Right: &Node{
+					Operation: OperationExponentiation,
+					Left:      placeholder,
+					Right:     ratio(int64(k), 1),
+				},
+			}).Simplify().Substitute(map[string]*Node{
+				placeholder.Value: t,
+			})
+		}
+		result = addition(result, term)
+	}
+	if result == nil {
+		return ratio(0, 1), nil
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"testing"
+)
+
+func TestSeries(t *testing.T) {
+	run(t, []test{
+		{"series(exp(x), x, 0, 4)", "1 + x + x^2 / 2 + x^3 / 6"},
+		{"series(log(x), x, 1, 3)", "x - 1 - (x - 1)^2 / 2"},
+		{"series(sqrt(x), x, 4, 2)", "2 + (x - 4) / 4"},
+		{"series(sin(x), x, 0, 5)", "x - x^3 / 6"},
+		{"series(cos(x), x, 0, 4)", "1 - x^2 / 2"},
+		{"series(tan(x), x, 0, 5)", "x + x^3 / 3"},
+		{"series(exp(x), x, y, 2)", "e^y + (x - y) * e^y"},
+		// Laurent series
+		{"series(1/x, x, 0, 3)", "1 / x"},
+		{"series(sin(x)/x^3, x, 0, 2)", "1 / x^2 - 1 / 6"},
+		// the remainder of the Taylor series of exp is about x^10 / 10!
+		{"eval(series(exp(x), x, 0, 10) - exp(x), x=1/10)", "-2.780994416e-17"},
+		// the series around 1 has the constant e
+		{"series(exp(y), y, 1, 3)", "e + e * (y - 1) + e * (y - 1)^2 / 2"},
+		{"derivative(series(exp(y), y, 1, 3))", "e * (y - 1) + e"},
+		{"eval(series(exp(y), y, 1, 3), y=1)", "2.718281828"},
+	})
+	runErrors(t, []errorTest{
+		{"series(exp(x), x, 0, -1)", ErrorTypeNonInteger, 21, 23},
+		{"series(exp(x), x, 0, 1/2)", ErrorTypeNonInteger, 21, 24},
+		{"series(log(x), x, 0, 3)", ErrorTypeDomain, 0, 0},
+		{"series(x^x, x, 0, 2)", ErrorTypeDomain, 0, 0},
+		{"series(exp(y), e, 0, 3)", ErrorTypeValue, 15, 16},
+	})
+}
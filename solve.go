@@ -0,0 +1,366 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math/big"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+const (
+	// maxIterations is the maximum number of iterations of a root finder
+	maxIterations = 128
+	// maxBracket is the maximum number of times the search for a sign change
+	// doubles its distance from the guess
+	maxBracket = 80
+)
+
+// Solve finds a root of the equation in the variable name at precision prec
+// with Halley's method starting from guess, looking up the other variables
+// and functions in env. If the iteration fails for a real equation, a root is
+// bracketed around the guess and found with Brent's method. If b isn't nil
+// the root is bracketed by guess and b.
+func (n *Node) Solve(env *Environment, name string, guess, b *complex.Rational, prec uint) (*complex.Matrix, error) {
+	if err := checkPrec(n, prec); err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = NewEnvironment()
+	}
+	piLock.RLock()
+	defer piLock.RUnlock()
+	reservePI(prec)
+	return n.solve(env, name, guess, b, prec)
+}
+
+// solver evaluates an equation and its derivatives
+type solver struct {
+	equation, first, second *Node
+	name                    string
+	scope                   *Environment
+	prec                    uint
+	eps                     *big.Float
+}
+
+// at evaluates the expression e at x
+func (s *solver) at(e *Node, x *complex.Float) (*complex.Float, error) {
+	s.scope.values[s.name] = Value{
+		ValueType: ValueTypeMatrix,
+		Matrix:    newScalar(s.prec, toRational(x)),
+	}
+	y, err := e.evaluate(s.scope, s.prec, 0)
+	if err != nil {
+		return nil, err
+	} else if !isScalar(y) {
+		return nil, newNodeError(ErrorTypeDimension, e, "the equation must be a 1x1 matrix")
+	}
+	return toFloat(&y.Values[0][0], s.prec), nil
+}
+
+// failed tests if an error is an arithmetic failure at a point, which ends an
+// iteration instead of the search for a root
+func failed(err error) bool {
+	e, ok := err.(*Error)
+	return ok && (e.ErrorType == ErrorTypeDivisionByZero || e.ErrorType == ErrorTypeDomain)
+}
+
+// magnitude computes the absolute value of x
+func magnitude(x *complex.Float, prec uint) *big.Float {
+	a := newFloat(prec).Mul(x.A, x.A)
+	a.Add(a, newFloat(prec).Mul(x.B, x.B))
+	return a.Sqrt(a)
+}
+
+// converged tests if the step is negligible relative to x
+func (s *solver) converged(step, x *complex.Float) bool {
+	scale := magnitude(x, s.prec)
+	if scale.Cmp(big.NewFloat(1)) < 0 {
+		scale.SetInt64(1)
+	}
+	return magnitude(step, s.prec).Cmp(scale.Mul(scale, s.eps)) <= 0
+}
+
+// iterate runs Halley's method from x, switching to Schroder's method which
+// converges quadratically to multiple roots if the convergence is linear
+func (s *solver) iterate(x *complex.Float) (*complex.Float, bool, error) {
+	x = complex.NewFloat(newFloat(s.prec).Set(x.A), newFloat(s.prec).Set(x.B))
+	var last *big.Float
+	linear, schroder := 0, false
+	limit := newFloat(s.prec).SetMantExp(big.NewFloat(1), int(s.prec))
+	for i := 0; i < maxIterations; i++ {
+		var f2 *complex.Float
+		f, err := s.at(s.equation, x)
+		if err != nil {
+			if failed(err) {
+				err = nil
+			}
+			return nil, false, err
+		} else if f.A.Sign() == 0 && f.B.Sign() == 0 {
+			return x, true, nil
+		}
+		f1, err := s.at(s.first, x)
+		if err == nil {
+			f2, err = s.at(s.second, x)
+		}
+		if err != nil {
+			if failed(err) {
+				err = nil
+			}
+			return nil, false, err
+		}
+		var step *complex.Float
+		square := cmul(f1, f1, s.prec)
+		ff2 := cmul(f, f2, s.prec)
+		if schroder {
+			// x - f f' / (f'^2 - f f'')
+			denominator := newComplex(s.prec).Sub(square, ff2)
+			if denominator.A.Sign() == 0 && denominator.B.Sign() == 0 {
+				return nil, false, nil
+			}
+			step = cquo(cmul(f, f1, s.prec), denominator, s.prec)
+		} else {
+			// x - 2 f f' / (2 f'^2 - f f'')
+			square.A.SetMantExp(square.A, 1)
+			square.B.SetMantExp(square.B, 1)
+			denominator := newComplex(s.prec).Sub(square, ff2)
+			if denominator.A.Sign() == 0 && denominator.B.Sign() == 0 {
+				return nil, false, nil
+			}
+			numerator := cmul(f, f1, s.prec)
+			numerator.A.SetMantExp(numerator.A, 1)
+			numerator.B.SetMantExp(numerator.B, 1)
+			step = cquo(numerator, denominator, s.prec)
+		}
+		x.Sub(x, step)
+		if s.converged(step, x) {
+			return x, true, nil
+		} else if magnitude(x, s.prec).Cmp(limit) > 0 {
+			return nil, false, nil
+		}
+		size := magnitude(step, s.prec)
+		if last != nil && newFloat(s.prec).SetMantExp(size, 3).Cmp(last) > 0 {
+			linear++
+		} else {
+			linear = 0
+		}
+		if linear >= 3 {
+			schroder = true
+		}
+		last = size
+	}
+	return nil, false, nil
+}
+
+// sign evaluates the sign of the equation at the real number x
+func (s *solver) sign(x *big.Float) (int, *big.Float, error) {
+	f, err := s.at(s.equation, complex.NewFloat(x, newFloat(s.prec)))
+	if err != nil {
+		return 0, nil, err
+	}
+	return f.A.Sign(), f.A, nil
+}
+
+// bracket searches for a sign change of a real equation around the guess
+func (s *solver) bracket(guess *big.Float) (a, b *big.Float, err error) {
+	sg, _, err := s.sign(guess)
+	if err != nil {
+		if failed(err) {
+			err = nil
+		}
+		return nil, nil, err
+	} else if sg == 0 {
+		return guess, guess, nil
+	}
+	scale := newFloat(s.prec).Abs(guess)
+	if scale.Cmp(big.NewFloat(1)) < 0 {
+		scale.SetInt64(1)
+	}
+	distance := newFloat(s.prec).SetMantExp(scale, -16)
+	for i := 0; i < maxBracket; i++ {
+		for _, x := range []*big.Float{
+			newFloat(s.prec).Sub(guess, distance),
+			newFloat(s.prec).Add(guess, distance),
+		} {
+			sx, _, err := s.sign(x)
+			if err != nil {
+				// the equation may not be defined everywhere around the guess
+				continue
+			}
+			if sx == 0 {
+				return x, x, nil
+			} else if sx != sg {
+				return guess, x, nil
+			}
+		}
+		distance.SetMantExp(distance, 1)
+	}
+	return nil, nil, nil
+}
+
+// brent finds the root of a real equation between a and b with Brent's method
+// https://en.wikipedia.org/wiki/Brent%27s_method
+func (s *solver) brent(a, b *big.Float) (*big.Float, bool, error) {
+	p := s.prec
+	_, fa, err := s.sign(a)
+	if err != nil {
+		return nil, false, err
+	}
+	_, fb, err := s.sign(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if fa.Sign() == 0 {
+		return a, true, nil
+	} else if fb.Sign() == 0 {
+		return b, true, nil
+	} else if fa.Sign() == fb.Sign() {
+		return nil, false, nil
+	}
+	abs := func(x *big.Float) *big.Float {
+		return newFloat(p).Abs(x)
+	}
+	sub := func(x, y *big.Float) *big.Float {
+		return newFloat(p).Sub(x, y)
+	}
+	mul := func(x, y *big.Float) *big.Float {
+		return newFloat(p).Mul(x, y)
+	}
+	quo := func(x, y *big.Float) *big.Float {
+		return newFloat(p).Quo(x, y)
+	}
+	if abs(fa).Cmp(abs(fb)) < 0 {
+		a, b, fa, fb = b, a, fb, fa
+	}
+	c, fc, d := a, fa, a
+	bisected := true
+	for i := 0; i < int(p)+maxIterations; i++ {
+		tolerance := abs(b)
+		if tolerance.Cmp(big.NewFloat(1)) < 0 {
+			tolerance.SetInt64(1)
+		}
+		tolerance.Mul(tolerance, s.eps)
+		if fb.Sign() == 0 || abs(sub(b, a)).Cmp(tolerance) <= 0 {
+			return b, true, nil
+		}
+		var x *big.Float
+		if fa.Cmp(fc) != 0 && fb.Cmp(fc) != 0 {
+			// inverse quadratic interpolation
+			x = quo(mul(mul(a, fb), fc), mul(sub(fa, fb), sub(fa, fc)))
+			x.Add(x, quo(mul(mul(b, fa), fc), mul(sub(fb, fa), sub(fb, fc))))
+			x.Add(x, quo(mul(mul(c, fa), fb), mul(sub(fc, fa), sub(fc, fb))))
+		} else {
+			// secant
+			x = sub(b, quo(mul(fb, sub(b, a)), sub(fb, fa)))
+		}
+		quarter := newFloat(p).Add(mul(a, big.NewFloat(3)), b)
+		quarter.SetMantExp(quarter, -2)
+		between := (sub(x, quarter).Sign() * sub(x, b).Sign()) < 0
+		half := func(x *big.Float) *big.Float {
+			return x.SetMantExp(x, -1)
+		}
+		if !between ||
+			(bisected && abs(sub(x, b)).Cmp(half(abs(sub(b, c)))) >= 0) ||
+			(!bisected && abs(sub(x, b)).Cmp(half(abs(sub(c, d)))) >= 0) ||
+			(bisected && abs(sub(b, c)).Cmp(tolerance) < 0) ||
+			(!bisected && abs(sub(c, d)).Cmp(tolerance) < 0) {
+			x = half(newFloat(p).Add(a, b))
+			bisected = true
+		} else {
+			bisected = false
+		}
+		_, fx, err := s.sign(x)
+		if err != nil {
+			if failed(err) {
+				// a pole rather than a root changes the sign
+				err = nil
+			}
+			return nil, false, err
+		}
+		d, c, fc = c, b, fb
+		if fa.Sign()*fx.Sign() < 0 {
+			b, fb = x, fx
+		} else {
+			a, fa = x, fx
+		}
+		if abs(fa).Cmp(abs(fb)) < 0 {
+			a, b, fa, fb = b, a, fb, fa
+		}
+	}
+	return nil, false, nil
+}
+
+// solve finds a root of the equation
+func (n *Node) solve(env *Environment, name string, guess, b *complex.Rational, prec uint) (*complex.Matrix, error) {
+	wp := prec + guard
+	var first, second *Node
+	if first = n.DerivativeWith(name); first != nil {
+		first = first.Simplify()
+		if second = first.DerivativeWith(name); second != nil {
+			second = second.Simplify()
+		}
+	}
+	s := &solver{
+		equation: n,
+		first:    first,
+		second:   second,
+		name:     name,
+		scope:    env.child(),
+		prec:     wp,
+		eps:      newFloat(wp).SetMantExp(big.NewFloat(1), -int(prec)),
+	}
+	result := func(x *complex.Float) *complex.Matrix {
+		r := toRational(complex.NewFloat(newFloat(prec).Set(x.A), newFloat(prec).Set(x.B)))
+		return newScalar(prec, r)
+	}
+
+	x := toFloat(guess, wp)
+	if b != nil {
+		// start from the middle of the bracket
+		x.Add(x, toFloat(b, wp))
+		x.A.SetMantExp(x.A, -1)
+		x.B.SetMantExp(x.B, -1)
+	}
+	var (
+		root *complex.Float
+		ok   bool
+		err  error
+	)
+	if second != nil {
+		// without the derivatives only a bracketed root can be found
+		if root, ok, err = s.iterate(x); err != nil {
+			return nil, err
+		}
+	}
+	if ok && b != nil && guess.B.Sign() == 0 && b.B.Sign() == 0 {
+		// the root must be within a real bracket
+		lower, upper := toFloat(guess, wp).A, toFloat(b, wp).A
+		if lower.Cmp(upper) > 0 {
+			lower, upper = upper, lower
+		}
+		ok = root.B.Sign() == 0 && root.A.Cmp(lower) >= 0 && root.A.Cmp(upper) <= 0
+	}
+	if ok {
+		return result(root), nil
+	}
+
+	if guess.B.Sign() == 0 && (b == nil || b.B.Sign() == 0) {
+		lower, upper := toFloat(guess, wp).A, (*big.Float)(nil)
+		if b != nil {
+			upper = toFloat(b, wp).A
+		} else if lower, upper, err = s.bracket(lower); err != nil {
+			return nil, err
+		}
+		if lower != nil {
+			x, ok, err := s.brent(lower, upper)
+			if err != nil {
+				return nil, err
+			} else if ok {
+				return result(complex.NewFloat(x, newFloat(wp))), nil
+			}
+		}
+	}
+	return nil, newNodeError(ErrorTypeConvergence, n, "no root of %s was found", name)
+}
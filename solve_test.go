@@ -0,0 +1,38 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		solve, root string
+	}{
+		{"solve(x^2 - 2, x, 1)", "sqrt(2)"},
+		{"solve(x^2 - 2, x, 0, 3)", "sqrt(2)"},
+		{"solve(x^2 + 1, x, 1i)", "1i"},
+		{"solve(exp(x) - 2, x, 0)", "log(2)"},
+		{"solve(x^3, x, 1)", "0"},
+		{"solve((x - 1)^2*(x + 2), x, 3/2)", "1"},
+		{"solve(cos(x) - x, x, 1)", "0.73908513321516064165531208767387340401341175890075746496568063577328465488354759459937610693176653184980124664398716302771490369130842031578044057462077868852490389153928943884509523480133563127677223158095635377657245"},
+	}
+	for _, test := range tests {
+		expression := test.solve + " - " + test.root
+		value, err := evaluate(NewEnvironment(), expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.solve, err)
+		} else if !negligible(value.Matrix, 680) {
+			t.Errorf("%s = %s, want 0", expression, format(value))
+		}
+	}
+	runErrors(t, []errorTest{
+		{"solve(x^2 + 1, x, 1)", ErrorTypeConvergence, 0, 20},
+		{"solve(x^2 - 2, x, 2, 3)", ErrorTypeConvergence, 0, 23},
+		{"solve([x x], x, 1)", ErrorTypeDimension, 0, 18},
+		{"solve(y - 1, e, 1)", ErrorTypeValue, 13, 14},
+	})
+}
@@ -0,0 +1,930 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"sync"
+
+	complex "github.com/pointlander/c0mpl3x"
+)
+
+// maxShift is the largest number of steps of the recurrences which move the
+// argument of the gamma functions to where the asymptotic series converge
+const maxShift = 1 << 16
+
+// maxGamma is the largest magnitude of the parts of the arguments of the gamma
+// function for which its values are within the exponent range of big.Float
+const maxGamma = 1 << 26
+
+// maxFactorial is the largest factorial which is computed exactly
+const maxFactorial = 4096
+
+// maxBernoulli is the largest index of the Bernoulli numbers in the exact
+// values of the zeta function
+const maxBernoulli = 1024
+
+// maxRefinements is the maximum number of times a special function is
+// computed again with more bits when its terms cancel
+const maxRefinements = 4
+
+// bernoullis are the Bernoulli numbers B_0, B_2, B_4, ... computed so far
+var bernoullis struct {
+	sync.Mutex
+	numbers []*big.Rat
+}
+
+// bernoulli returns the Bernoulli number B_n, B_1 is -1/2
+func bernoulli(n int) *big.Rat {
+	switch {
+	case n == 1:
+		return big.NewRat(-1, 2)
+	case n%2 == 1:
+		return new(big.Rat)
+	}
+	bernoullis.Lock()
+	defer bernoullis.Unlock()
+	if k := n / 2; k >= len(bernoullis.numbers) {
+		size := 2 * len(bernoullis.numbers)
+		if size <= k {
+			size = k + 1
+		}
+		bernoullis.numbers = evenBernoulli(size)
+	}
+	return new(big.Rat).Set(bernoullis.numbers[n/2])
+}
+
+// evenBernoulli computes B_0, B_2, ..., B_2(n-1) from the tangent numbers with
+// the algorithm of Brent and Harvey
+func evenBernoulli(n int) []*big.Rat {
+	numbers := []*big.Rat{big.NewRat(1, 1)}
+	t := make([]*big.Int, n)
+	for k := 1; k < n; k++ {
+		t[k] = big.NewInt(1)
+		if k > 1 {
+			t[k].Mul(t[k-1], big.NewInt(int64(k-1)))
+		}
+	}
+	a := new(big.Int)
+	for k := 2; k < n; k++ {
+		for j := k; j < n; j++ {
+			// T_j = (j - k) T_(j-1) + (j - k + 2) T_j
+			a.Mul(t[j-1], big.NewInt(int64(j-k)))
+			t[j].Mul(t[j], big.NewInt(int64(j-k+2)))
+			t[j].Add(t[j], a)
+		}
+	}
+	for k := 1; k < n; k++ {
+		// B_2k = (-1)^(k-1) 2k T_k / (4^k (4^k - 1))
+		d := new(big.Int).Lsh(big.NewInt(1), uint(2*k))
+		d.Mul(d, new(big.Int).Sub(d, big.NewInt(1)))
+		b := new(big.Rat).SetFrac(new(big.Int).Mul(t[k], big.NewInt(int64(2*k))), d)
+		if k%2 == 0 {
+			b.Neg(b)
+		}
+		numbers = append(numbers, b)
+	}
+	return numbers
+}
+
+// integerOf returns the value of x if it is an integer
+func integerOf(x *complex.Rational) (*big.Int, bool) {
+	if x.B.Sign() != 0 || !x.A.IsInt() {
+		return nil, false
+	}
+	return x.A.Num(), true
+}
+
+// nonPositive tests if x is zero or a negative integer, the poles of the gamma
+// function
+func nonPositive(x *complex.Rational) bool {
+	n, ok := integerOf(x)
+	return ok && n.Sign() <= 0
+}
+
+// realRational creates the complex rational of a real rational
+func realRational(r *big.Rat) *complex.Rational {
+	return complex.NewRational(r, new(big.Rat))
+}
+
+// exactGamma computes the gamma function of the positive integers up to
+// maxFactorial + 1, which are factorials
+func exactGamma(x *complex.Rational) (*complex.Rational, bool) {
+	n, ok := integerOf(x)
+	if !ok || n.Sign() <= 0 || n.Cmp(big.NewInt(maxFactorial+1)) > 0 {
+		return nil, false
+	}
+	return realRational(new(big.Rat).SetInt(factorial(int(n.Int64() - 1)))), true
+}
+
+// exactBeta computes the beta function of positive integers, which is a ratio
+// of factorials, and of the points where only the denominator has a pole
+func exactBeta(a, b *complex.Rational) (*complex.Rational, bool) {
+	if nonPositive(a) || nonPositive(b) {
+		return nil, false
+	}
+	s := complex.NewRational(new(big.Rat).Add(a.A, b.A), new(big.Rat).Add(a.B, b.B))
+	if nonPositive(s) {
+		return realRational(new(big.Rat)), true
+	}
+	x, ok := exactGamma(a)
+	if !ok {
+		return nil, false
+	}
+	y, ok := exactGamma(b)
+	if !ok {
+		return nil, false
+	}
+	z, ok := exactGamma(s)
+	if !ok {
+		return nil, false
+	}
+	x.Mul(x, y)
+	return x.Div(x, z), true
+}
+
+// exactZeta computes the zeta function of the non-positive integers,
+// zeta(-n) = (-1)^n B_(n+1) / (n + 1)
+func exactZeta(s *complex.Rational) (*complex.Rational, bool) {
+	n, ok := integerOf(s)
+	if !ok || n.Sign() > 0 || n.Cmp(big.NewInt(-maxBernoulli+1)) < 0 {
+		return nil, false
+	}
+	m := -n.Int64()
+	a := bernoulli(int(m + 1))
+	a.Quo(a, big.NewRat(m+1, 1))
+	if m%2 == 1 {
+		a.Neg(a)
+	}
+	return realRational(a), true
+}
+
+// halfGamma computes the rational r with Γ(x) = r sqrt(pi) for a half
+// integer x
+func halfGamma(x *complex.Rational) (*big.Rat, bool) {
+	if x.B.Sign() != 0 || x.A.Denom().Cmp(big.NewInt(2)) != 0 {
+		return nil, false
+	}
+	// x = n + 1/2
+	n := new(big.Int).Sub(x.A.Num(), big.NewInt(1))
+	n.Rsh(n, 1)
+	if !n.IsInt64() || abs(n.Int64()) > maxFactorial/2 {
+		return nil, false
+	}
+	m := n.Int64()
+	if m >= 0 {
+		// Γ(n + 1/2) = (2n)! / (4^n n!) sqrt(pi)
+		d := new(big.Int).Lsh(factorial(int(m)), uint(2*m))
+		return new(big.Rat).SetFrac(factorial(int(2*m)), d), true
+	}
+	// Γ(1/2 - n) = (-4)^n n! / (2n)! sqrt(pi)
+	m = -m
+	a := new(big.Int).Lsh(factorial(int(m)), uint(2*m))
+	if m%2 == 1 {
+		a.Neg(a)
+	}
+	return new(big.Rat).SetFrac(a, factorial(int(2*m))), true
+}
+
+// evenZeta computes the rational r with zeta(x) = r pi^x for a positive even
+// integer x
+func evenZeta(x *complex.Rational) (*big.Rat, bool) {
+	n, ok := integerOf(x)
+	if !ok || n.Sign() <= 0 || n.Bit(0) == 1 || n.Cmp(big.NewInt(maxBernoulli)) > 0 {
+		return nil, false
+	}
+	// zeta(2k) = (-1)^(k + 1) B_2k (2 pi)^2k / (2 (2k)!)
+	m := int(n.Int64())
+	a := bernoulli(m)
+	a.Mul(a, new(big.Rat).SetFrac(new(big.Int).Lsh(big.NewInt(1), uint(m-1)), factorial(m)))
+	if m%4 == 0 {
+		a.Neg(a)
+	}
+	return a, true
+}
+
+// exactValue computes a special function of one argument at the points where
+// its value is rational
+func exactValue(operation Operation, x *complex.Rational) (*complex.Rational, bool) {
+	switch operation {
+	case OperationFactorial:
+		return exactGamma(complex.NewRational(new(big.Rat).Add(x.A, big.NewRat(1, 1)), x.B))
+	case OperationGamma:
+		return exactGamma(x)
+	case OperationLogGamma:
+		// log Γ(1) = log Γ(2) = 0
+		if n, ok := integerOf(x); ok && (n.Cmp(big.NewInt(1)) == 0 || n.Cmp(big.NewInt(2)) == 0) {
+			return realRational(new(big.Rat)), true
+		}
+	case OperationErf, OperationErfc:
+		if x.A.Sign() == 0 && x.B.Sign() == 0 {
+			if operation == OperationErfc {
+				return realRational(big.NewRat(1, 1)), true
+			}
+			return realRational(new(big.Rat)), true
+		}
+	case OperationZeta:
+		return exactZeta(x)
+	case OperationEllipticE:
+		// E(1) = 1
+		if n, ok := integerOf(x); ok && n.Cmp(big.NewInt(1)) == 0 {
+			return realRational(big.NewRat(1, 1)), true
+		}
+	}
+	return nil, false
+}
+
+// approximate returns the parts of x as float64s
+func approximate(x *complex.Float) (float64, float64) {
+	a, _ := x.A.Float64()
+	b, _ := x.B.Float64()
+	return a, b
+}
+
+// cround rounds x to precision prec
+func cround(x *complex.Float, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Set(x.A), newFloat(prec).Set(x.B))
+}
+
+// creal creates the complex float of a real float
+func creal(x *big.Float, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Set(x), newFloat(prec))
+}
+
+// cadd computes x + y
+func cadd(x, y *complex.Float, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Add(x.A, y.A), newFloat(prec).Add(x.B, y.B))
+}
+
+// csub computes x - y
+func csub(x, y *complex.Float, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Sub(x.A, y.A), newFloat(prec).Sub(x.B, y.B))
+}
+
+// cscale computes r x for a real r
+func cscale(x *complex.Float, r *big.Float, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Mul(x.A, r), newFloat(prec).Mul(x.B, r))
+}
+
+// cneg computes -x
+func cneg(x *complex.Float) *complex.Float {
+	return complex.NewFloat(new(big.Float).Neg(x.A), new(big.Float).Neg(x.B))
+}
+
+// czero tests if x is zero
+func czero(x *complex.Float) bool {
+	return x.A.Sign() == 0 && x.B.Sign() == 0
+}
+
+// cpowInt computes x^n for a positive integer n
+func cpowInt(x *complex.Float, n int, prec uint) *complex.Float {
+	a := creal(big.NewFloat(1), prec)
+	for b := x; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			a = cmul(a, b, prec)
+		}
+		if n > 1 {
+			b = cmul(b, b, prec)
+		}
+	}
+	return a
+}
+
+// nearest returns the integer nearest to x
+func nearest(x *big.Float) *big.Int {
+	if x.IsInt() {
+		n, _ := x.Int(nil)
+		return n
+	}
+	a := newFloat(x.Prec()+1).Add(x, big.NewFloat(.5))
+	n, accuracy := a.Int(nil)
+	if accuracy == big.Above {
+		n.Sub(n, big.NewInt(1))
+	}
+	return n
+}
+
+// sinPi computes sin(pi x), the argument is reduced to the nearest integer
+// before it is multiplied by pi so that the zeros at the integers are exact
+func sinPi(x *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	n := nearest(x.A)
+	d := complex.NewFloat(newFloat(wp).Sub(x.A, newFloat(wp).SetInt(n)), newFloat(wp).Set(x.B))
+	_, pi := constant(wp)
+	a := csin(cscale(d, pi, wp), prec)
+	if n.Bit(0) == 1 {
+		a = cneg(a)
+	}
+	return a
+}
+
+// refine computes a special function with the working precision increased by
+// the bits that are lost to cancellation, f returns the value and the binary
+// exponent of the largest of the terms that were summed
+func refine(prec uint, f func(wp uint) (*complex.Float, int)) *complex.Float {
+	wp := prec + guard
+	for i := 0; ; i++ {
+		a, scale := f(wp)
+		loss := scale - cexponent(a)
+		if czero(a) || int(wp)-loss >= int(prec+guard/2) || i == maxRefinements {
+			return cround(a, prec)
+		}
+		wp = prec + guard + uint(loss)
+	}
+}
+
+// shift returns the number of steps n for which z + n has a positive real part
+// and a magnitude of at least r, or maxShift + 1 if there are too many steps
+func shift(z *complex.Float, r float64) int {
+	x, y := approximate(z)
+	n := 0.0
+	if x < 1 {
+		n = math.Ceil(1 - x)
+	}
+	if d := r*r - y*y; d > 0 {
+		if m := math.Ceil(math.Sqrt(d) - x); m > n {
+			n = m
+		}
+	}
+	if n > maxShift {
+		return maxShift + 1
+	}
+	return int(n)
+}
+
+// shifted computes z + n
+func shifted(z *complex.Float, n int, prec uint) *complex.Float {
+	return complex.NewFloat(newFloat(prec).Add(z.A, newFloat(prec).SetInt64(int64(n))), newFloat(prec).Set(z.B))
+}
+
+// stirling computes log Γ(w) with Stirling's series, |w| must be large enough
+// that the terms become smaller than 2^-prec before they diverge
+func stirling(w *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	_, pi := constant(wp)
+	// (w - 1/2) log w - w + log(2 pi)/2
+	half := complex.NewFloat(newFloat(wp).Sub(w.A, big.NewFloat(.5)), newFloat(wp).Set(w.B))
+	sum := csub(cmul(half, clog(w, wp), wp), w, wp)
+	c := logFloat(pi.SetMantExp(pi, 1), wp)
+	sum.A.Add(sum.A, c.SetMantExp(c, -1))
+	// the sum of B_2k / (2k (2k - 1) w^(2k - 1))
+	power := cquo(creal(big.NewFloat(1), wp), w, wp)
+	square := cmul(power, power, wp)
+	last := math.MaxInt32
+	for k := 1; ; k++ {
+		b := bernoulli(2 * k)
+		b.Quo(b, big.NewRat(int64(2*k*(2*k-1)), 1))
+		term := cscale(power, newFloat(wp).SetRat(b), wp)
+		e := cexponent(term)
+		if e < cexponent(sum)-int(wp) || e > last {
+			break
+		}
+		sum, last = cadd(sum, term, wp), e
+		power = cmul(power, square, wp)
+	}
+	return sum
+}
+
+// logGamma computes the principal branch of log Γ(z) at the working precision
+// wp, it returns the value and the binary exponent of the larger of the terms
+// that are subtracted
+func logGamma(z *complex.Float, wp uint) (*complex.Float, int) {
+	n := shift(z, float64(wp)/2)
+	a := stirling(shifted(z, n, wp), wp)
+	if n == 0 {
+		return a, cexponent(a)
+	}
+	// log Γ(z) = log Γ(z + n) - the sum of log(z + j), the logarithm of the
+	// product is moved to the branch of the sum with the sum of the angles
+	product := creal(big.NewFloat(1), wp)
+	x, y := approximate(z)
+	if z.B.Sign() == 0 {
+		y = 0
+	}
+	angle := 0.0
+	for j := 0; j < n; j++ {
+		product = cmul(product, shifted(z, j, wp), wp)
+		angle += math.Atan2(y, x+float64(j))
+	}
+	log := clog(product, wp)
+	b, _ := log.B.Float64()
+	if k := math.Round((angle - b) / (2 * math.Pi)); k != 0 {
+		_, pi := constant(wp)
+		log.B.Add(log.B, pi.Mul(pi, newFloat(wp).SetFloat64(2*k)))
+	}
+	scale := cexponent(a)
+	if e := cexponent(log); e > scale {
+		scale = e
+	}
+	return csub(a, log, wp), scale
+}
+
+// clgamma computes the principal branch of the logarithm of the gamma
+// function, which is analytic except on the real numbers less than or equal to
+// zero, z must not be a non-positive integer and the real part of z must not be
+// less than -maxShift
+func clgamma(z *complex.Float, prec uint) *complex.Float {
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		return logGamma(z, wp)
+	})
+}
+
+// cgamma computes the gamma function, z must not be a non-positive integer
+func cgamma(z *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	if z.A.Cmp(big.NewFloat(.5)) < 0 {
+		// the reflection formula Γ(z) = pi / (sin(pi z) Γ(1 - z))
+		_, pi := constant(wp)
+		reflected := complex.NewFloat(newFloat(wp).Sub(big.NewFloat(1), z.A), newFloat(wp).Neg(z.B))
+		d := cmul(sinPi(z, wp), cgamma(reflected, wp), wp)
+		return cquo(creal(pi, wp), d, prec)
+	}
+	// Γ(z) = e^log Γ(z), the bits of the integer part of log Γ(z) are lost
+	a, scale := logGamma(z, wp)
+	if scale > guard/2 {
+		a, _ = logGamma(z, wp+uint(scale))
+	}
+	return cexp(a, prec)
+}
+
+// cpolygamma computes the polygamma function of order m, which is the mth
+// derivative of the digamma function, z must not be a non-positive integer
+// and the real part of z must not be less than -maxShift for m > 0
+func cpolygamma(m int, z *complex.Float, prec uint) *complex.Float {
+	if m == 0 && z.A.Sign() < 0 {
+		// the reflection formula ψ(z) = ψ(1 - z) - pi cot(pi z)
+		return refine(prec, func(wp uint) (*complex.Float, int) {
+			_, pi := constant(wp)
+			reflected := complex.NewFloat(newFloat(wp).Sub(big.NewFloat(1), z.A), newFloat(wp).Neg(z.B))
+			a := cpolygamma(0, reflected, wp)
+			// cot(pi z) = cos(pi z)/sin(pi z) where cos(pi z) = sin(pi (z + 1/2))
+			half := complex.NewFloat(newFloat(wp).Add(z.A, big.NewFloat(.5)), newFloat(wp).Set(z.B))
+			b := cscale(cquo(sinPi(half, wp), sinPi(z, wp), wp), pi, wp)
+			scale := cexponent(a)
+			if e := cexponent(b); e > scale {
+				scale = e
+			}
+			return csub(a, b, wp), scale
+		})
+	}
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		n := shift(z, float64(wp)/2+2*float64(m))
+		w := shifted(z, n, wp)
+		one := creal(big.NewFloat(1), wp)
+		inverse := cquo(one, w, wp)
+		var sum, power *complex.Float
+		// the asymptotic series
+		if m == 0 {
+			// log w - 1/(2w) - the sum of B_2k / (2k w^2k)
+			half := cscale(inverse, big.NewFloat(.5), wp)
+			sum, power = csub(clog(w, wp), half, wp), cmul(inverse, inverse, wp)
+		} else {
+			// (m - 1)!/w^m + m!/(2 w^(m + 1)) + the sum of B_2k (2k + m - 1)! /
+			// ((2k)! w^(2k + m))
+			power = cpowInt(inverse, m, wp)
+			sum = cscale(power, newFloat(wp).SetInt(factorial(m-1)), wp)
+			power = cmul(power, inverse, wp)
+			half := newFloat(wp).SetInt(factorial(m))
+			sum = cadd(sum, cscale(power, half.SetMantExp(half, -1), wp), wp)
+			power = cmul(power, inverse, wp)
+		}
+		square := cmul(inverse, inverse, wp)
+		last := math.MaxInt32
+		for k := 1; ; k++ {
+			b := bernoulli(2 * k)
+			if m == 0 {
+				b.Quo(b, big.NewRat(int64(-2*k), 1))
+			} else {
+				b.Mul(b, new(big.Rat).SetFrac(factorial(2*k+m-1), factorial(2*k)))
+			}
+			term := cscale(power, newFloat(wp).SetRat(b), wp)
+			e := cexponent(term)
+			if e < cexponent(sum)-int(wp) || e > last {
+				break
+			}
+			sum, last = cadd(sum, term, wp), e
+			power = cmul(power, square, wp)
+		}
+		if m > 0 && m%2 == 0 {
+			sum = cneg(sum)
+		}
+		scale := cexponent(sum)
+		if n == 0 {
+			return sum, scale
+		}
+		// ψ^(m)(z) = ψ^(m)(z + n) - (-1)^m m! the sum of 1/(z + j)^(m + 1)
+		terms := newComplex(wp)
+		for j := 0; j < n; j++ {
+			terms = cadd(terms, cpowInt(cquo(one, shifted(z, j, wp), wp), m+1, wp), wp)
+		}
+		terms = cscale(terms, newFloat(wp).SetInt(factorial(m)), wp)
+		if e := cexponent(terms); e > scale {
+			scale = e
+		}
+		if m%2 == 1 {
+			return cadd(sum, terms, wp), scale
+		}
+		return csub(sum, terms, wp), scale
+	})
+}
+
+// erfAsymptotic tests if |z|^2 is large enough for the asymptotic series of
+// the complementary error function to reach precision prec
+func erfAsymptotic(z *complex.Float, prec uint) bool {
+	x, y := approximate(z)
+	return x*x+y*y >= float64(prec+guard)*math.Ln2
+}
+
+// erfSeries sums the Maclaurin series of the error function, it returns the
+// sum and the binary exponent of the largest term
+func erfSeries(z *complex.Float, prec uint) (*complex.Float, int) {
+	wp := prec + guard
+	if czero(z) {
+		return newComplex(wp), 0
+	}
+	// erf z = 2/sqrt(pi) the sum of (-1)^n z^(2n + 1) / (n! (2n + 1))
+	x, y := approximate(z)
+	peak := x*x + y*y
+	square := cneg(cmul(z, z, wp))
+	term, sum := cround(z, wp), cround(z, wp)
+	scale := cexponent(z)
+	for n := 1; ; n++ {
+		term = cmul(term, square, wp)
+		term = cscale(term, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(n))), wp)
+		a := cscale(term, newFloat(wp).Quo(big.NewFloat(1), newFloat(wp).SetInt64(int64(2*n+1))), wp)
+		e := cexponent(a)
+		if e > scale {
+			scale = e
+		}
+		sum = cadd(sum, a, wp)
+		if float64(n) > peak && e < cexponent(sum)-int(wp) {
+			break
+		}
+	}
+	_, pi := constant(wp)
+	c := newFloat(wp).Quo(big.NewFloat(2), pi.Sqrt(pi))
+	return cscale(sum, c, wp), scale + 1
+}
+
+// erfcSeries sums the asymptotic series of the complementary error function
+// for z with a non-negative real part and a large enough magnitude
+func erfcSeries(z *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	// erfc z = e^(-z^2) / (z sqrt(pi)) the sum of (-1)^n (2n - 1)!! / (2z^2)^n
+	square := cmul(z, z, wp)
+	inverse := cquo(creal(big.NewFloat(-.5), wp), square, wp)
+	term, sum := creal(big.NewFloat(1), wp), creal(big.NewFloat(1), wp)
+	last := 1
+	for n := 1; ; n++ {
+		term = cmul(term, inverse, wp)
+		term = cscale(term, newFloat(wp).SetInt64(int64(2*n-1)), wp)
+		e := cexponent(term)
+		if e < cexponent(sum)-int(wp) || e > last {
+			break
+		}
+		sum, last = cadd(sum, term, wp), e
+	}
+	_, pi := constant(wp)
+	d := cscale(z, pi.Sqrt(pi), wp)
+	return cquo(cmul(cexp(cneg(square), wp), sum, wp), d, prec)
+}
+
+// cerf computes the error function
+func cerf(z *complex.Float, prec uint) *complex.Float {
+	if z.A.Sign() < 0 {
+		// erf(-z) = -erf(z)
+		return cneg(cerf(cneg(z), prec))
+	} else if erfAsymptotic(z, prec) {
+		a := erfcSeries(z, prec+guard)
+		a.A.Sub(big.NewFloat(1), a.A)
+		a.B.Neg(a.B)
+		if z.A.Sign() == 0 {
+			// erf is odd so erf(iy) is imaginary
+			a.A.SetInt64(0)
+		}
+		return cround(a, prec)
+	}
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		return erfSeries(z, wp)
+	})
+}
+
+// cerfc computes the complementary error function
+func cerfc(z *complex.Float, prec uint) *complex.Float {
+	if z.A.Sign() < 0 {
+		// erfc(-z) = 2 - erfc(z)
+		a := cerfc(cneg(z), prec+guard)
+		a.A.Sub(big.NewFloat(2), a.A)
+		a.B.Neg(a.B)
+		return cround(a, prec)
+	} else if erfAsymptotic(z, prec) {
+		return erfcSeries(z, prec)
+	}
+	// erfc z = 1 - erf z which cancels for large real z
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		a, scale := erfSeries(z, wp)
+		a.A.Sub(big.NewFloat(1), a.A)
+		a.B.Neg(a.B)
+		if scale < 1 {
+			scale = 1
+		}
+		return a, scale
+	})
+}
+
+// czeta computes the derivative of order m of the Riemann zeta function, s
+// must not be 1
+func czeta(m int, s *complex.Float, prec uint) *complex.Float {
+	if m == 0 && s.A.Sign() < 0 {
+		// the functional equation zeta(s) = 2^s pi^(s - 1) sin(pi s/2) Γ(1 - s)
+		// zeta(1 - s)
+		wp := prec + guard + positive(cexponent(s))
+		ln2, pi := constant(wp)
+		logPI := logFloat(pi, wp)
+		a := cscale(s, newFloat(wp).Add(ln2, logPI), wp)
+		a.A.Sub(a.A, logPI)
+		a = cexp(a, wp)
+		half := cround(s, wp)
+		half.A.SetMantExp(half.A, -1)
+		half.B.SetMantExp(half.B, -1)
+		a = cmul(a, sinPi(half, wp), wp)
+		reflected := complex.NewFloat(newFloat(wp).Sub(big.NewFloat(1), s.A), newFloat(wp).Neg(s.B))
+		a = cmul(a, cgamma(reflected, wp), wp)
+		return cmul(a, czeta(0, reflected, wp), prec)
+	}
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		return eulerMaclaurin(m, s, wp)
+	})
+}
+
+// eulerMaclaurin computes the derivative of order m of the Riemann zeta
+// function with the Euler-Maclaurin summation of the first n terms of the
+// Dirichlet series, the derivatives with respect to s are the coefficients of
+// the series in h of the terms at s + h. It returns the value and the binary
+// exponent of the largest term.
+func eulerMaclaurin(m int, s *complex.Float, prec uint) (*complex.Float, int) {
+	wp := prec + guard + positive(cexponent(s)) + 8
+	// the terms of the Euler-Maclaurin sum decrease to 2^-wp if 2 pi n is
+	// larger than wp and |s|, unless n^-s is already negligible
+	x, y := approximate(s)
+	n := int(float64(wp)/8+math.Abs(y)/2) + 1
+	if x < 0 {
+		n -= int(x / 2)
+	}
+	scale := math.MinInt32
+	if m == 0 {
+		scale = 1
+	}
+	add := func(sum, a *complex.Float) *complex.Float {
+		if e := cexponent(a); !czero(a) && e > scale {
+			scale = e
+		}
+		return cadd(sum, a, wp)
+	}
+	// the coefficients of n^-h are (-log n)^i / i!
+	logN := logFloat(newFloat(wp).SetInt64(int64(n)), wp)
+	coefficients := []*big.Float{newFloat(wp).SetInt64(1)}
+	for i := 1; i <= m; i++ {
+		c := newFloat(wp).Mul(coefficients[i-1], logN)
+		c.Quo(c.Neg(c), newFloat(wp).SetInt64(int64(i)))
+		coefficients = append(coefficients, c)
+	}
+	// the sum of k^-s (-log k)^m / m! for k < n
+	sum := newComplex(wp)
+	if m == 0 {
+		sum = creal(big.NewFloat(1), wp)
+	}
+	for k := 2; k < n; k++ {
+		l := logFloat(newFloat(wp).SetInt64(int64(k)), wp)
+		a := cexp(cscale(s, newFloat(wp).Neg(l), wp), wp)
+		c := newFloat(wp).SetInt64(1)
+		for i := 1; i <= m; i++ {
+			c.Mul(c, l)
+			c.Quo(c.Neg(c), newFloat(wp).SetInt64(int64(i)))
+		}
+		sum = add(sum, cscale(a, c, wp))
+	}
+	// n^(1 - s - h) / (s - 1 + h) where 1/(s - 1 + h) has the coefficients
+	// (-1)^i / (s - 1)^(i + 1)
+	one := creal(big.NewFloat(1), wp)
+	q := cexp(cscale(csub(one, s, wp), logN, wp), wp)
+	inverse := cquo(one, csub(s, one, wp), wp)
+	power, c := inverse, newComplex(wp)
+	for i := m; i >= 0; i-- {
+		a := cscale(power, coefficients[i], wp)
+		if (m-i)%2 == 1 {
+			a = cneg(a)
+		}
+		c, power = cadd(c, a, wp), cmul(power, inverse, wp)
+	}
+	sum = add(sum, cmul(q, c, wp))
+	// n^(-s - h) / 2
+	r := cscale(q, newFloat(wp).Quo(big.NewFloat(.5), newFloat(wp).SetInt64(int64(n))), wp)
+	sum = add(sum, cscale(r, coefficients[m], wp))
+	// the sum of B_2j / (2j)! s (s + 1) ... (s + 2j - 2) n^(-s - 2j + 1 - h)
+	// where the rising factorial p is a polynomial in h
+	p := make([]*complex.Float, m+1)
+	p[0] = cround(s, wp)
+	for i := 1; i <= m; i++ {
+		p[i] = newComplex(wp)
+	}
+	if m > 0 {
+		p[1] = creal(big.NewFloat(1), wp)
+	}
+	// p (a + h)
+	linear := func(a *complex.Float) {
+		for i := m; i >= 0; i-- {
+			p[i] = cmul(p[i], a, wp)
+			if i > 0 {
+				p[i] = cadd(p[i], p[i-1], wp)
+			}
+		}
+	}
+	square := newFloat(wp).SetInt64(int64(n))
+	square.Quo(big.NewFloat(1), square.Mul(square, square))
+	t := cscale(q, square, wp)
+	last := math.MaxInt32
+	for j := 1; ; j++ {
+		c := newComplex(wp)
+		for i := 0; i <= m; i++ {
+			c = cadd(c, cscale(p[i], coefficients[m-i], wp), wp)
+		}
+		b := bernoulli(2 * j)
+		b.Quo(b, new(big.Rat).SetInt(factorial(2*j)))
+		a := cscale(cmul(t, c, wp), newFloat(wp).SetRat(b), wp)
+		e := cexponent(a)
+		if czero(a) || e < cexponent(sum)-int(wp) || e > last {
+			break
+		}
+		sum, last = add(sum, a), e
+		linear(shifted(s, 2*j-1, wp))
+		linear(shifted(s, 2*j, wp))
+		t = cscale(t, square, wp)
+	}
+	return cscale(sum, newFloat(wp).SetInt(factorial(m)), wp), scale + factorial(m).BitLen()
+}
+
+// lambertGuess approximates the branch k of the Lambert W function of z, the
+// approximations near the branch point -1/e and the asymptotic expansion
+// select the branch
+func lambertGuess(z complex128, k int) complex128 {
+	x, y := real(z), imag(z)
+	const r = -1 / math.E
+	var l1 complex128
+	switch {
+	case k == 0:
+		if -4 < y && y < 4 && -1 < x && x < 2.5 {
+			// the Taylor series in the upper and lower half planes
+			switch {
+			case y > 1:
+				return .876 + .645i + (.118-.174i)*(z-(.75+2.5i))
+			case y > .25:
+				return .505 + .204i + (.375-.132i)*(z-(.75+.5i))
+			case y < -1:
+				return .876 - .645i + (.118+.174i)*(z-(.75-2.5i))
+			case y < -.25:
+				return .505 - .204i + (.375+.132i)*(z-(.75-.5i))
+			}
+			switch {
+			case x < -.5 && y >= 0:
+				// the Taylor series at -1
+				return -.318 + 1.34i + (-.697-.593i)*(z+1)
+			case x < -.5:
+				return -.318 - 1.34i + (-.697+.593i)*(z+1)
+			case x < -.2:
+				// the series at the branch point
+				return -1 + 2.33164398159712*cmplx.Sqrt(z-r) - 1.81218788563936*(z-r)
+			case x < .5:
+				return z
+			}
+			return .2 + .3*z
+		}
+		l1 = cmplx.Log(z)
+	case (k == -1 && y >= 0 || k == 1 && y < 0) && -.1 < y && y < .1 && -.6 < x && x < -.2:
+		// the other side of the branch point
+		return -1 - 2.33164398159712*cmplx.Sqrt(z-r) - 1.81218788563936*(z-r)
+	case k == -1 && y == 0 && -.2 <= x && x < 0:
+		l := math.Log(-x)
+		return cmplx.Rect(l-math.Log(-l), 0)
+	default:
+		l1 = cmplx.Log(z) + cmplx.Rect(2*math.Pi*float64(k), 0)*1i
+	}
+	// the asymptotic expansion
+	l2 := cmplx.Log(l1)
+	return l1 - l2 + l2/l1 + l2*(l2-2)/(2*l1*l1)
+}
+
+// maxLambert is the largest magnitude of the binary exponents of the arguments
+// of the Lambert W function for which the initial guess is a complex128
+const maxLambert = 1000
+
+// clambertw computes the branch k of the Lambert W function, which is the
+// solution w of w e^w = z, with Halley's iteration, z must not be zero unless
+// k is zero
+func clambertw(z *complex.Float, k int, prec uint) *complex.Float {
+	// the bits of 1 + e z are lost near the branch point at -1/e
+	one := creal(big.NewFloat(1), prec)
+	t := cadd(one, cscale(z, expFloat(big.NewFloat(1), prec+guard), prec+guard), prec+guard)
+	wp := prec + guard + positive(-cexponent(t))
+	t = cadd(one, cscale(z, expFloat(big.NewFloat(1), wp), wp), wp)
+	var w *complex.Float
+	switch e := cexponent(z); {
+	case e < -maxLambert && k == 0:
+		// W(z) = z - z^2 + ...
+		w = cround(z, wp)
+	case e < -maxLambert || e > maxLambert:
+		// z is out of the range of complex128, W ~ L1 - L2 + L2/L1 where L1 =
+		// log z + 2 pi i k and L2 = log L1
+		l1 := clog(z, wp)
+		_, pi := constant(wp)
+		l1.B.Add(l1.B, pi.Mul(pi, newFloat(wp).SetInt64(int64(2*k))))
+		l2 := clog(l1, wp)
+		w = cadd(csub(l1, l2, wp), cquo(l2, l1, wp), wp)
+	default:
+		// the package complex hides the builtin complex, the rectangular form
+		// of a real number has no imaginary part
+		x, y := approximate(z)
+		guess := lambertGuess(cmplx.Rect(x, 0)+cmplx.Rect(y, 0)*1i, k)
+		if guess == -1 {
+			// Halley's iteration divides by w + 1
+			guess = -1 + 1./(1<<26)
+		}
+		w = complex.NewFloat(newFloat(wp).SetFloat64(real(guess)), newFloat(wp).SetFloat64(imag(guess)))
+	}
+	if z.B.Sign() == 0 && t.A.Sign() >= 0 && (k == 0 || k == -1 && z.A.Sign() < 0) {
+		// W is real between the branch point and zero on the branch -1 and
+		// after the branch point on the branch 0
+		w.B.SetInt64(0)
+	}
+	for i := 0; i < maxIterations; i++ {
+		// w - f / (e^w (w + 1) - (w + 2) f / (2w + 2)) where f = w e^w - z
+		e := cexp(w, wp)
+		f := csub(cmul(w, e, wp), z, wp)
+		a := shifted(w, 1, wp)
+		d := cquo(cmul(shifted(w, 2, wp), f, wp), cscale(a, big.NewFloat(2), wp), wp)
+		step := cquo(f, csub(cmul(e, a, wp), d, wp), wp)
+		w = csub(w, step, wp)
+		if czero(step) || cexponent(step) < cexponent(w)-int(wp) {
+			break
+		}
+	}
+	return cround(w, prec)
+}
+
+// agm computes the arithmetic geometric mean M of 1 and sqrt(1 - m), where
+// K(m) = pi / (2M), and the sum of 2^(n - 1) c_n^2 where E(m) = K(m) (1 - the
+// sum)
+func agm(m *complex.Float, wp uint) (mean, sum *complex.Float) {
+	one := creal(big.NewFloat(1), wp)
+	a, b := one, csqrt(csub(one, m, wp), wp)
+	sum = cscale(m, big.NewFloat(.5), wp)
+	power := newFloat(wp).SetInt64(1)
+	for i := 0; i < maxIterations; i++ {
+		c := cscale(csub(a, b, wp), big.NewFloat(.5), wp)
+		if czero(c) || cexponent(c) < cexponent(a)-int(wp) {
+			break
+		}
+		sum = cadd(sum, cscale(cmul(c, c, wp), power, wp), wp)
+		power.SetMantExp(power, 1)
+		// the root is the one closer to the mean
+		mean := csub(a, c, wp)
+		b = csqrt(cmul(a, b, wp), wp)
+		p, q := approximate(csub(mean, b, wp))
+		r, s := approximate(cadd(mean, b, wp))
+		if p*p+q*q > r*r+s*s {
+			b = cneg(b)
+		}
+		a = mean
+	}
+	return a, sum
+}
+
+// cellipticK computes the complete elliptic integral of the first kind of the
+// parameter m, m must not be one
+func cellipticK(m *complex.Float, prec uint) *complex.Float {
+	wp := prec + guard
+	mean, _ := agm(m, wp)
+	_, pi := constant(wp)
+	return cquo(creal(pi.SetMantExp(pi, -1), wp), mean, prec)
+}
+
+// cellipticE computes the complete elliptic integral of the second kind of the
+// parameter m, the terms cancel near m = 1
+func cellipticE(m *complex.Float, prec uint) *complex.Float {
+	return refine(prec, func(wp uint) (*complex.Float, int) {
+		mean, sum := agm(m, wp)
+		_, pi := constant(wp)
+		k := cquo(creal(pi.SetMantExp(pi, -1), wp), mean, wp)
+		scale := cexponent(sum)
+		if scale < 1 {
+			scale = 1
+		}
+		sum.A.Sub(big.NewFloat(1), sum.A)
+		sum.B.Neg(sum.B)
+		return cmul(k, sum, wp), scale + cexponent(k)
+	})
+}
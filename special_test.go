@@ -0,0 +1,84 @@
+// Copyright 2020 The Calc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpecialFunctions(t *testing.T) {
+	run(t, []test{
+		{"5!", "120"},
+		{"2.5!", "3.32335097"},
+		{"gamma(1 + 1i)", "0.4980156681 + -0.1549498283i"},
+		{"lgamma(-1/2)", "1.265512123 + -3.141592654i"},
+		{"erf(1i)", "0 + 1.650425759i"},
+		{"zeta(1/2 + 14i)", "0.02224114261 + -0.1032581233i"},
+		{"zeta(1, 2)", "-0.9375482543"},
+	})
+	runZeros(t, []string{
+		"gamma(5) - 24",
+		"gamma(1/2)^2 - pi",
+		"(1/2)! - sqrt(pi)/2",
+		"gamma(1 + 1i)*gamma(1 - 1i) - pi/sinh(pi)",
+		"lgamma(10) - log(362880)",
+		"beta(2, 3) - 1/12",
+		"beta(1/2, 1/2) - pi",
+		"erf(3/2) + erfc(3/2) - 1",
+		"erf(1 + 1i) + erfc(1 + 1i) - 1",
+		"zeta(2) - pi^2/6",
+		"zeta(0) + 1/2",
+		"zeta(-1) + 1/12",
+		"polygamma(1, 1) - pi^2/6",
+		"digamma(1/2) - digamma(1) + 2*log(2)",
+	}, 1000)
+
+	// the reference values are computed with float64
+	tests := []struct {
+		expression string
+		value      float64
+	}{
+		{"gamma(5/2)", math.Gamma(2.5)},
+		{"gamma(-3/2)", math.Gamma(-1.5)},
+		{"lgamma(100)", func() float64 { a, _ := math.Lgamma(100); return a }()},
+		{"erf(1/2)", math.Erf(0.5)},
+		{"erfc(3)", math.Erfc(3)},
+		{"erf(-2)", math.Erf(-2)},
+	}
+	for _, test := range tests {
+		if a := toComplex128(t, test.expression); !near(a, complex(test.value, 0)) {
+			t.Errorf("%s = %v, want %v", test.expression, a, test.value)
+		}
+	}
+
+	runErrors(t, []errorTest{
+		{"gamma(0)", ErrorTypeDomain, 0, 8},
+		{"gamma(-2)", ErrorTypeDomain, 0, 9},
+		{"(-1)!", ErrorTypeDomain, 0, 5},
+		{"zeta(1)", ErrorTypeDomain, 0, 7},
+	})
+}
+
+func TestSpecialDerivatives(t *testing.T) {
+	checkDerivatives(t, []string{
+		"checkderivative(gamma(x)*erf(x), x, 3/2)",
+		"checkderivative(lgamma(x) + beta(x, 2), x, 5/2)",
+		"checkderivative(digamma(x)*erfc(x), x, 2)",
+		"checkderivative(polygamma(2, x)*x, x, 3/2)",
+		"checkderivative(x!, x, 7/3)",
+	})
+	run(t, []test{
+		{"derivative(zeta(x, n), n)", "zeta(x + 1, n)"},
+		{"derivative(polygamma(1, x)*x)", "x * polygamma(2, x) + polygamma(1, x)"},
+	})
+	// the derivatives with respect to the order aren't supported
+	runErrors(t, []errorTest{
+		{"derivative(polygamma(x, 2))", ErrorTypeValue, 0, 27},
+		{"derivative(zeta(x, x))", ErrorTypeValue, 0, 22},
+		{"checkderivative(polygamma(x, 2), x, 1)", ErrorTypeValue, 0, 0},
+		{"gradient(zeta(x, y), [x; y])", ErrorTypeValue, 0, 0},
+	})
+}